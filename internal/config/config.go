@@ -782,11 +782,21 @@ func (c *Config) addOutput(name string, table *ast.Table) error {
 	// arbitrary types of output, so build the serializer and set it.
 	switch t := output.(type) {
 	case serializers.SerializerOutput:
-		serializer, err := buildSerializer(name, table)
+		sc, err := buildSerializerConfig(name, table)
+		if err != nil {
+			return err
+		}
+		serializer, err := serializers.NewSerializer(sc)
 		if err != nil {
 			return err
 		}
 		t.SetSerializer(serializer)
+
+		// outputs writing several data formats build their other serializers
+		// with the same options
+		if sco, ok := output.(serializers.SerializerConfigOutput); ok {
+			sco.SetSerializerConfig(sc)
+		}
 	}
 
 	outputConfig, err := buildOutput(name, table)
@@ -1313,6 +1323,86 @@ func buildParser(name string, tbl *ast.Table) (parsers.Parser, error) {
 		}
 	}
 
+	if node, ok := tbl.Fields["protobuf_descriptor_file"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.ProtobufDescriptorFile = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["protobuf_message_type"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.ProtobufMessageType = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["protobuf_measurement_field"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.ProtobufMeasurementField = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["protobuf_tag_fields"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.ProtobufTagFields = append(c.ProtobufTagFields, str.Value)
+					}
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["protobuf_fields"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.ProtobufFields = append(c.ProtobufFields, str.Value)
+					}
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["protobuf_timestamp_field"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.ProtobufTimestampField = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["protobuf_timestamp_units"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				units, err := time.ParseDuration(str.Value)
+				if err != nil {
+					return nil, fmt.Errorf("Unable to parse protobuf_timestamp_units as a duration, %s", err)
+				}
+				c.ProtobufTimestampUnits = units
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["protobuf_length_delimited"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.ProtobufLengthDelimited, err = strconv.ParseBool(b.Value)
+				if err != nil {
+					return nil, fmt.Errorf("Unable to parse protobuf_length_delimited as a boolean, %s", err)
+				}
+			}
+		}
+	}
+
 	c.MetricName = name
 
 	delete(tbl.Fields, "data_format")
@@ -1328,6 +1418,14 @@ func buildParser(name string, tbl *ast.Table) (parsers.Parser, error) {
 	delete(tbl.Fields, "dropwizard_time_format")
 	delete(tbl.Fields, "dropwizard_tags_path")
 	delete(tbl.Fields, "dropwizard_tag_paths")
+	delete(tbl.Fields, "protobuf_descriptor_file")
+	delete(tbl.Fields, "protobuf_message_type")
+	delete(tbl.Fields, "protobuf_measurement_field")
+	delete(tbl.Fields, "protobuf_tag_fields")
+	delete(tbl.Fields, "protobuf_fields")
+	delete(tbl.Fields, "protobuf_timestamp_field")
+	delete(tbl.Fields, "protobuf_timestamp_units")
+	delete(tbl.Fields, "protobuf_length_delimited")
 
 	return parsers.NewParser(c)
 }
@@ -1336,7 +1434,20 @@ func buildParser(name string, tbl *ast.Table) (parsers.Parser, error) {
 // a serializers.Serializer object, and creates it, which can then be added onto
 // an Output object.
 func buildSerializer(name string, tbl *ast.Table) (serializers.Serializer, error) {
-	c := &serializers.Config{TimestampUnits: time.Duration(1 * time.Second)}
+	c, err := buildSerializerConfig(name, tbl)
+	if err != nil {
+		return nil, err
+	}
+	return serializers.NewSerializer(c)
+}
+
+// buildSerializerConfig grabs the serializer options from the ast.Table.
+func buildSerializerConfig(name string, tbl *ast.Table) (*serializers.Config, error) {
+	c := &serializers.Config{
+		TimestampUnits:        time.Duration(1 * time.Second),
+		WavefrontConvertPaths: true,
+		WavefrontConvertBool:  true,
+	}
 
 	if node, ok := tbl.Fields["data_format"]; ok {
 		if kv, ok := node.(*ast.KeyValue); ok {
@@ -1366,13 +1477,34 @@ func buildSerializer(name string, tbl *ast.Table) (serializers.Serializer, error
 		}
 	}
 
+	if node, ok := tbl.Fields["graphite_tag_support"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.GraphiteTagSupport, err = strconv.ParseBool(b.Value)
+				if err != nil {
+					return nil, fmt.Errorf("Unable to parse graphite_tag_support as a boolean, %s", err)
+				}
+			}
+		}
+	}
+
 	if node, ok := tbl.Fields["json_timestamp_units"]; ok {
 		if kv, ok := node.(*ast.KeyValue); ok {
 			if str, ok := kv.Value.(*ast.String); ok {
-				timestampVal, err := time.ParseDuration(str.Value)
+				units := str.Value
+				// allow units without a quantity, eg. "ms" for "1ms"
+				switch units {
+				case "ns", "us", "µs", "ms", "s":
+					units = "1" + units
+				}
+				timestampVal, err := time.ParseDuration(units)
 				if err != nil {
 					return nil, fmt.Errorf("Unable to parse json_timestamp_units as a duration, %s", err)
 				}
+				if timestampVal <= 0 {
+					return nil, fmt.Errorf("json_timestamp_units must be positive, got %s", str.Value)
+				}
 				// now that we have a duration, truncate it to the nearest
 				// power of ten (just in case)
 				nearest_exponent := int64(math.Log10(float64(timestampVal.Nanoseconds())))
@@ -1382,11 +1514,149 @@ func buildSerializer(name string, tbl *ast.Table) (serializers.Serializer, error
 		}
 	}
 
+	if node, ok := tbl.Fields["json_batch"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.JSONBatch, err = strconv.ParseBool(b.Value)
+				if err != nil {
+					return nil, fmt.Errorf("Unable to parse json_batch as a boolean, %s", err)
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["prometheus_export_timestamp"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.PrometheusExportTimestamp, err = strconv.ParseBool(b.Value)
+				if err != nil {
+					return nil, fmt.Errorf("Unable to parse prometheus_export_timestamp as a boolean, %s", err)
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["prometheus_string_as_label"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.PrometheusStringAsLabel, err = strconv.ParseBool(b.Value)
+				if err != nil {
+					return nil, fmt.Errorf("Unable to parse prometheus_string_as_label as a boolean, %s", err)
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["carbon2_meta_tags"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.Carbon2MetaTags = append(c.Carbon2MetaTags, str.Value)
+					}
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["wavefront_simple_fields"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.WavefrontSimpleFields, err = strconv.ParseBool(b.Value)
+				if err != nil {
+					return nil, fmt.Errorf("Unable to parse wavefront_simple_fields as a boolean, %s", err)
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["wavefront_metric_separator"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.WavefrontMetricSeparator = str.Value
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["wavefront_convert_paths"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.WavefrontConvertPaths, err = strconv.ParseBool(b.Value)
+				if err != nil {
+					return nil, fmt.Errorf("Unable to parse wavefront_convert_paths as a boolean, %s", err)
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["wavefront_convert_bool"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.WavefrontConvertBool, err = strconv.ParseBool(b.Value)
+				if err != nil {
+					return nil, fmt.Errorf("Unable to parse wavefront_convert_bool as a boolean, %s", err)
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["wavefront_use_regex"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.WavefrontUseRegex, err = strconv.ParseBool(b.Value)
+				if err != nil {
+					return nil, fmt.Errorf("Unable to parse wavefront_use_regex as a boolean, %s", err)
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["wavefront_source_override"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.WavefrontSourceOverride = append(c.WavefrontSourceOverride, str.Value)
+					}
+				}
+			}
+		}
+	}
+
 	delete(tbl.Fields, "data_format")
 	delete(tbl.Fields, "prefix")
 	delete(tbl.Fields, "template")
+	delete(tbl.Fields, "graphite_tag_support")
 	delete(tbl.Fields, "json_timestamp_units")
-	return serializers.NewSerializer(c)
+	delete(tbl.Fields, "json_batch")
+	delete(tbl.Fields, "prometheus_export_timestamp")
+	delete(tbl.Fields, "prometheus_string_as_label")
+	delete(tbl.Fields, "carbon2_meta_tags")
+	delete(tbl.Fields, "wavefront_simple_fields")
+	delete(tbl.Fields, "wavefront_metric_separator")
+	delete(tbl.Fields, "wavefront_convert_paths")
+	delete(tbl.Fields, "wavefront_convert_bool")
+	delete(tbl.Fields, "wavefront_use_regex")
+	delete(tbl.Fields, "wavefront_source_override")
+
+	if node, ok := tbl.Fields["wavefront_string_to_number"]; ok {
+		if subtbl, ok := node.(*ast.Table); ok {
+			c.WavefrontStringToNumber = make(map[string][]map[string]float64)
+			if err := toml.UnmarshalTable(subtbl, c.WavefrontStringToNumber); err != nil {
+				return nil, fmt.Errorf("Unable to parse wavefront_string_to_number, %s", err)
+			}
+		}
+	}
+	delete(tbl.Fields, "wavefront_string_to_number")
+
+	return c, nil
 }
 
 // buildOutput parses output specific items from the ast.Table,
@@ -11,8 +11,11 @@ import (
 	"github.com/influxdata/telegraf/plugins/inputs/memcached"
 	"github.com/influxdata/telegraf/plugins/inputs/procstat"
 	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/plugins/serializers/wavefront"
+	"github.com/influxdata/toml"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestConfig_LoadSingleInputWithEnvVars(t *testing.T) {
@@ -174,3 +177,19 @@ func TestConfig_LoadDirectory(t *testing.T) {
 	assert.Equal(t, pConfig, c.Inputs[3].Config,
 		"Merged Testdata did not produce correct procstat metadata.")
 }
+
+func TestBuildSerializer_wavefrontStringToNumber(t *testing.T) {
+	tbl, err := toml.Parse([]byte(`
+data_format = "wavefront"
+[[wavefront_string_to_number.elasticsearch]]
+  green = 1.0
+  red = 0.0
+`))
+	require.NoError(t, err)
+
+	s, err := buildSerializer("file", tbl)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]map[string]float64{
+		"elasticsearch": {{"green": 1.0, "red": 0.0}},
+	}, s.(*wavefront.WavefrontSerializer).StringToNumber)
+}
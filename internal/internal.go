@@ -67,6 +67,50 @@ func (d *Duration) UnmarshalTOML(b []byte) error {
 	return nil
 }
 
+// Size is an amount of bytes, it can be configured as an integer or as a
+// string with a unit, ie, "10MB"
+type Size struct {
+	Size int64
+}
+
+var sizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"KiB", 1 << 10},
+	{"MiB", 1 << 20},
+	{"GiB", 1 << 30},
+	{"KB", 1000},
+	{"MB", 1000 * 1000},
+	{"GB", 1000 * 1000 * 1000},
+	{"B", 1},
+}
+
+// UnmarshalTOML parses the size from the TOML config file
+func (s *Size) UnmarshalTOML(b []byte) error {
+	str := string(bytes.Trim(b, `'`))
+	if uq, err := strconv.Unquote(str); err == nil {
+		str = uq
+	}
+	str = strings.TrimSpace(str)
+
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(str, unit.suffix) {
+			multiplier = unit.multiplier
+			str = strings.TrimSpace(strings.TrimSuffix(str, unit.suffix))
+			break
+		}
+	}
+
+	val, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid size %q", string(b))
+	}
+	s.Size = val * multiplier
+	return nil
+}
+
 // ReadLines reads contents from a file and splits them by new lines.
 // A convenience wrapper to ReadLinesOffsetN(filename, 0, -1).
 func ReadLines(filename string) ([]string, error) {
@@ -155,6 +199,74 @@ func GetTLSConfig(
 	return t, nil
 }
 
+// GetServerTLSConfig gets a tls.Config object for a listener from the given
+// certificate and key files, you must give the full path to the files.
+// Client certificates are required and verified against the allowed CA files
+// if any are given, and their common name must be one of the allowed common
+// names if any are given.  Allowed common names require allowed CA files, as
+// any certificate signed by a system root CA could have the common name.
+// If the certificate and key files are blank, returns a nil pointer.
+func GetServerTLSConfig(
+	TLSCert, TLSKey string,
+	TLSAllowedCACerts, TLSAllowedCNs []string,
+) (*tls.Config, error) {
+	if TLSCert == "" && TLSKey == "" {
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(TLSCert, TLSKey)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"Could not load TLS server key/certificate from %s:%s: %s",
+			TLSKey, TLSCert, err)
+	}
+
+	if len(TLSAllowedCNs) > 0 && len(TLSAllowedCACerts) == 0 {
+		return nil, errors.New("TLS allowed common names require TLS allowed CA certificates")
+	}
+
+	t := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if len(TLSAllowedCACerts) > 0 {
+		caCertPool := x509.NewCertPool()
+		for _, ca := range TLSAllowedCACerts {
+			caCert, err := ioutil.ReadFile(ca)
+			if err != nil {
+				return nil, fmt.Errorf("Could not load TLS CA: %s", err)
+			}
+			caCertPool.AppendCertsFromPEM(caCert)
+		}
+		t.ClientCAs = caCertPool
+		t.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	if len(TLSAllowedCNs) > 0 {
+		t.VerifyPeerCertificate = verifyCommonName(TLSAllowedCNs)
+	}
+
+	return t, nil
+}
+
+// verifyCommonName returns a function rejecting client certificates whose
+// common name is not one of the allowed common names.
+func verifyCommonName(allowedCNs []string) func([][]byte, [][]*x509.Certificate) error {
+	return func(_ [][]byte, verifiedChains [][]*x509.Certificate) error {
+		for _, chain := range verifiedChains {
+			if len(chain) == 0 {
+				continue
+			}
+			for _, cn := range allowedCNs {
+				if chain[0].Subject.CommonName == cn {
+					return nil
+				}
+			}
+		}
+		return errors.New("client certificate common name is not allowed")
+	}
+}
+
 // SnakeCase converts the given string to snake case following the Golang format:
 // acronyms are converted to lower-case and preceded by an underscore.
 func SnakeCase(in string) string {
@@ -1,11 +1,17 @@
 package internal
 
 import (
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"net"
 	"os/exec"
 	"testing"
 	"time"
 
+	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type SnakeTest struct {
@@ -162,3 +168,90 @@ func TestDuration(t *testing.T) {
 	d.UnmarshalTOML([]byte(`1.5`))
 	assert.Equal(t, time.Second, d.Duration)
 }
+
+func TestSize(t *testing.T) {
+	var s Size
+
+	assert.NoError(t, s.UnmarshalTOML([]byte(`1024`)))
+	assert.Equal(t, int64(1024), s.Size)
+
+	s = Size{}
+	assert.NoError(t, s.UnmarshalTOML([]byte(`"10MB"`)))
+	assert.Equal(t, int64(10000000), s.Size)
+
+	s = Size{}
+	assert.NoError(t, s.UnmarshalTOML([]byte(`'1 KiB'`)))
+	assert.Equal(t, int64(1024), s.Size)
+
+	s = Size{}
+	assert.NoError(t, s.UnmarshalTOML([]byte(`"512B"`)))
+	assert.Equal(t, int64(512), s.Size)
+
+	s = Size{}
+	assert.Error(t, s.UnmarshalTOML([]byte(`"ten"`)))
+}
+
+// handshake performs a TLS handshake between a server with the config and
+// a client presenting the test client certificate.
+func handshake(t *testing.T, config *tls.Config) error {
+	clientConfig, err := testutil.TLSClientConfig()
+	require.NoError(t, err)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	go func() {
+		c, err := tls.Dial("tcp", l.Addr().String(), clientConfig)
+		if err == nil {
+			// wait for the server to verify the client certificate
+			io.Copy(ioutil.Discard, c)
+			c.Close()
+		}
+	}()
+
+	c, err := l.Accept()
+	require.NoError(t, err)
+	defer c.Close()
+
+	return tls.Server(c, config).Handshake()
+}
+
+func TestGetServerTLSConfig(t *testing.T) {
+	cert := testutil.TLSFile("servercert.pem")
+	key := testutil.TLSFile("serverkey.pem")
+	ca := []string{testutil.TLSFile("cacert.pem")}
+
+	config, err := GetServerTLSConfig("", "", nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, config)
+
+	_, err = GetServerTLSConfig(cert, "", nil, nil)
+	assert.Error(t, err)
+
+	_, err = GetServerTLSConfig(cert, key, []string{"missing.pem"}, nil)
+	assert.Error(t, err)
+
+	config, err = GetServerTLSConfig(cert, key, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, tls.NoClientCert, config.ClientAuth)
+	assert.NoError(t, handshake(t, config))
+
+	config, err = GetServerTLSConfig(cert, key, ca, nil)
+	require.NoError(t, err)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, config.ClientAuth)
+	assert.NoError(t, handshake(t, config))
+
+	config, err = GetServerTLSConfig(cert, key, ca, []string{"other", "telegraf"})
+	require.NoError(t, err)
+	assert.NoError(t, handshake(t, config))
+
+	config, err = GetServerTLSConfig(cert, key, ca, []string{"other"})
+	require.NoError(t, err)
+	assert.Error(t, handshake(t, config))
+
+	// common names without CA files would accept any publicly trusted
+	// certificate with the name
+	_, err = GetServerTLSConfig(cert, key, nil, []string{"telegraf"})
+	assert.Error(t, err)
+}
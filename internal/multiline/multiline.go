@@ -0,0 +1,124 @@
+// Package multiline joins lines of text into multiline events, such as stack
+// traces or pretty printed JSON documents, before they are parsed.
+package multiline
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+const (
+	// DefaultTimeout is the time after which an incomplete event is flushed
+	// if no new line is received.
+	DefaultTimeout = 5 * time.Second
+
+	// DefaultMaxLines is the maximum number of lines of an event.
+	DefaultMaxLines = 500
+)
+
+// Config is the multiline configuration of a plugin.  Exactly one of the
+// patterns must be set.
+type Config struct {
+	// StartPattern matches the first line of each event, other lines are
+	// appended to the current event.
+	StartPattern string `toml:"start_pattern"`
+	// ContinuationPattern matches the lines appended to the current event,
+	// other lines start a new event.
+	ContinuationPattern string `toml:"continuation_pattern"`
+	// InvertMatch inverts the match of the pattern.
+	InvertMatch bool              `toml:"invert_match"`
+	Timeout     internal.Duration `toml:"timeout"`
+	MaxLines    int               `toml:"max_lines"`
+}
+
+// Multiline joins the lines of one source into events.  It is not safe for
+// concurrent use.
+type Multiline struct {
+	pattern      *regexp.Regexp
+	continuation bool
+	invert       bool
+	timeout      time.Duration
+	maxLines     int
+
+	lines []string
+}
+
+// New returns a Multiline for one source of lines, e.g. one file.
+func (c *Config) New() (*Multiline, error) {
+	var expr string
+	var continuation bool
+	switch {
+	case c.StartPattern != "" && c.ContinuationPattern != "":
+		return nil, fmt.Errorf("multiline: only one of start_pattern and continuation_pattern can be set")
+	case c.StartPattern != "":
+		expr = c.StartPattern
+	case c.ContinuationPattern != "":
+		expr = c.ContinuationPattern
+		continuation = true
+	default:
+		return nil, fmt.Errorf("multiline: start_pattern or continuation_pattern must be set")
+	}
+
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("multiline: invalid pattern %q: %s", expr, err)
+	}
+
+	m := &Multiline{
+		pattern:      pattern,
+		continuation: continuation,
+		invert:       c.InvertMatch,
+		timeout:      c.Timeout.Duration,
+		maxLines:     c.MaxLines,
+	}
+	if m.timeout <= 0 {
+		m.timeout = DefaultTimeout
+	}
+	if m.maxLines <= 0 {
+		m.maxLines = DefaultMaxLines
+	}
+	return m, nil
+}
+
+// Timeout returns the time after which Flush should be called if no new
+// line was added.
+func (m *Multiline) Timeout() time.Duration {
+	return m.timeout
+}
+
+// AddLine adds a line to the current event.  It returns the previous event
+// and true if the line completed it, which is the case when the line starts a
+// new event or the event already has the maximum number of lines.
+func (m *Multiline) AddLine(line string) (string, bool) {
+	matched := m.pattern.MatchString(line) != m.invert
+
+	var event string
+	var complete bool
+	// a line not continuing the current event, or starting a new one,
+	// completes the current event
+	if len(m.lines) > 0 && (matched != m.continuation || len(m.lines) >= m.maxLines) {
+		event, complete = m.Flush()
+	}
+
+	m.lines = append(m.lines, line)
+	return event, complete
+}
+
+// Len returns the number of lines of the current event.
+func (m *Multiline) Len() int {
+	return len(m.lines)
+}
+
+// Flush returns the current event and true, or false if there is none.
+func (m *Multiline) Flush() (string, bool) {
+	if len(m.lines) == 0 {
+		return "", false
+	}
+	event := strings.Join(m.lines, "\n")
+	m.lines = m.lines[:0]
+	return event, true
+}
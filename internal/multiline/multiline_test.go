@@ -0,0 +1,127 @@
+package multiline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addLines(m *Multiline, lines []string) []string {
+	var events []string
+	for _, line := range lines {
+		if event, ok := m.AddLine(line); ok {
+			events = append(events, event)
+		}
+	}
+	if event, ok := m.Flush(); ok {
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestStartPattern(t *testing.T) {
+	c := &Config{StartPattern: `^\d{4}-\d{2}-\d{2} `}
+	m, err := c.New()
+	require.NoError(t, err)
+
+	events := addLines(m, []string{
+		"2018-03-02 14:13:20 ERROR failed",
+		"java.lang.NullPointerException",
+		"\tat Main.main(Main.java:1)",
+		"2018-03-02 14:13:21 INFO done",
+	})
+	assert.Equal(t, []string{
+		"2018-03-02 14:13:20 ERROR failed\njava.lang.NullPointerException\n\tat Main.main(Main.java:1)",
+		"2018-03-02 14:13:21 INFO done",
+	}, events)
+}
+
+func TestLen(t *testing.T) {
+	c := &Config{StartPattern: `^\S`}
+	m, err := c.New()
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, m.Len())
+	m.AddLine("first")
+	m.AddLine("  continued")
+	assert.Equal(t, 2, m.Len())
+	m.AddLine("second")
+	assert.Equal(t, 1, m.Len())
+	m.Flush()
+	assert.Equal(t, 0, m.Len())
+}
+
+func TestContinuationPattern(t *testing.T) {
+	c := &Config{ContinuationPattern: `^\s`}
+	m, err := c.New()
+	require.NoError(t, err)
+
+	events := addLines(m, []string{
+		"first",
+		"  continued",
+		"second",
+		"third",
+		"\tcontinued",
+	})
+	assert.Equal(t, []string{"first\n  continued", "second", "third\n\tcontinued"}, events)
+}
+
+func TestInvertMatch(t *testing.T) {
+	// lines not starting with a date continue the current event
+	c := &Config{ContinuationPattern: `^\d{4}-\d{2}-\d{2} `, InvertMatch: true}
+	m, err := c.New()
+	require.NoError(t, err)
+
+	events := addLines(m, []string{
+		"2018-03-02 14:13:20 {",
+		`  "a": 1`,
+		"}",
+		"2018-03-02 14:13:21 {}",
+	})
+	assert.Equal(t, []string{"2018-03-02 14:13:20 {\n  \"a\": 1\n}", "2018-03-02 14:13:21 {}"}, events)
+
+	c = &Config{StartPattern: `^\s`, InvertMatch: true}
+	m, err = c.New()
+	require.NoError(t, err)
+
+	events = addLines(m, []string{
+		"first",
+		"  continued",
+		"second",
+	})
+	assert.Equal(t, []string{"first\n  continued", "second"}, events)
+}
+
+func TestMaxLines(t *testing.T) {
+	c := &Config{StartPattern: `^start`, MaxLines: 2}
+	m, err := c.New()
+	require.NoError(t, err)
+
+	events := addLines(m, []string{
+		"start",
+		"1",
+		"2",
+		"3",
+		"start",
+	})
+	assert.Equal(t, []string{"start\n1", "2\n3", "start"}, events)
+}
+
+func TestInvalidConfig(t *testing.T) {
+	_, err := (&Config{}).New()
+	assert.Error(t, err)
+
+	_, err = (&Config{StartPattern: "a", ContinuationPattern: "b"}).New()
+	assert.Error(t, err)
+
+	_, err = (&Config{StartPattern: "("}).New()
+	assert.Error(t, err)
+}
+
+func TestDefaults(t *testing.T) {
+	m, err := (&Config{StartPattern: "a"}).New()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTimeout, m.Timeout())
+	assert.Equal(t, DefaultMaxLines, m.maxLines)
+}
@@ -0,0 +1,260 @@
+package rotate
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxArchives is the number of rotated files kept by default
+	DefaultMaxArchives = 5
+
+	// timeFormat is used in the name of rotated files, it sorts
+	// lexicographically in the order the files were rotated.
+	timeFormat = "2006-01-02T15-04-05.000000000"
+
+	filePerm = 0644
+)
+
+// rename is so tests can mock out os.Rename usage.
+var rename = os.Rename
+
+// FileWriter is an io.WriteCloser that appends to a file and rotates it when
+// it has been open for longer than the interval or when it would grow larger
+// than the maximum size.  Rotated files are renamed with the time of the
+// rotation inserted before the extension, ie, metrics.out becomes
+// metrics.2018-03-01T15-04-05.000000000.out, and optionally compressed.
+//
+// If the file is moved or removed by another process, such as logrotate, it
+// is reopened on the next write.  If it is truncated, as with the copytruncate
+// option of logrotate, its size is taken again.
+type FileWriter struct {
+	filename    string
+	interval    time.Duration
+	maxSize     int64
+	maxArchives int
+	compress    bool
+
+	sync.Mutex
+	current      *os.File
+	currentInfo  os.FileInfo
+	expireTime   time.Time
+	bytesWritten int64
+}
+
+// NewFileWriter opens filename for appending.  A zero interval or maxSize
+// disables the corresponding rotation, a negative maxArchives keeps all
+// rotated files.
+func NewFileWriter(
+	filename string,
+	interval time.Duration,
+	maxSize int64,
+	maxArchives int,
+	compress bool,
+) (*FileWriter, error) {
+	w := &FileWriter{
+		filename:    filename,
+		interval:    interval,
+		maxSize:     maxSize,
+		maxArchives: maxArchives,
+		compress:    compress,
+	}
+
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write writes p to the current file, rotating it first if needed.
+func (w *FileWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.moved() {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if w.shouldRotate(int64(len(p))) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.current.Write(p)
+	w.bytesWritten += int64(n)
+	return n, err
+}
+
+// Close closes the current file.
+func (w *FileWriter) Close() error {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.current == nil {
+		return nil
+	}
+	err := w.current.Close()
+	w.current = nil
+	return err
+}
+
+// open opens the file for appending, replacing the current file only once the
+// new one has been opened successfully.
+func (w *FileWriter) open() error {
+	f, err := os.OpenFile(w.filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, filePerm)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	if w.current != nil {
+		w.current.Close()
+	}
+	w.current = f
+	w.currentInfo = info
+	w.bytesWritten = info.Size()
+	w.expireTime = time.Now().Add(w.interval)
+	return nil
+}
+
+// moved reports if the file at filename is no longer the file being written.
+// If it is the same file but smaller than what was written, it has been
+// truncated and the size used for rotation is reset to its new size.
+func (w *FileWriter) moved() bool {
+	info, err := os.Stat(w.filename)
+	if err != nil {
+		return true
+	}
+	if !os.SameFile(info, w.currentInfo) {
+		return true
+	}
+	if info.Size() < w.bytesWritten {
+		w.bytesWritten = info.Size()
+	}
+	return false
+}
+
+// shouldRotate reports if the file needs to be rotated before writing size
+// bytes, empty files are never rotated.
+func (w *FileWriter) shouldRotate(size int64) bool {
+	if w.bytesWritten == 0 {
+		return false
+	}
+	if w.interval > 0 && !time.Now().Before(w.expireTime) {
+		return true
+	}
+	return w.maxSize > 0 && w.bytesWritten+size > w.maxSize
+}
+
+func (w *FileWriter) rotate() error {
+	if err := w.current.Close(); err != nil {
+		return err
+	}
+	w.current = nil
+
+	ext := filepath.Ext(w.filename)
+	base := strings.TrimSuffix(w.filename, ext)
+	archive := base + "." + time.Now().Format(timeFormat) + ext
+	if err := rename(w.filename, archive); err != nil {
+		// the file is still in place, it is reopened so that the next
+		// write retries the rotation
+		if oerr := w.open(); oerr != nil {
+			return oerr
+		}
+		return err
+	}
+
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	if w.compress {
+		if err := compressFile(archive); err != nil {
+			return err
+		}
+	}
+
+	return w.purgeArchives(base, ext)
+}
+
+// purgeArchives removes the oldest rotated files until at most maxArchives
+// are left.
+func (w *FileWriter) purgeArchives(base, ext string) error {
+	if w.maxArchives < 0 {
+		return nil
+	}
+
+	matches, err := filepath.Glob(base + ".*" + ext + "*")
+	if err != nil {
+		return err
+	}
+
+	prefix := base + "."
+	var archives []string
+	for _, match := range matches {
+		stamp := strings.TrimPrefix(match, prefix)
+		stamp = strings.TrimSuffix(stamp, ".gz")
+		stamp = strings.TrimSuffix(stamp, ext)
+		if _, err := time.Parse(timeFormat, stamp); err == nil {
+			archives = append(archives, match)
+		}
+	}
+	if len(archives) <= w.maxArchives {
+		return nil
+	}
+
+	sort.Strings(archives)
+	for _, archive := range archives[:len(archives)-w.maxArchives] {
+		if err := os.Remove(archive); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compressFile replaces filename with a gzip compressed copy named
+// filename.gz.
+func compressFile(filename string) error {
+	in, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp := filename + ".gz.tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, filePerm)
+	if err != nil {
+		return err
+	}
+
+	gw := gzip.NewWriter(out)
+	_, err = io.Copy(gw, in)
+	if err == nil {
+		err = gw.Close()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := os.Rename(tmp, filename+".gz"); err != nil {
+		return err
+	}
+	return os.Remove(filename)
+}
@@ -0,0 +1,212 @@
+package rotate
+
+import (
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func archives(t *testing.T, dir string) []string {
+	matches, err := filepath.Glob(filepath.Join(dir, "metrics.*.out*"))
+	require.NoError(t, err)
+	return matches
+}
+
+func TestFileWriter_NoRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writer, err := NewFileWriter(filepath.Join(dir, "metrics.out"), 0, 0, DefaultMaxArchives, false)
+	require.NoError(t, err)
+	defer writer.Close()
+
+	_, err = writer.Write([]byte("Hello World"))
+	require.NoError(t, err)
+	_, err = writer.Write([]byte("Hello World 2"))
+	require.NoError(t, err)
+
+	assert.Len(t, archives(t, dir), 0)
+}
+
+func TestFileWriter_TimeRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writer, err := NewFileWriter(filepath.Join(dir, "metrics.out"), time.Millisecond, 0, -1, false)
+	require.NoError(t, err)
+	defer writer.Close()
+
+	_, err = writer.Write([]byte("Hello World"))
+	require.NoError(t, err)
+	time.Sleep(2 * time.Millisecond)
+	_, err = writer.Write([]byte("Hello World 2"))
+	require.NoError(t, err)
+
+	assert.Len(t, archives(t, dir), 1)
+}
+
+func TestFileWriter_SizeRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "metrics.out")
+	writer, err := NewFileWriter(filename, 0, 10, -1, false)
+	require.NoError(t, err)
+	defer writer.Close()
+
+	_, err = writer.Write([]byte("Hello"))
+	require.NoError(t, err)
+	_, err = writer.Write([]byte("World"))
+	require.NoError(t, err)
+	_, err = writer.Write([]byte("Hello World 2"))
+	require.NoError(t, err)
+
+	files := archives(t, dir)
+	require.Len(t, files, 1)
+	b, err := ioutil.ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Equal(t, "HelloWorld", string(b))
+
+	b, err = ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello World 2", string(b))
+}
+
+func TestFileWriter_MaxArchives(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writer, err := NewFileWriter(filepath.Join(dir, "metrics.out"), 0, 5, 2, false)
+	require.NoError(t, err)
+	defer writer.Close()
+
+	for i := 0; i < 5; i++ {
+		_, err = writer.Write([]byte("Hello"))
+		require.NoError(t, err)
+	}
+
+	assert.Len(t, archives(t, dir), 2)
+}
+
+func TestFileWriter_Compress(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writer, err := NewFileWriter(filepath.Join(dir, "metrics.out"), 0, 5, -1, true)
+	require.NoError(t, err)
+	defer writer.Close()
+
+	_, err = writer.Write([]byte("Hello"))
+	require.NoError(t, err)
+	_, err = writer.Write([]byte("World"))
+	require.NoError(t, err)
+
+	files := archives(t, dir)
+	require.Len(t, files, 1)
+	assert.Equal(t, ".gz", filepath.Ext(files[0]))
+
+	f, err := os.Open(files[0])
+	require.NoError(t, err)
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	b, err := ioutil.ReadAll(gr)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", string(b))
+}
+
+func TestFileWriter_ReopenAfterMove(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "metrics.out")
+	writer, err := NewFileWriter(filename, 0, 0, -1, false)
+	require.NoError(t, err)
+	defer writer.Close()
+
+	_, err = writer.Write([]byte("Hello"))
+	require.NoError(t, err)
+
+	// simulate logrotate moving the file away
+	require.NoError(t, os.Rename(filename, filename+".1"))
+
+	_, err = writer.Write([]byte("World"))
+	require.NoError(t, err)
+
+	b, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "World", string(b))
+
+	b, err = ioutil.ReadFile(filename + ".1")
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", string(b))
+}
+
+func TestFileWriter_Truncated(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "metrics.out")
+	writer, err := NewFileWriter(filename, 0, 10, -1, false)
+	require.NoError(t, err)
+	defer writer.Close()
+
+	_, err = writer.Write([]byte("HelloWorld"))
+	require.NoError(t, err)
+
+	// simulate logrotate copying and truncating the file
+	require.NoError(t, os.Truncate(filename, 0))
+
+	_, err = writer.Write([]byte("Hello"))
+	require.NoError(t, err)
+
+	assert.Len(t, archives(t, dir), 0)
+	b, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello", string(b))
+}
+
+func TestFileWriter_RenameError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	defer func(r func(string, string) error) { rename = r }(rename)
+	rename = func(_, _ string) error {
+		return errors.New("rename failed")
+	}
+
+	writer, err := NewFileWriter(filepath.Join(dir, "metrics.out"), 0, 10, -1, false)
+	require.NoError(t, err)
+	defer writer.Close()
+
+	_, err = writer.Write([]byte("Hello World"))
+	require.NoError(t, err)
+	_, err = writer.Write([]byte("Hello World 2"))
+	require.Error(t, err)
+	assert.Len(t, archives(t, dir), 0)
+
+	// the rotation succeeds once renaming works again
+	rename = os.Rename
+	_, err = writer.Write([]byte("Hello World 3"))
+	require.NoError(t, err)
+	assert.Len(t, archives(t, dir), 1)
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "metrics.out"))
+	require.NoError(t, err)
+	assert.Equal(t, "Hello World 3", string(b))
+}
@@ -0,0 +1,745 @@
+package snmp
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode"
+)
+
+// Kinds of the tokens of a MIB module.
+const (
+	tokenEOF = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenPunct
+)
+
+type token struct {
+	kind int
+	text string
+	line int
+}
+
+// lexer splits a MIB file in tokens, skipping the comments.
+type lexer struct {
+	r    *bufio.Reader
+	line int
+}
+
+func (l *lexer) next() (token, error) {
+	for {
+		c, err := l.read()
+		if err == io.EOF {
+			return token{kind: tokenEOF, line: l.line}, nil
+		}
+		if err != nil {
+			return token{}, err
+		}
+
+		switch {
+		case unicode.IsSpace(c):
+			continue
+		case c == '-' && l.peek() == '-':
+			l.read()
+			l.skipComment()
+			continue
+		case c == '"':
+			return l.readString()
+		case c == '\'':
+			// binary or hexadecimal string, such as '00'H
+			text, err := l.readUntil('\'')
+			if err != nil {
+				return token{}, err
+			}
+			if p := l.peek(); p == 'H' || p == 'h' || p == 'B' || p == 'b' {
+				l.read()
+			}
+			return token{kind: tokenString, text: text, line: l.line}, nil
+		case c == ':' && l.peek() == ':':
+			l.read()
+			if l.peek() == '=' {
+				l.read()
+				return token{kind: tokenPunct, text: "::=", line: l.line}, nil
+			}
+			return token{kind: tokenPunct, text: "::", line: l.line}, nil
+		case c == '.' && l.peek() == '.':
+			l.read()
+			return token{kind: tokenPunct, text: "..", line: l.line}, nil
+		case isDigit(c) || (c == '-' && isDigit(l.peek())):
+			return l.readWord(c, tokenNumber)
+		case isIdentChar(c):
+			return l.readWord(c, tokenIdent)
+		default:
+			return token{kind: tokenPunct, text: string(c), line: l.line}, nil
+		}
+	}
+}
+
+func (l *lexer) read() (rune, error) {
+	c, _, err := l.r.ReadRune()
+	if c == '\n' {
+		l.line++
+	}
+	return c, err
+}
+
+func (l *lexer) peek() rune {
+	c, _, err := l.r.ReadRune()
+	if err != nil {
+		return 0
+	}
+	l.r.UnreadRune()
+	return c
+}
+
+// skipComment skips a comment, which ends at the end of the line or at the
+// next "--".
+func (l *lexer) skipComment() {
+	for {
+		c, err := l.read()
+		if err != nil || c == '\n' {
+			return
+		}
+		if c == '-' && l.peek() == '-' {
+			l.read()
+			return
+		}
+	}
+}
+
+func (l *lexer) readString() (token, error) {
+	line := l.line
+	text, err := l.readUntil('"')
+	if err != nil {
+		return token{}, fmt.Errorf("line %d: unterminated string", line)
+	}
+	return token{kind: tokenString, text: text, line: line}, nil
+}
+
+func (l *lexer) readUntil(end rune) (string, error) {
+	var buf bytes.Buffer
+	for {
+		c, err := l.read()
+		if err != nil {
+			return "", err
+		}
+		if c == end {
+			return buf.String(), nil
+		}
+		buf.WriteRune(c)
+	}
+}
+
+func (l *lexer) readWord(first rune, kind int) (token, error) {
+	var buf bytes.Buffer
+	buf.WriteRune(first)
+	for {
+		c := l.peek()
+		// a "-" is part of the word unless it starts a comment
+		if !isIdentChar(c) || (c == '-' && l.peekComment()) {
+			break
+		}
+		l.read()
+		buf.WriteRune(c)
+	}
+	return token{kind: kind, text: buf.String(), line: l.line}, nil
+}
+
+// peekComment returns true if the next runes are "--".
+func (l *lexer) peekComment() bool {
+	b, err := l.r.Peek(2)
+	return err == nil && b[0] == '-' && b[1] == '-'
+}
+
+func isDigit(c rune) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentChar(c rune) bool {
+	return c == '-' || c == '_' || isDigit(c) || (c < unicode.MaxASCII && unicode.IsLetter(c))
+}
+
+// module is a parsed MIB module.
+type module struct {
+	name string
+	// imported symbols and their modules
+	imports map[string]string
+	// OID assignments, in the order of the module
+	objects []*object
+	// names of the objects
+	defined map[string]bool
+	// type assignments and textual conventions
+	types map[string]*syntax
+}
+
+func (m *module) add(o *object) {
+	m.objects = append(m.objects, o)
+	m.defined[o.name] = true
+}
+
+// object is the assignment of an OID.
+type object struct {
+	name string
+	// OID value, whose first component may be the name of another object
+	oid []oidComponent
+	// SYNTAX of an OBJECT-TYPE
+	syntax *syntax
+	// MAX-ACCESS or ACCESS of an OBJECT-TYPE
+	access string
+	// INDEX and AUGMENTS of a table entry
+	index    []string
+	augments string
+}
+
+type oidComponent struct {
+	name   string
+	num    uint32
+	hasNum bool
+}
+
+// syntax is a type, with the enumerated values of INTEGER types.
+type syntax struct {
+	name  string
+	enums map[int64]string
+	bits  bool
+}
+
+// macros assigning OIDs
+var oidMacros = map[string]bool{
+	"OBJECT-TYPE":        true,
+	"OBJECT-IDENTITY":    true,
+	"MODULE-IDENTITY":    true,
+	"NOTIFICATION-TYPE":  true,
+	"OBJECT-GROUP":       true,
+	"NOTIFICATION-GROUP": true,
+	"MODULE-COMPLIANCE":  true,
+	"AGENT-CAPABILITIES": true,
+	"TRAP-TYPE":          true,
+}
+
+// parser parses the MIB modules of a file.
+type parser struct {
+	lex    *lexer
+	tok    token
+	peeked []token
+}
+
+// parseModules parses the MIB modules of the reader.
+func parseModules(r io.Reader) ([]*module, error) {
+	p := &parser{lex: &lexer{r: bufio.NewReader(r), line: 1}}
+
+	var modules []*module
+	for {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind == tokenEOF {
+			return modules, nil
+		}
+
+		// NAME DEFINITIONS ::= BEGIN
+		if p.tok.kind != tokenIdent {
+			return nil, p.errorf("expected module name")
+		}
+		m := &module{
+			name:    p.tok.text,
+			imports: make(map[string]string),
+			defined: make(map[string]bool),
+			types:   make(map[string]*syntax),
+		}
+		for _, expected := range []string{"DEFINITIONS", "::=", "BEGIN"} {
+			if err := p.expect(expected); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.parseBody(m); err != nil {
+			return nil, fmt.Errorf("module %s: %s", m.name, err)
+		}
+		modules = append(modules, m)
+	}
+}
+
+func (p *parser) next() error {
+	if len(p.peeked) > 0 {
+		p.tok = p.peeked[0]
+		p.peeked = p.peeked[1:]
+		return nil
+	}
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+// peek returns the next token without consuming it.
+func (p *parser) peek() (token, error) {
+	if len(p.peeked) == 0 {
+		tok, err := p.lex.next()
+		if err != nil {
+			return tok, err
+		}
+		p.peeked = append(p.peeked, tok)
+	}
+	return p.peeked[0], nil
+}
+
+func (p *parser) expect(text string) error {
+	if err := p.next(); err != nil {
+		return err
+	}
+	if p.tok.text != text || p.tok.kind == tokenString {
+		return p.errorf("expected %q", text)
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	found := p.tok.text
+	if p.tok.kind == tokenEOF {
+		found = "end of file"
+	}
+	return fmt.Errorf("line %d: %s, found %q", p.tok.line, fmt.Sprintf(format, args...), found)
+}
+
+// is returns true if the current token is the punctuation or keyword.
+func (p *parser) is(text string) bool {
+	return p.tok.kind != tokenString && p.tok.text == text
+}
+
+func (p *parser) parseBody(m *module) error {
+	for {
+		if err := p.next(); err != nil {
+			return err
+		}
+
+		switch {
+		case p.tok.kind == tokenEOF:
+			return p.errorf("expected END")
+		case p.is("END"):
+			return nil
+		case p.is("IMPORTS"):
+			if err := p.parseImports(m); err != nil {
+				return err
+			}
+		case p.is("EXPORTS"):
+			if err := p.skipUntil(";"); err != nil {
+				return err
+			}
+		case p.tok.kind == tokenIdent:
+			if err := p.parseAssignment(m); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// parseImports parses the imported symbols, "a, b FROM MODULE-A c FROM
+// MODULE-B;".
+func (p *parser) parseImports(m *module) error {
+	var symbols []string
+	for {
+		if err := p.next(); err != nil {
+			return err
+		}
+		switch {
+		case p.tok.kind == tokenEOF:
+			return p.errorf("expected ;")
+		case p.is(";"):
+			return nil
+		case p.is(","):
+		case p.is("FROM"):
+			if err := p.next(); err != nil {
+				return err
+			}
+			for _, s := range symbols {
+				m.imports[s] = p.tok.text
+			}
+			symbols = nil
+		default:
+			symbols = append(symbols, p.tok.text)
+		}
+	}
+}
+
+// parseAssignment parses the assignment of the identifier of the current
+// token.  The assignments which are not of an OID or of a type are skipped.
+func (p *parser) parseAssignment(m *module) error {
+	name := p.tok.text
+	next, err := p.peek()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case next.text == "OBJECT":
+		// name OBJECT IDENTIFIER ::= { ... }
+		p.next()
+		if err := p.next(); err != nil {
+			return err
+		}
+		if !p.is("IDENTIFIER") {
+			return nil
+		}
+		if err := p.expect("::="); err != nil {
+			return err
+		}
+		oid, err := p.parseOID()
+		if err != nil {
+			return err
+		}
+		m.add(&object{name: name, oid: oid})
+	case next.text == "::=":
+		p.next()
+		next, err := p.peek()
+		if err != nil {
+			return err
+		}
+		switch {
+		case next.text == "{" && unicode.IsLower(rune(name[0])):
+			// value assignment without type, name ::= { ... }
+			oid, err := p.parseOID()
+			if err != nil {
+				return err
+			}
+			m.add(&object{name: name, oid: oid})
+		case next.text == "TEXTUAL-CONVENTION":
+			p.next()
+			s, err := p.parseTextualConvention()
+			if err != nil {
+				return err
+			}
+			m.types[name] = s
+		default:
+			s, err := p.parseSyntax()
+			if err != nil {
+				return err
+			}
+			m.types[name] = s
+		}
+	case next.text == "MACRO":
+		// macro definitions of the SMI modules, MACRO ::= BEGIN ... END
+		return p.skipUntil("END")
+	case next.kind == tokenIdent && oidMacros[next.text]:
+		p.next()
+		o, err := p.parseMacro(name, p.tok.text)
+		if err != nil {
+			return err
+		}
+		m.add(o)
+	}
+	return nil
+}
+
+// parseMacro parses the clauses of an OID macro up to its value.
+func (p *parser) parseMacro(name, macro string) (*object, error) {
+	o := &object{name: name}
+	var enterprise string
+	for {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind == tokenEOF {
+			return nil, p.errorf("expected ::=")
+		}
+		if p.tok.kind == tokenString {
+			continue
+		}
+
+		switch {
+		case p.is("::="):
+			if macro != "TRAP-TYPE" {
+				oid, err := p.parseOID()
+				if err != nil {
+					return nil, err
+				}
+				o.oid = oid
+				return o, nil
+			}
+			// SMIv1 traps are assigned the OID enterprise.0.number
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			n, err := strconv.ParseUint(p.tok.text, 10, 32)
+			if err != nil || enterprise == "" {
+				return nil, p.errorf("invalid trap number")
+			}
+			o.oid = []oidComponent{{name: enterprise}, {num: 0, hasNum: true}, {num: uint32(n), hasNum: true}}
+			return o, nil
+		case p.is("SYNTAX") && macro == "OBJECT-TYPE":
+			s, err := p.parseSyntax()
+			if err != nil {
+				return nil, err
+			}
+			o.syntax = s
+		case (p.is("MAX-ACCESS") || p.is("ACCESS")) && macro == "OBJECT-TYPE":
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			o.access = p.tok.text
+		case p.is("INDEX") && macro == "OBJECT-TYPE":
+			names, err := p.parseNames()
+			if err != nil {
+				return nil, err
+			}
+			o.index = names
+		case p.is("AUGMENTS") && macro == "OBJECT-TYPE":
+			names, err := p.parseNames()
+			if err != nil {
+				return nil, err
+			}
+			if len(names) > 0 {
+				o.augments = names[0]
+			}
+		case p.is("ENTERPRISE") && macro == "TRAP-TYPE":
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			enterprise = p.tok.text
+		case p.is("{"):
+			// the lists of the other clauses, such as OBJECTS { ... }
+			if err := p.skipBalanced("{", "}"); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// parseTextualConvention parses the clauses of a textual convention up to
+// its SYNTAX.
+func (p *parser) parseTextualConvention() (*syntax, error) {
+	for {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind == tokenEOF {
+			return nil, p.errorf("expected SYNTAX")
+		}
+		if p.is("SYNTAX") {
+			return p.parseSyntax()
+		}
+	}
+}
+
+// parseSyntax parses a type, such as "OCTET STRING (SIZE (0..255))" or
+// "INTEGER { up(1), down(2) }".
+func (p *parser) parseSyntax() (*syntax, error) {
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	// tagged types of the SMI, such as [APPLICATION 1] IMPLICIT INTEGER
+	if p.is("[") {
+		if err := p.skipBalanced("[", "]"); err != nil {
+			return nil, err
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if p.is("IMPLICIT") {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	s := &syntax{name: p.tok.text}
+	switch {
+	case p.is("OCTET") || p.is("OBJECT"):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		s.name += " " + p.tok.text
+	case p.is("SEQUENCE"):
+		next, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if next.text == "OF" {
+			p.next()
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			s.name = "SEQUENCE OF " + p.tok.text
+			return s, nil
+		}
+		p.next()
+		return s, p.skipBalanced("{", "}")
+	case p.is("CHOICE"):
+		p.next()
+		return s, p.skipBalanced("{", "}")
+	case p.is("BITS"):
+		s.bits = true
+	}
+
+	next, err := p.peek()
+	if err != nil {
+		return nil, err
+	}
+	if next.text == "{" && next.kind == tokenPunct {
+		p.next()
+		enums, err := p.parseEnums()
+		if err != nil {
+			return nil, err
+		}
+		s.enums = enums
+		if next, err = p.peek(); err != nil {
+			return nil, err
+		}
+	}
+	if next.text == "(" && next.kind == tokenPunct {
+		// constraints of size or range
+		p.next()
+		if err := p.skipBalanced("(", ")"); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// parseEnums parses the named numbers of a type, "{ up(1), down(2) }", the
+// opening brace being the current token.
+func (p *parser) parseEnums() (map[int64]string, error) {
+	enums := make(map[int64]string)
+	for {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		switch {
+		case p.is("}"):
+			return enums, nil
+		case p.is(","):
+		case p.tok.kind == tokenIdent:
+			name := p.tok.text
+			if err := p.expect("("); err != nil {
+				return nil, err
+			}
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			n, err := strconv.ParseInt(p.tok.text, 10, 64)
+			if err != nil {
+				return nil, p.errorf("invalid number")
+			}
+			enums[n] = name
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, p.errorf("expected named number")
+		}
+	}
+}
+
+// parseOID parses an OID value, "{ parent 1 }" or "{ iso org(3) 6 }".
+func (p *parser) parseOID() ([]oidComponent, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	var oid []oidComponent
+	for {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		switch {
+		case p.is("}"):
+			if len(oid) == 0 {
+				return nil, p.errorf("empty OID")
+			}
+			return oid, nil
+		case p.tok.kind == tokenNumber:
+			n, err := strconv.ParseUint(p.tok.text, 10, 32)
+			if err != nil {
+				return nil, p.errorf("invalid sub-identifier")
+			}
+			oid = append(oid, oidComponent{num: uint32(n), hasNum: true})
+		case p.tok.kind == tokenIdent:
+			c := oidComponent{name: p.tok.text}
+			next, err := p.peek()
+			if err != nil {
+				return nil, err
+			}
+			if next.text == "(" {
+				p.next()
+				if err := p.next(); err != nil {
+					return nil, err
+				}
+				n, err := strconv.ParseUint(p.tok.text, 10, 32)
+				if err != nil {
+					return nil, p.errorf("invalid sub-identifier")
+				}
+				c.num = uint32(n)
+				c.hasNum = true
+				if err := p.expect(")"); err != nil {
+					return nil, err
+				}
+			}
+			oid = append(oid, c)
+		default:
+			return nil, p.errorf("expected sub-identifier")
+		}
+	}
+}
+
+// parseNames parses a list of names, "{ a, IMPLIED b }".
+func (p *parser) parseNames() ([]string, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var names []string
+	for {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		switch {
+		case p.is("}"):
+			return names, nil
+		case p.tok.kind == tokenEOF:
+			return nil, p.errorf("expected }")
+		case p.is(",") || p.is("IMPLIED"):
+		default:
+			names = append(names, p.tok.text)
+		}
+	}
+}
+
+// skipBalanced skips the tokens up to the closing token matching the current
+// opening token.
+func (p *parser) skipBalanced(open, close string) error {
+	depth := 1
+	for depth > 0 {
+		if err := p.next(); err != nil {
+			return err
+		}
+		switch {
+		case p.tok.kind == tokenEOF:
+			return p.errorf("expected %s", close)
+		case p.is(open):
+			depth++
+		case p.is(close):
+			depth--
+		}
+	}
+	return nil
+}
+
+// skipUntil skips the tokens up to the keyword or punctuation.
+func (p *parser) skipUntil(text string) error {
+	for {
+		if err := p.next(); err != nil {
+			return err
+		}
+		if p.tok.kind == tokenEOF {
+			return p.errorf("expected %s", text)
+		}
+		if p.is(text) {
+			return nil
+		}
+	}
+}
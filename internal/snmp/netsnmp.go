@@ -0,0 +1,129 @@
+package snmp
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// ExecCommand runs the net-snmp tools, so that the tests of the plugins can
+// mock them out.
+var ExecCommand = exec.Command
+
+// execCmd executes the specified command, returning the STDOUT content.
+// If command exits with error status, the output is captured into the returned error.
+func execCmd(arg0 string, args ...string) ([]byte, error) {
+	out, err := ExecCommand(arg0, args...).Output()
+	if err != nil {
+		if err, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("%s: %s", err, bytes.TrimRight(err.Stderr, "\r\n"))
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+type netsnmpTranslation struct {
+	mibName    string
+	oidNum     string
+	oidText    string
+	conversion string
+	err        error
+}
+
+var netsnmpCacheLock sync.Mutex
+
+// netsnmpCache holds the translations of NetsnmpTranslate, shared by all the
+// plugins.
+var netsnmpCache map[string]netsnmpTranslation
+
+// NetsnmpTranslate translates the OID with the snmptranslate tool of
+// net-snmp, returning the same values as Tree.Translate.  If snmptranslate
+// is not installed, numeric OIDs are returned as is.  The results are cached.
+func NetsnmpTranslate(oid string) (mibName string, oidNum string, oidText string, conversion string, err error) {
+	netsnmpCacheLock.Lock()
+	defer netsnmpCacheLock.Unlock()
+
+	if netsnmpCache == nil {
+		netsnmpCache = make(map[string]netsnmpTranslation)
+	}
+
+	// This will result in only one call to snmptranslate running at a time,
+	// which avoids slamming the system when lots of lookups are performed.
+	t, ok := netsnmpCache[oid]
+	if !ok {
+		t.mibName, t.oidNum, t.oidText, t.conversion, t.err = netsnmpTranslate(oid)
+		netsnmpCache[oid] = t
+	}
+	return t.mibName, t.oidNum, t.oidText, t.conversion, t.err
+}
+
+func netsnmpTranslate(oid string) (mibName string, oidNum string, oidText string, conversion string, err error) {
+	var out []byte
+	if strings.ContainsAny(oid, ":abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ") {
+		out, err = execCmd("snmptranslate", "-Td", "-Ob", oid)
+	} else {
+		out, err = execCmd("snmptranslate", "-Td", "-Ob", "-m", "all", oid)
+		if err, ok := err.(*exec.Error); ok && err.Err == exec.ErrNotFound {
+			// Silently discard error if snmptranslate not found and we have a numeric OID.
+			// Meaning we can get by without the lookup.
+			return "", oid, oid, "", nil
+		}
+	}
+	if err != nil {
+		return "", "", "", "", err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewBuffer(out))
+	ok := scanner.Scan()
+	if !ok && scanner.Err() != nil {
+		return "", "", "", "", fmt.Errorf("getting OID text: %s", scanner.Err())
+	}
+
+	oidText = scanner.Text()
+
+	i := strings.Index(oidText, "::")
+	if i == -1 {
+		// was not found in MIB.
+		if bytes.Contains(out, []byte("[TRUNCATED]")) {
+			return "", oid, oid, "", nil
+		}
+		// not truncated, but not fully found. We still need to parse out numeric OID, so keep going
+		oidText = oid
+	} else {
+		mibName = oidText[:i]
+		oidText = oidText[i+2:]
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.HasPrefix(line, "  -- TEXTUAL CONVENTION ") {
+			tc := strings.TrimPrefix(line, "  -- TEXTUAL CONVENTION ")
+			if c, ok := tcConversions[tc]; ok {
+				conversion = c
+			}
+		} else if strings.HasPrefix(line, "::= { ") {
+			objs := strings.TrimPrefix(line, "::= { ")
+			objs = strings.TrimSuffix(objs, " }")
+
+			for _, obj := range strings.Split(objs, " ") {
+				if len(obj) == 0 {
+					continue
+				}
+				if i := strings.Index(obj, "("); i != -1 {
+					obj = obj[i+1:]
+					oidNum += "." + obj[:strings.Index(obj, ")")]
+				} else {
+					oidNum += "." + obj
+				}
+			}
+			break
+		}
+	}
+
+	return mibName, oidNum, oidText, conversion, nil
+}
@@ -0,0 +1,99 @@
+package snmp
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ifPhysAddressOutput = `IF-MIB::ifPhysAddress.1
+ifPhysAddress OBJECT-TYPE
+  -- FROM	IF-MIB
+  -- TEXTUAL CONVENTION PhysAddress
+  SYNTAX	OCTET STRING
+  MAX-ACCESS	read-only
+  STATUS	current
+::= { iso(1) org(3) dod(6) internet(1) mgmt(2) mib-2(1) interfaces(2) ifTable(2) ifEntry(1) ifPhysAddress(6) 1 }
+`
+
+// mockSnmptranslate runs this test binary instead of snmptranslate, printing
+// the output of ifPhysAddress.1.
+func mockSnmptranslate(arg0 string, args ...string) *exec.Cmd {
+	cmd := exec.Command(os.Args[0], "-test.run=TestMockSnmptranslate")
+	cmd.Env = append(os.Environ(), "MOCK_SNMPTRANSLATE=1")
+	return cmd
+}
+
+// This is not a real test, it prints the output of the mocked snmptranslate.
+func TestMockSnmptranslate(t *testing.T) {
+	if os.Getenv("MOCK_SNMPTRANSLATE") != "1" {
+		return
+	}
+	fmt.Print(ifPhysAddressOutput)
+	os.Exit(0)
+}
+
+func TestNetsnmpTranslate(t *testing.T) {
+	defer func(ec func(string, ...string) *exec.Cmd) { ExecCommand = ec }(ExecCommand)
+	ExecCommand = mockSnmptranslate
+	netsnmpCache = nil
+
+	oid := "IF-MIB::ifPhysAddress.1"
+	mibName, oidNum, oidText, conversion, err := NetsnmpTranslate(oid)
+	require.NoError(t, err)
+	assert.Equal(t, "IF-MIB", mibName)
+	assert.Equal(t, ".1.3.6.1.2.1.2.2.1.6.1", oidNum)
+	assert.Equal(t, "ifPhysAddress.1", oidText)
+	assert.Equal(t, "hwaddr", conversion)
+
+	assert.Len(t, netsnmpCache, 1)
+	assert.Equal(t, netsnmpTranslation{
+		mibName:    mibName,
+		oidNum:     oidNum,
+		oidText:    oidText,
+		conversion: conversion,
+	}, netsnmpCache[oid])
+	netsnmpCache = nil
+}
+
+func TestNetsnmpTranslate_cached(t *testing.T) {
+	netsnmpCache = map[string]netsnmpTranslation{
+		"foo": {
+			mibName:    "a",
+			oidNum:     "b",
+			oidText:    "c",
+			conversion: "d",
+			err:        fmt.Errorf("e"),
+		},
+	}
+	mibName, oidNum, oidText, conversion, err := NetsnmpTranslate("foo")
+	assert.Equal(t, "a", mibName)
+	assert.Equal(t, "b", oidNum)
+	assert.Equal(t, "c", oidText)
+	assert.Equal(t, "d", conversion)
+	assert.Equal(t, fmt.Errorf("e"), err)
+	netsnmpCache = nil
+}
+
+func TestNetsnmpTranslate_notInstalled(t *testing.T) {
+	defer func(ec func(string, ...string) *exec.Cmd) { ExecCommand = ec }(ExecCommand)
+	ExecCommand = func(_ string, _ ...string) *exec.Cmd {
+		return exec.Command("snmptranslateExecErrNotFound")
+	}
+	netsnmpCache = nil
+
+	// numeric OIDs are returned as is
+	mibName, oidNum, oidText, _, err := NetsnmpTranslate(".1.3.6.1.2.1.1.3.0")
+	require.NoError(t, err)
+	assert.Equal(t, "", mibName)
+	assert.Equal(t, ".1.3.6.1.2.1.1.3.0", oidNum)
+	assert.Equal(t, ".1.3.6.1.2.1.1.3.0", oidText)
+
+	_, _, _, _, err = NetsnmpTranslate("IF-MIB::ifDescr")
+	assert.Error(t, err)
+	netsnmpCache = nil
+}
@@ -0,0 +1,495 @@
+// Package snmp translates the OIDs of SNMP objects to their names, either by
+// loading the MIB modules in Go or with the net-snmp tools.
+package snmp
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// smiMIB defines the OIDs of the SMI, which are used by the MIB modules even
+// if the SMI modules are not in the loaded directories.
+const smiMIB = `
+SNMPv2-SMI DEFINITIONS ::= BEGIN
+org            OBJECT IDENTIFIER ::= { iso 3 }
+dod            OBJECT IDENTIFIER ::= { org 6 }
+internet       OBJECT IDENTIFIER ::= { dod 1 }
+directory      OBJECT IDENTIFIER ::= { internet 1 }
+mgmt           OBJECT IDENTIFIER ::= { internet 2 }
+mib-2          OBJECT IDENTIFIER ::= { mgmt 1 }
+transmission   OBJECT IDENTIFIER ::= { mib-2 10 }
+experimental   OBJECT IDENTIFIER ::= { internet 3 }
+private        OBJECT IDENTIFIER ::= { internet 4 }
+enterprises    OBJECT IDENTIFIER ::= { private 1 }
+security       OBJECT IDENTIFIER ::= { internet 5 }
+snmpV2         OBJECT IDENTIFIER ::= { internet 6 }
+snmpDomains    OBJECT IDENTIFIER ::= { snmpV2 1 }
+snmpProxys     OBJECT IDENTIFIER ::= { snmpV2 2 }
+snmpModules    OBJECT IDENTIFIER ::= { snmpV2 3 }
+zeroDotZero    OBJECT IDENTIFIER ::= { 0 0 }
+END
+`
+
+// conversions of the values of the textual conventions, as done by the snmp
+// input
+var tcConversions = map[string]string{
+	"MacAddress":      "hwaddr",
+	"PhysAddress":     "hwaddr",
+	"InetAddress":     "ipaddr",
+	"InetAddressIPv4": "ipaddr",
+	"InetAddressIPv6": "ipaddr",
+}
+
+// Column is a column of a table.
+type Column struct {
+	// Name of the column object
+	Name string
+	// Oid is the numeric OID of the column
+	Oid string
+	// IsIndex is true if the column is an index of the table
+	IsIndex bool
+}
+
+// node is a node of the OID tree.
+type node struct {
+	// name of the node, empty for the nodes without name
+	name string
+	// module defining the node, empty for the root nodes
+	module string
+	// numeric OID of the node, such as ".1.3.6.1.2.1"
+	oid string
+
+	// from the OBJECT-TYPE of the node
+	conversion string
+	enums      map[int64]string
+	access     string
+	index      []string
+
+	parent   *node
+	children map[uint32]*node
+	object   *object
+	mod      *module
+}
+
+// child returns the child of the sub-identifier, creating it if needed.
+func (n *node) child(subid uint32) *node {
+	c, ok := n.children[subid]
+	if !ok {
+		c = &node{
+			oid:      n.oid + "." + strconv.FormatUint(uint64(subid), 10),
+			parent:   n,
+			children: make(map[uint32]*node),
+		}
+		n.children[subid] = c
+	}
+	return c
+}
+
+// Tree is the tree of the OIDs defined by MIB modules.
+type Tree struct {
+	root    *node
+	modules map[string]*module
+	// nodes named by each module
+	names map[string]map[string]*node
+	// first node of each name
+	byName map[string]*node
+	// first type of each name, and its module
+	types map[string]*module
+}
+
+var (
+	treesLock sync.Mutex
+	// loaded trees of each list of directories, shared by all the plugins
+	trees = make(map[string]*Tree)
+)
+
+// LoadTree loads the MIB modules of the files in the directories and their
+// sub-directories.  The trees are loaded once for each list of directories.
+// The files which cannot be parsed are skipped with a warning.
+func LoadTree(paths []string) (*Tree, error) {
+	key := strings.Join(paths, string(os.PathListSeparator))
+
+	treesLock.Lock()
+	defer treesLock.Unlock()
+
+	if t, ok := trees[key]; ok {
+		return t, nil
+	}
+
+	modules := make(map[string]*module)
+	for _, path := range paths {
+		err := filepath.Walk(path, func(filename string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if strings.HasPrefix(info.Name(), ".") && filename != path {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !info.Mode().IsRegular() {
+				return nil
+			}
+
+			mods, err := parseFile(filename)
+			if err != nil {
+				log.Printf("W! [snmp] Unable to load MIB file %s: %s", filename, err)
+				return nil
+			}
+			for _, m := range mods {
+				// the first module of a name is loaded
+				if _, ok := modules[m.name]; !ok {
+					modules[m.name] = m
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to load MIBs of %s: %s", path, err)
+		}
+	}
+
+	t, err := newTree(modules)
+	if err != nil {
+		return nil, err
+	}
+	trees[key] = t
+	return t, nil
+}
+
+func parseFile(filename string) ([]*module, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseModules(f)
+}
+
+// newTree builds the OID tree of the modules.
+func newTree(modules map[string]*module) (*Tree, error) {
+	if _, ok := modules["SNMPv2-SMI"]; !ok {
+		smi, err := parseModules(strings.NewReader(smiMIB))
+		if err != nil {
+			return nil, err
+		}
+		modules["SNMPv2-SMI"] = smi[0]
+	}
+
+	t := &Tree{
+		root:    &node{children: make(map[uint32]*node)},
+		modules: modules,
+		names:   make(map[string]map[string]*node),
+		byName:  make(map[string]*node),
+		types:   make(map[string]*module),
+	}
+	for i, name := range []string{"ccitt", "iso", "joint-iso-ccitt"} {
+		n := t.root.child(uint32(i))
+		n.name = name
+		t.byName[name] = n
+	}
+
+	// the modules are resolved in order, the first module defining an OID
+	// naming it
+	names := make([]string, 0, len(modules))
+	for name := range modules {
+		names = append(names, name)
+		t.names[name] = make(map[string]*node)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for tn := range modules[name].types {
+			if _, ok := t.types[tn]; !ok {
+				t.types[tn] = modules[name]
+			}
+		}
+	}
+
+	type pending struct {
+		m *module
+		o *object
+	}
+	var objects []pending
+	for _, name := range names {
+		for _, o := range modules[name].objects {
+			objects = append(objects, pending{modules[name], o})
+		}
+	}
+
+	// the objects are resolved once the objects of their OIDs are
+	for len(objects) > 0 {
+		var unresolved []pending
+		for _, p := range objects {
+			if !t.resolve(p.m, p.o) {
+				unresolved = append(unresolved, p)
+			}
+		}
+		if len(unresolved) == len(objects) {
+			for _, p := range unresolved {
+				log.Printf("D! [snmp] Unable to resolve the OID of %s::%s", p.m.name, p.o.name)
+			}
+			break
+		}
+		objects = unresolved
+	}
+
+	t.walk(t.root, func(n *node) {
+		if n.object != nil {
+			t.resolveObject(n)
+		}
+	})
+	return t, nil
+}
+
+// resolve adds the object to the tree, returning false if the first
+// component of its OID is not resolved yet.
+func (t *Tree) resolve(m *module, o *object) bool {
+	first := o.oid[0]
+	var n *node
+	if first.name == "" {
+		n = t.root.child(first.num)
+	} else if n = t.lookup(m, first.name); n == nil {
+		return false
+	}
+
+	for _, c := range o.oid[1:] {
+		if !c.hasNum {
+			// invalid OID, such as { a b }, which is ignored
+			return true
+		}
+		n = n.child(c.num)
+		if c.name != "" {
+			t.setName(n, m, c.name)
+		}
+	}
+
+	t.setName(n, m, o.name)
+	if n.object == nil {
+		n.object = o
+		n.mod = m
+	}
+	return true
+}
+
+// setName names the node in the module, the node keeping the first name it is
+// given.
+func (t *Tree) setName(n *node, m *module, name string) {
+	if n.name == "" {
+		n.name = name
+		n.module = m.name
+	}
+	t.names[m.name][name] = n
+	if _, ok := t.byName[name]; !ok {
+		t.byName[name] = n
+	}
+}
+
+// lookup returns the node of the name, defined or imported by the module, or
+// else defined by any module.  It returns nil if the node of the name is not
+// resolved yet.
+func (t *Tree) lookup(m *module, name string) *node {
+	if n, ok := t.names[m.name][name]; ok {
+		return n
+	}
+	if m.defined[name] {
+		return nil
+	}
+	if from, ok := m.imports[name]; ok {
+		if fm, ok := t.modules[from]; ok && fm.defined[name] {
+			return t.names[from][name]
+		}
+	}
+	return t.byName[name]
+}
+
+// lookupType returns the type of the name, defined or imported by the
+// module, or else defined by any module.
+func (t *Tree) lookupType(m *module, name string) (*syntax, *module) {
+	if s, ok := m.types[name]; ok {
+		return s, m
+	}
+	if from, ok := m.imports[name]; ok {
+		if fm, ok := t.modules[from]; ok {
+			if s, ok := fm.types[name]; ok {
+				return s, fm
+			}
+		}
+	}
+	if tm, ok := t.types[name]; ok {
+		return tm.types[name], tm
+	}
+	return nil, nil
+}
+
+// resolveObject sets the conversion, the enums and the index of the node
+// from the definition of its object.
+func (t *Tree) resolveObject(n *node) {
+	o := n.object
+	n.access = o.access
+	n.index = o.index
+	if o.augments != "" {
+		if entry := t.lookup(n.mod, o.augments); entry != nil && entry.object != nil {
+			n.index = entry.object.index
+		}
+	}
+
+	// follow the types of the syntax up to the base type, the conversion
+	// being the one of the first known textual convention
+	s, m := o.syntax, n.mod
+	for i := 0; s != nil && i < 16; i++ {
+		if n.conversion == "" {
+			n.conversion = tcConversions[s.name]
+		}
+		if s.bits {
+			break
+		}
+		if n.enums == nil && len(s.enums) > 0 {
+			n.enums = s.enums
+		}
+		s, m = t.lookupType(m, s.name)
+	}
+}
+
+func (t *Tree) walk(n *node, fn func(*node)) {
+	fn(n)
+	for _, c := range n.children {
+		t.walk(c, fn)
+	}
+}
+
+// find returns the node of the OID and the numeric suffix of the OID below
+// the node, such as the index of a table column.  The OID is numeric, such
+// as ".1.3.6.1.2.1.1.1.0", or textual, such as "SNMPv2-MIB::sysDescr.0",
+// "sysDescr.0" or ".iso.org.dod.internet".  The node is the deepest named
+// node of the OID, or the root if the OID is not found.
+func (t *Tree) find(oid string) (*node, string, error) {
+	var n *node
+	var parts []string
+	if i := strings.Index(oid, "::"); i != -1 {
+		parts = strings.Split(oid[i+2:], ".")
+		n = t.names[oid[:i]][parts[0]]
+		parts = parts[1:]
+	} else if strings.HasPrefix(oid, ".") {
+		n = t.root
+		parts = strings.Split(oid[1:], ".")
+	} else {
+		parts = strings.Split(oid, ".")
+		if _, err := strconv.ParseUint(parts[0], 10, 32); err == nil {
+			n = t.root
+		} else {
+			n = t.byName[parts[0]]
+			parts = parts[1:]
+		}
+	}
+	if n == nil {
+		return nil, "", fmt.Errorf("unknown object %s", oid)
+	}
+
+	for i, p := range parts {
+		var child *node
+		if subid, err := strconv.ParseUint(p, 10, 32); err == nil {
+			child = n.children[uint32(subid)]
+		} else {
+			for _, c := range n.children {
+				if c.name == p {
+					child = c
+					break
+				}
+			}
+			if child == nil {
+				return nil, "", fmt.Errorf("unknown object %s in %s", p, oid)
+			}
+		}
+		if child == nil {
+			for _, p := range parts[i:] {
+				if _, err := strconv.ParseUint(p, 10, 32); err != nil {
+					return nil, "", fmt.Errorf("unknown object %s in %s", p, oid)
+				}
+			}
+			n, suffix := t.named(n, "."+strings.Join(parts[i:], "."))
+			return n, suffix, nil
+		}
+		n = child
+	}
+	n, suffix := t.named(n, "")
+	return n, suffix, nil
+}
+
+// named returns the deepest named node of the node and its ancestors, with
+// the suffix of the node below it.
+func (t *Tree) named(n *node, suffix string) (*node, string) {
+	for n.name == "" && n != t.root {
+		suffix = n.oid[strings.LastIndex(n.oid, "."):] + suffix
+		n = n.parent
+	}
+	return n, suffix
+}
+
+// Translate translates the OID, returning the module and the numeric and
+// textual OIDs, such as "IF-MIB", ".1.3.6.1.2.1.2.2.1.2.1" and "ifDescr.1",
+// and the conversion of its values, "hwaddr", "ipaddr" or empty.  If the OID
+// is not defined by a module, the textual OID is the numeric one.
+func (t *Tree) Translate(oid string) (mibName string, oidNum string, oidText string, conversion string, err error) {
+	n, suffix, err := t.find(oid)
+	if err != nil {
+		return "", "", "", "", err
+	}
+	if n.module == "" {
+		return "", n.oid + suffix, n.oid + suffix, "", nil
+	}
+	return n.module, n.oid + suffix, n.name + suffix, n.conversion, nil
+}
+
+// Enums returns the named values of the object of the OID, nil if it has
+// none.
+func (t *Tree) Enums(oid string) (map[int64]string, error) {
+	n, _, err := t.find(oid)
+	if err != nil {
+		return nil, err
+	}
+	return n.enums, nil
+}
+
+// Table returns the module and the numeric and textual OIDs of the table,
+// and its accessible columns.
+func (t *Tree) Table(oid string) (mibName string, oidNum string, oidText string, columns []Column, err error) {
+	n, suffix, err := t.find(oid)
+	if err != nil {
+		return "", "", "", nil, err
+	}
+	// the entry of a table is its single child
+	entry, ok := n.children[1]
+	if suffix != "" || !ok || len(n.children) != 1 {
+		return "", "", "", nil, fmt.Errorf("%s is not a table", oid)
+	}
+
+	index := make(map[string]bool)
+	for _, name := range entry.index {
+		index[name] = true
+	}
+
+	subids := make([]int, 0, len(entry.children))
+	for subid := range entry.children {
+		subids = append(subids, int(subid))
+	}
+	sort.Ints(subids)
+	for _, subid := range subids {
+		c := entry.children[uint32(subid)]
+		if c.name == "" || c.access == "not-accessible" || c.access == "accessible-for-notify" {
+			continue
+		}
+		columns = append(columns, Column{
+			Name:    c.name,
+			Oid:     c.oid,
+			IsIndex: index[c.name],
+		})
+	}
+	return n.module, n.oid, n.name, columns, nil
+}
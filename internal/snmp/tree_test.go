@@ -0,0 +1,191 @@
+package snmp
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslate(t *testing.T) {
+	tree, err := LoadTree([]string{"testdata"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		oid        string
+		mibName    string
+		oidNum     string
+		oidText    string
+		conversion string
+	}{
+		{".1.3.6.1.2.1.2.2.1.2.1", "IF-MIB", ".1.3.6.1.2.1.2.2.1.2.1", "ifDescr.1", ""},
+		{"ifDescr", "IF-MIB", ".1.3.6.1.2.1.2.2.1.2", "ifDescr", ""},
+		{"IF-MIB::ifPhysAddress.2", "IF-MIB", ".1.3.6.1.2.1.2.2.1.6.2", "ifPhysAddress.2", "hwaddr"},
+		{"1.3.6.1.2.1.31.1.1.1.6.3", "IF-MIB", ".1.3.6.1.2.1.31.1.1.1.6.3", "ifHCInOctets.3", ""},
+		{".iso.org.dod.internet.mgmt.mib-2.system.sysUpTime.0", "SNMPv2-MIB", ".1.3.6.1.2.1.1.3.0", "sysUpTime.0", ""},
+		{".1.3.6.1.6.3.1.1.5.4", "IF-MIB", ".1.3.6.1.6.3.1.1.5.4", "linkUp", ""},
+		{".1.3.6.1.4.1.9999.0.17", "TEST-TRAP-MIB", ".1.3.6.1.4.1.9999.0.17", "testAlarm", ""},
+		{".1.3.6.1.4.1.9999.2", "TEST-TRAP-MIB", ".1.3.6.1.4.1.9999.2", "test.2", ""},
+		{".1.3.6.1.2.1", "SNMPv2-SMI", ".1.3.6.1.2.1", "mib-2", ""},
+		{".1.2.3", "", ".1.2.3", ".1.2.3", ""},
+		{".999", "", ".999", ".999", ""},
+	}
+	for _, tt := range tests {
+		mibName, oidNum, oidText, conversion, err := tree.Translate(tt.oid)
+		require.NoError(t, err, tt.oid)
+		assert.Equal(t, tt.mibName, mibName, tt.oid)
+		assert.Equal(t, tt.oidNum, oidNum, tt.oid)
+		assert.Equal(t, tt.oidText, oidText, tt.oid)
+		assert.Equal(t, tt.conversion, conversion, tt.oid)
+	}
+}
+
+func TestTranslate_unknown(t *testing.T) {
+	tree, err := LoadTree([]string{"testdata"})
+	require.NoError(t, err)
+
+	for _, oid := range []string{"ifFoo", "IF-MIB::ifFoo", "FOO-MIB::ifDescr", ".1.3.6.foo", "ifDescr.foo"} {
+		_, _, _, _, err := tree.Translate(oid)
+		assert.Error(t, err, oid)
+	}
+}
+
+func TestEnums(t *testing.T) {
+	tree, err := LoadTree([]string{"testdata"})
+	require.NoError(t, err)
+
+	enums, err := tree.Enums("IF-MIB::ifAdminStatus.1")
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]string{1: "up", 2: "down", 3: "testing"}, enums)
+
+	// enums of a textual convention
+	enums, err = tree.Enums(".1.3.6.1.2.1.31.1.1.1.16")
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]string{1: "true", 2: "false"}, enums)
+
+	enums, err = tree.Enums("ifDescr")
+	require.NoError(t, err)
+	assert.Nil(t, enums)
+}
+
+func TestTable(t *testing.T) {
+	tree, err := LoadTree([]string{"testdata"})
+	require.NoError(t, err)
+
+	mibName, oidNum, oidText, columns, err := tree.Table("IF-MIB::ifTable")
+	require.NoError(t, err)
+	assert.Equal(t, "IF-MIB", mibName)
+	assert.Equal(t, ".1.3.6.1.2.1.2.2", oidNum)
+	assert.Equal(t, "ifTable", oidText)
+	assert.Equal(t, []Column{
+		{Name: "ifIndex", Oid: ".1.3.6.1.2.1.2.2.1.1", IsIndex: true},
+		{Name: "ifDescr", Oid: ".1.3.6.1.2.1.2.2.1.2"},
+		{Name: "ifType", Oid: ".1.3.6.1.2.1.2.2.1.3"},
+		{Name: "ifMtu", Oid: ".1.3.6.1.2.1.2.2.1.4"},
+		{Name: "ifPhysAddress", Oid: ".1.3.6.1.2.1.2.2.1.6"},
+		{Name: "ifAdminStatus", Oid: ".1.3.6.1.2.1.2.2.1.7"},
+		{Name: "ifOperStatus", Oid: ".1.3.6.1.2.1.2.2.1.8"},
+		{Name: "ifInOctets", Oid: ".1.3.6.1.2.1.2.2.1.10"},
+		{Name: "ifSpecific", Oid: ".1.3.6.1.2.1.2.2.1.22"},
+	}, columns)
+
+	// table augmenting ifTable
+	_, _, _, columns, err = tree.Table(".1.3.6.1.2.1.31.1.1")
+	require.NoError(t, err)
+	assert.Equal(t, []Column{
+		{Name: "ifName", Oid: ".1.3.6.1.2.1.31.1.1.1.1"},
+		{Name: "ifHCInOctets", Oid: ".1.3.6.1.2.1.31.1.1.1.6"},
+		{Name: "ifPromiscuousMode", Oid: ".1.3.6.1.2.1.31.1.1.1.16"},
+		{Name: "ifCounterDiscontinuityTime", Oid: ".1.3.6.1.2.1.31.1.1.1.19"},
+	}, columns)
+
+	for _, oid := range []string{"ifEntry", "ifDescr", "ifTable.1", "ifFoo"} {
+		_, _, _, _, err := tree.Table(oid)
+		assert.Error(t, err, oid)
+	}
+}
+
+func TestLoadTree_cache(t *testing.T) {
+	tree1, err := LoadTree([]string{"testdata"})
+	require.NoError(t, err)
+	tree2, err := LoadTree([]string{"testdata"})
+	require.NoError(t, err)
+	assert.True(t, tree1 == tree2)
+}
+
+func TestLoadTree_invalidFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mibs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "BAD-MIB.txt"), []byte("BAD-MIB DEFINITIONS ::= BEGIN\nbad OBJECT IDENTIFIER ::= {"), 0644)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, "GOOD-MIB.txt"), []byte("GOOD-MIB DEFINITIONS ::= BEGIN\ngood OBJECT IDENTIFIER ::= { enterprises 1 }\nEND\n"), 0644)
+	require.NoError(t, err)
+
+	tree, err := LoadTree([]string{dir})
+	require.NoError(t, err)
+
+	mibName, _, oidText, _, err := tree.Translate(".1.3.6.1.4.1.1")
+	require.NoError(t, err)
+	assert.Equal(t, "GOOD-MIB", mibName)
+	assert.Equal(t, "good", oidText)
+
+	_, _, _, _, err = tree.Translate("bad")
+	assert.Error(t, err)
+}
+
+func TestLoadTree_missingDirectory(t *testing.T) {
+	_, err := LoadTree([]string{"testdata/missing"})
+	assert.Error(t, err)
+}
+
+func TestParseModules(t *testing.T) {
+	mib := `
+-- comment
+A-MIB DEFINITIONS ::= BEGIN
+IMPORTS b FROM B-MIB;
+a OBJECT IDENTIFIER ::= { b 1 } -- comment -- c OBJECT IDENTIFIER ::= { a 2 }
+d ::= { iso org(3) 6 }
+Status ::= INTEGER { on(1), off(-1) }
+END
+B-MIB DEFINITIONS ::= BEGIN
+b OBJECT IDENTIFIER ::= { iso 2 }
+END
+`
+	modules, err := parseModules(strings.NewReader(mib))
+	require.NoError(t, err)
+	require.Len(t, modules, 2)
+
+	m := modules[0]
+	assert.Equal(t, "A-MIB", m.name)
+	assert.Equal(t, map[string]string{"b": "B-MIB"}, m.imports)
+	require.Len(t, m.objects, 3)
+	assert.Equal(t, "a", m.objects[0].name)
+	assert.Equal(t, []oidComponent{{name: "b"}, {num: 1, hasNum: true}}, m.objects[0].oid)
+	assert.Equal(t, "c", m.objects[1].name)
+	assert.Equal(t, "d", m.objects[2].name)
+	assert.Equal(t, []oidComponent{{name: "iso"}, {name: "org", num: 3, hasNum: true}, {num: 6, hasNum: true}}, m.objects[2].oid)
+	assert.Equal(t, map[int64]string{1: "on", -1: "off"}, m.types["Status"].enums)
+
+	assert.Equal(t, "B-MIB", modules[1].name)
+}
+
+func TestParseModules_errors(t *testing.T) {
+	for _, mib := range []string{
+		"A-MIB DEFINITIONS BEGIN END",
+		"A-MIB DEFINITIONS ::= BEGIN",
+		"A-MIB DEFINITIONS ::= BEGIN a OBJECT IDENTIFIER ::= { } END",
+		"A-MIB DEFINITIONS ::= BEGIN a OBJECT IDENTIFIER ::= { b 99999999999 } END",
+		"A-MIB DEFINITIONS ::= BEGIN a OBJECT-TYPE SYNTAX INTEGER { up(x) } ::= { b 1 } END",
+		"A-MIB DEFINITIONS ::= BEGIN a OBJECT-TYPE DESCRIPTION \"unterminated ::= { b 1 } END",
+		"{ }",
+	} {
+		_, err := parseModules(strings.NewReader(mib))
+		assert.Error(t, err, mib)
+	}
+}
@@ -3,6 +3,7 @@ package exec
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
@@ -31,6 +32,14 @@ const sampleConfig = `
   ## Timeout for each command to complete.
   timeout = "5s"
 
+  ## Environment variables of the commands, in addition to the environment
+  ## of telegraf.
+  # environment = ["LD_LIBRARY_PATH=/opt/mycollector/lib"]
+
+  ## Working directory of the commands, defaults to the working directory of
+  ## telegraf.
+  # working_dir = "/opt/mycollector"
+
   ## measurement name suffix (for separating different commands)
   name_suffix = "_mycollector"
 
@@ -48,6 +57,9 @@ type Exec struct {
 	Command  string
 	Timeout  internal.Duration
 
+	Environment []string `toml:"environment"`
+	WorkingDir  string   `toml:"working_dir"`
+
 	parser parsers.Parser
 
 	runner Runner
@@ -97,6 +109,10 @@ func (c CommandRunner) Run(
 	}
 
 	cmd := exec.Command(split_cmd[0], split_cmd[1:]...)
+	cmd.Dir = e.WorkingDir
+	if len(e.Environment) > 0 {
+		cmd.Env = append(os.Environ(), e.Environment...)
+	}
 
 	var (
 		out    bytes.Buffer
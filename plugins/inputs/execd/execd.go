@@ -0,0 +1,293 @@
+package execd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/parsers"
+)
+
+const sampleConfig = `
+  ## Program to run as a daemon, writing metrics to stdout.
+  command = "/usr/bin/mycollector --foo=bar"
+
+  ## Environment variables of the program, in addition to the environment
+  ## of telegraf.
+  # environment = ["LD_LIBRARY_PATH=/opt/mycollector/lib"]
+
+  ## Working directory of the program, defaults to the working directory of
+  ## telegraf.
+  # working_dir = "/opt/mycollector"
+
+  ## How the program is signaled on each interval:
+  ##   "none"    : not signaled, the program writes metrics on its own.
+  ##   "STDIN"   : a newline is written to its stdin.
+  ##   "SIGHUP", "SIGUSR1" or "SIGUSR2" : the signal is sent, not on Windows.
+  # signal = "none"
+
+  ## Delay before the program is restarted after it exits.
+  # restart_delay = "10s"
+
+  ## Data format to consume, each line of output is parsed separately.
+  ## Each data format has its own unique set of configuration options, read
+  ## more about them here:
+  ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_INPUT.md
+  data_format = "influx"
+`
+
+const (
+	signalNone  = "none"
+	signalStdin = "STDIN"
+
+	// time given to the program to exit once stopped before it is killed
+	stopTimeout = 5 * time.Second
+)
+
+type Execd struct {
+	Command      string            `toml:"command"`
+	Environment  []string          `toml:"environment"`
+	WorkingDir   string            `toml:"working_dir"`
+	Signal       string            `toml:"signal"`
+	RestartDelay internal.Duration `toml:"restart_delay"`
+
+	acc    telegraf.Accumulator
+	parser parsers.Parser
+	args   []string
+
+	// running program, nil between restarts
+	mu      sync.Mutex
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	stopped bool
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func (e *Execd) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *Execd) Description() string {
+	return "Run a program as a daemon and read the metrics it writes to stdout"
+}
+
+func (e *Execd) SetParser(parser parsers.Parser) {
+	e.parser = parser
+}
+
+// Start runs the program, restarting it when it exits until stopped.
+func (e *Execd) Start(acc telegraf.Accumulator) error {
+	args, err := shellquote.Split(e.Command)
+	if err != nil || len(args) == 0 {
+		return fmt.Errorf("unable to parse command %q: %v", e.Command, err)
+	}
+	if e.Signal == "" {
+		e.Signal = signalNone
+	}
+	if _, ok := signals[e.Signal]; !ok && e.Signal != signalNone && e.Signal != signalStdin {
+		return fmt.Errorf("unsupported signal %q", e.Signal)
+	}
+
+	e.acc = acc
+	e.args = args
+	e.stopped = false
+	e.done = make(chan struct{})
+
+	e.wg.Add(1)
+	go e.run()
+	return nil
+}
+
+// Gather signals the program to write its metrics.
+func (e *Execd) Gather(_ telegraf.Accumulator) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.cmd == nil {
+		return nil
+	}
+
+	switch e.Signal {
+	case signalNone:
+	case signalStdin:
+		if _, err := io.WriteString(e.stdin, "\n"); err != nil {
+			return fmt.Errorf("error writing to stdin of %s: %s", e.args[0], err)
+		}
+	default:
+		if err := e.cmd.Process.Signal(signals[e.Signal]); err != nil {
+			return fmt.Errorf("error signaling %s: %s", e.args[0], err)
+		}
+	}
+	return nil
+}
+
+// Stop closes the stdin of the program and terminates it, killing it if it
+// does not exit in time.
+func (e *Execd) Stop() {
+	if e.done == nil {
+		return
+	}
+
+	e.mu.Lock()
+	e.stopped = true
+	close(e.done)
+	if e.cmd != nil {
+		e.stdin.Close()
+		terminate(e.cmd.Process)
+	}
+	e.mu.Unlock()
+
+	exited := make(chan struct{})
+	go func() {
+		e.wg.Wait()
+		close(exited)
+	}()
+
+	select {
+	case <-exited:
+	case <-time.After(stopTimeout):
+		e.mu.Lock()
+		if e.cmd != nil {
+			kill(e.cmd.Process)
+		}
+		e.mu.Unlock()
+		<-exited
+	}
+}
+
+// run runs the program until stopped, restarting it after the restart delay
+// when it exits.
+func (e *Execd) run() {
+	defer e.wg.Done()
+
+	for {
+		err := e.cmdRun()
+
+		select {
+		case <-e.done:
+			return
+		default:
+		}
+
+		if err != nil {
+			log.Printf("E! [inputs.execd] Process %s exited: %s", e.args[0], err)
+		} else {
+			log.Printf("E! [inputs.execd] Process %s exited", e.args[0])
+		}
+		log.Printf("I! [inputs.execd] Restarting in %s", e.RestartDelay.Duration)
+
+		select {
+		case <-e.done:
+			return
+		case <-time.After(e.RestartDelay.Duration):
+		}
+	}
+}
+
+// cmdRun starts the program and reads its output until it exits.
+func (e *Execd) cmdRun() error {
+	cmd := exec.Command(e.args[0], e.args[1:]...)
+	cmd.Dir = e.WorkingDir
+	if len(e.Environment) > 0 {
+		cmd.Env = append(os.Environ(), e.Environment...)
+	}
+	setProcessGroup(cmd)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	if e.stopped {
+		e.mu.Unlock()
+		return nil
+	}
+	if err := cmd.Start(); err != nil {
+		e.mu.Unlock()
+		return err
+	}
+	e.cmd = cmd
+	e.stdin = stdin
+	e.mu.Unlock()
+
+	// the output must be read before waiting for the program
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		e.readStdout(stdout)
+	}()
+	go func() {
+		defer wg.Done()
+		e.readStderr(stderr)
+	}()
+	wg.Wait()
+
+	err = cmd.Wait()
+
+	e.mu.Lock()
+	e.cmd = nil
+	e.stdin = nil
+	e.mu.Unlock()
+
+	return err
+}
+
+// readStdout parses each line of output as metrics.
+func (e *Execd) readStdout(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		metrics, err := e.parser.Parse(scanner.Bytes())
+		if err != nil {
+			e.acc.AddError(fmt.Errorf("parse error: %s", err))
+			continue
+		}
+		for _, metric := range metrics {
+			e.acc.AddFields(metric.Name(), metric.Fields(), metric.Tags(), metric.Time())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		e.acc.AddError(fmt.Errorf("error reading stdout: %s", err))
+	}
+}
+
+// readStderr forwards each line of the error output to the log.
+func (e *Execd) readStderr(r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		log.Printf("E! [inputs.execd] stderr: %q", scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		e.acc.AddError(fmt.Errorf("error reading stderr: %s", err))
+	}
+}
+
+func init() {
+	inputs.Add("execd", func() telegraf.Input {
+		return &Execd{
+			Signal:       signalNone,
+			RestartDelay: internal.Duration{Duration: 10 * time.Second},
+		}
+	})
+}
@@ -0,0 +1,32 @@
+// +build !windows
+
+package execd
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// signals that can be sent to the program on each interval
+var signals = map[string]os.Signal{
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGUSR1": syscall.SIGUSR1,
+	"SIGUSR2": syscall.SIGUSR2,
+}
+
+// setProcessGroup runs the program in its own process group, so that its
+// children are terminated along with it.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// terminate asks the process group of the program to exit.
+func terminate(p *os.Process) {
+	syscall.Kill(-p.Pid, syscall.SIGTERM)
+}
+
+// kill kills the process group of the program.
+func kill(p *os.Process) {
+	syscall.Kill(-p.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,106 @@
+// +build !windows
+
+package execd
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestExecd(command string) *Execd {
+	parser, _ := parsers.NewInfluxParser()
+	e := &Execd{
+		Command:      command,
+		RestartDelay: internal.Duration{Duration: 10 * time.Millisecond},
+	}
+	e.SetParser(parser)
+	return e
+}
+
+func TestExecdStdin(t *testing.T) {
+	e := newTestExecd(`sh -c 'while read line; do echo "test,dir=$(pwd) value=$VALUE"; done'`)
+	e.Signal = "STDIN"
+	e.Environment = []string{"VALUE=42"}
+	e.WorkingDir = "/"
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, e.Start(acc))
+	defer e.Stop()
+
+	// signal until the program runs and answers
+	for i := 0; i < 200 && acc.NMetrics() == 0; i++ {
+		require.NoError(t, e.Gather(acc))
+		time.Sleep(10 * time.Millisecond)
+	}
+	acc.Wait(1)
+
+	acc.AssertContainsTaggedFields(t, "test",
+		map[string]interface{}{"value": float64(42)},
+		map[string]string{"dir": "/"})
+}
+
+func TestExecdSignal(t *testing.T) {
+	e := newTestExecd(`sh -c 'trap "echo test value=1" USR1; while true; do sleep 0.01; done'`)
+	e.Signal = "SIGUSR1"
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, e.Start(acc))
+	defer e.Stop()
+
+	for i := 0; i < 200 && acc.NMetrics() == 0; i++ {
+		require.NoError(t, e.Gather(acc))
+		time.Sleep(20 * time.Millisecond)
+	}
+	acc.Wait(1)
+	acc.AssertContainsFields(t, "test", map[string]interface{}{"value": float64(1)})
+}
+
+func TestExecdRestart(t *testing.T) {
+	e := newTestExecd(`sh -c 'echo test value=1; echo error >&2; exit 1'`)
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, e.Start(acc))
+	defer e.Stop()
+
+	// the program is restarted each time it exits
+	acc.Wait(3)
+	acc.AssertContainsFields(t, "test", map[string]interface{}{"value": float64(1)})
+}
+
+func TestExecdParseError(t *testing.T) {
+	e := newTestExecd(`sh -c 'echo not metrics; sleep 10'`)
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, e.Start(acc))
+
+	acc.WaitError(1)
+	assert.Contains(t, acc.Errors[0].Error(), "parse error")
+
+	// the program is terminated when stopped
+	start := time.Now()
+	e.Stop()
+	assert.True(t, time.Since(start) < stopTimeout)
+}
+
+func TestExecdStopBeforeStart(t *testing.T) {
+	e := newTestExecd("true")
+	e.Stop()
+}
+
+func TestExecdInvalidConfig(t *testing.T) {
+	for _, e := range []*Execd{
+		newTestExecd(""),
+		newTestExecd(`sh -c 'unterminated`),
+		{Command: os.Args[0], Signal: "SIGKILL"},
+	} {
+		acc := &testutil.Accumulator{}
+		assert.Error(t, e.Start(acc), e.Command)
+	}
+}
@@ -0,0 +1,24 @@
+// +build windows
+
+package execd
+
+import (
+	"os"
+	"os/exec"
+)
+
+// signals cannot be sent to processes on Windows, the program can only be
+// signaled through stdin.
+var signals = map[string]os.Signal{}
+
+func setProcessGroup(cmd *exec.Cmd) {
+}
+
+// terminate asks the process to exit.  It cannot be signaled on Windows, so
+// it is expected to exit once its stdin is closed, or else killed.
+func terminate(p *os.Process) {
+}
+
+func kill(p *os.Process) {
+	p.Kill()
+}
@@ -5,18 +5,22 @@ import (
 	"compress/gzip"
 	"crypto/subtle"
 	"crypto/tls"
-	"crypto/x509"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
 	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/golang/snappy"
+
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/parsers"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/selfstat"
 )
@@ -42,11 +46,16 @@ type HTTPListener struct {
 	Port           int
 
 	TlsAllowedCacerts []string
+	TlsAllowedCns     []string
 	TlsCert           string
 	TlsKey            string
 
 	BasicUsername string
 	BasicPassword string
+	Token         string `toml:"token"`
+
+	QueryParamTags []string          `toml:"query_param_tags"`
+	HTTPHeaderTags map[string]string `toml:"http_header_tags"`
 
 	mu sync.Mutex
 	wg sync.WaitGroup
@@ -54,8 +63,10 @@ type HTTPListener struct {
 	listener net.Listener
 
 	parser influx.InfluxParser
-	acc    telegraf.Accumulator
-	pool   *pool
+	// parser of the data format, nil for line protocol
+	dataParser parsers.Parser
+	acc        telegraf.Accumulator
+	pool       *pool
 
 	BytesRecv       selfstat.Stat
 	RequestsServed  selfstat.Stat
@@ -92,6 +103,10 @@ const sampleConfig = `
   ## enable mutually authenticated TLS connections
   tls_allowed_cacerts = ["/etc/telegraf/clientca.pem"]
 
+  ## Optional list of common names allowed in client certificates, requires
+  ## tls_allowed_cacerts.
+  # tls_allowed_cns = ["telegraf"]
+
   ## Add service certificate and key
   tls_cert = "/etc/telegraf/cert.pem"
   tls_key = "/etc/telegraf/key.pem"
@@ -100,6 +115,23 @@ const sampleConfig = `
   ## You probably want to make sure you have TLS configured above for this.
   # basic_username = "foobar"
   # basic_password = "barfoo"
+
+  ## Optional token to accept in the Authorization header, as
+  ## "Bearer <token>" or "Token <token>" like the InfluxDB 2.x API.
+  # token = "secret-token"
+
+  ## Query parameters of the requests to add as tags to the metrics.
+  # query_param_tags = ["db"]
+
+  ## Data format to consume.
+  ## Each data format has its own unique set of configuration options, read
+  ## more about them here:
+  ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_INPUT.md
+  data_format = "influx"
+
+  ## HTTP headers of the requests to add as tags, mapped to the tag names.
+  # [inputs.http_listener.http_header_tags]
+  #   X-Source = "source"
 `
 
 func (h *HTTPListener) SampleConfig() string {
@@ -110,6 +142,16 @@ func (h *HTTPListener) Description() string {
 	return "Influx HTTP write listener"
 }
 
+// SetParser sets the parser of the request bodies, line protocol is parsed
+// as it is read.
+func (h *HTTPListener) SetParser(parser parsers.Parser) {
+	if _, ok := parser.(*influx.InfluxParser); ok {
+		h.dataParser = nil
+		return
+	}
+	h.dataParser = parser
+}
+
 func (h *HTTPListener) Gather(_ telegraf.Accumulator) error {
 	h.BuffersCreated.Set(h.pool.ncreated())
 	return nil
@@ -153,7 +195,11 @@ func (h *HTTPListener) Start(acc telegraf.Accumulator) error {
 	h.acc = acc
 	h.pool = NewPool(200, h.MaxLineSize)
 
-	tlsConf := h.getTLSConfig()
+	tlsConf, err := internal.GetServerTLSConfig(h.TlsCert, h.TlsKey,
+		h.TlsAllowedCacerts, h.TlsAllowedCns)
+	if err != nil {
+		return err
+	}
 
 	server := &http.Server{
 		Addr:         h.ServiceAddress,
@@ -163,7 +209,6 @@ func (h *HTTPListener) Start(acc telegraf.Accumulator) error {
 		TLSConfig:    tlsConf,
 	}
 
-	var err error
 	var listener net.Listener
 	if tlsConf != nil {
 		listener, err = tls.Listen("tcp", h.ServiceAddress, tlsConf)
@@ -206,6 +251,10 @@ func (h *HTTPListener) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 		h.WritesRecv.Incr(1)
 		defer h.WritesServed.Incr(1)
 		h.AuthenticateIfSet(h.serveWrite, res, req)
+	case "/api/v2/write":
+		h.WritesRecv.Incr(1)
+		defer h.WritesServed.Incr(1)
+		h.AuthenticateIfSet(h.serveWriteV2, res, req)
 	case "/query":
 		h.QueriesRecv.Incr(1)
 		defer h.QueriesServed.Incr(1)
@@ -232,21 +281,55 @@ func (h *HTTPListener) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 }
 
 func (h *HTTPListener) serveWrite(res http.ResponseWriter, req *http.Request) {
+	h.write(res, req, req.URL.Query().Get("precision"))
+}
+
+// serveWriteV2 handles writes to the InfluxDB 2.x API, whose precision is one
+// of ns (default), us, ms or s.
+func (h *HTTPListener) serveWriteV2(res http.ResponseWriter, req *http.Request) {
+	precision, ok := precisionsV2[req.URL.Query().Get("precision")]
+	if !ok {
+		log.Printf("E! http_listener received invalid precision %q", req.URL.Query().Get("precision"))
+		badRequest(res)
+		return
+	}
+	h.write(res, req, precision)
+}
+
+// precisionsV2 maps the precisions of the InfluxDB 2.x API to those of the
+// line protocol parser.
+var precisionsV2 = map[string]string{
+	"":   "n",
+	"ns": "n",
+	"us": "u",
+	"ms": "ms",
+	"s":  "s",
+}
+
+func (h *HTTPListener) write(res http.ResponseWriter, req *http.Request, precision string) {
 	// Check that the content length is not too large for us to handle.
 	if req.ContentLength > h.MaxBodySize {
 		tooLarge(res)
 		return
 	}
 	now := time.Now()
+	tags := h.requestTags(req)
 
-	precision := req.URL.Query().Get("precision")
-
-	// Handle gzip request bodies
+	// Handle gzip and snappy request bodies
 	body := req.Body
-	if req.Header.Get("Content-Encoding") == "gzip" {
+	switch req.Header.Get("Content-Encoding") {
+	case "gzip":
 		var err error
 		body, err = gzip.NewReader(req.Body)
+		if err != nil {
+			log.Println("E! " + err.Error())
+			badRequest(res)
+			return
+		}
 		defer body.Close()
+	case "snappy":
+		var err error
+		body, err = h.snappyReader(req.Body)
 		if err != nil {
 			log.Println("E! " + err.Error())
 			badRequest(res)
@@ -255,6 +338,11 @@ func (h *HTTPListener) serveWrite(res http.ResponseWriter, req *http.Request) {
 	}
 	body = http.MaxBytesReader(res, body, h.MaxBodySize)
 
+	if h.dataParser != nil {
+		h.parseBody(res, body, tags)
+		return
+	}
+
 	var return400 bool
 	var hangingBytes bool
 	buf := h.pool.get()
@@ -297,7 +385,7 @@ func (h *HTTPListener) serveWrite(res http.ResponseWriter, req *http.Request) {
 
 		if err == io.ErrUnexpectedEOF {
 			// finished reading the request body
-			if err := h.parse(buf[:n+bufStart], now, precision); err != nil {
+			if err := h.parse(buf[:n+bufStart], now, precision, tags); err != nil {
 				log.Println("E! " + err.Error())
 				return400 = true
 			}
@@ -322,7 +410,7 @@ func (h *HTTPListener) serveWrite(res http.ResponseWriter, req *http.Request) {
 			bufStart = 0
 			continue
 		}
-		if err := h.parse(buf[:i+1], now, precision); err != nil {
+		if err := h.parse(buf[:i+1], now, precision, tags); err != nil {
 			log.Println("E! " + err.Error())
 			return400 = true
 		}
@@ -335,14 +423,85 @@ func (h *HTTPListener) serveWrite(res http.ResponseWriter, req *http.Request) {
 	}
 }
 
-func (h *HTTPListener) parse(b []byte, t time.Time, precision string) error {
+func (h *HTTPListener) parse(b []byte, t time.Time, precision string, tags map[string]string) error {
 	metrics, err := h.parser.ParseWithDefaultTimePrecision(b, t, precision)
 
+	h.addMetrics(metrics, tags)
+
+	return err
+}
+
+// parseBody parses the whole request body with the parser of the data format.
+func (h *HTTPListener) parseBody(res http.ResponseWriter, body io.Reader, tags map[string]string) {
+	b, err := ioutil.ReadAll(body)
+	h.BytesRecv.Incr(int64(len(b)))
+	if err != nil {
+		log.Println("E! " + err.Error())
+		if strings.Contains(err.Error(), "request body too large") {
+			tooLarge(res)
+		} else {
+			badRequest(res)
+		}
+		return
+	}
+
+	metrics, err := h.dataParser.Parse(b)
+	if err != nil {
+		log.Println("E! " + err.Error())
+		badRequest(res)
+		return
+	}
+	h.addMetrics(metrics, tags)
+	res.WriteHeader(http.StatusNoContent)
+}
+
+func (h *HTTPListener) addMetrics(metrics []telegraf.Metric, tags map[string]string) {
 	for _, m := range metrics {
-		h.acc.AddFields(m.Name(), m.Fields(), m.Tags(), m.Time())
+		mTags := m.Tags()
+		for k, v := range tags {
+			mTags[k] = v
+		}
+		h.acc.AddFields(m.Name(), m.Fields(), mTags, m.Time())
 	}
+}
 
-	return err
+// requestTags returns the tags of the query parameters and headers of the
+// request.
+func (h *HTTPListener) requestTags(req *http.Request) map[string]string {
+	tags := make(map[string]string)
+	query := req.URL.Query()
+	for _, param := range h.QueryParamTags {
+		if v := query.Get(param); v != "" {
+			tags[param] = v
+		}
+	}
+	for header, tag := range h.HTTPHeaderTags {
+		if v := req.Header.Get(header); v != "" {
+			tags[tag] = v
+		}
+	}
+	return tags
+}
+
+// snappyReader decompresses a body in the snappy block format, as sent by
+// Prometheus remote write.
+func (h *HTTPListener) snappyReader(r io.Reader) (io.ReadCloser, error) {
+	b, err := ioutil.ReadAll(io.LimitReader(r, h.MaxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	n, err := snappy.DecodedLen(b)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > h.MaxBodySize || int64(n) > h.MaxBodySize {
+		return nil, fmt.Errorf("http: request body too large")
+	}
+	b, err = snappy.Decode(nil, b)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(bytes.NewReader(b)), nil
 }
 
 func tooLarge(res http.ResponseWriter) {
@@ -359,53 +518,42 @@ func badRequest(res http.ResponseWriter) {
 	res.Write([]byte(`{"error":"http: bad request"}`))
 }
 
-func (h *HTTPListener) getTLSConfig() *tls.Config {
-	tlsConf := &tls.Config{
-		InsecureSkipVerify: false,
-		Renegotiation:      tls.RenegotiateNever,
+func (h *HTTPListener) AuthenticateIfSet(handler http.HandlerFunc, res http.ResponseWriter, req *http.Request) {
+	if !h.authorized(req) {
+		h.AuthFailures.Incr(1)
+		http.Error(res, "Unauthorized.", http.StatusUnauthorized)
+		return
 	}
+	handler(res, req)
+}
 
-	if len(h.TlsCert) == 0 || len(h.TlsKey) == 0 {
-		return nil
+// authorized returns whether the request has the token or the basic
+// authentication credentials, if either is set.
+func (h *HTTPListener) authorized(req *http.Request) bool {
+	basicAuth := h.BasicUsername != "" && h.BasicPassword != ""
+	if !basicAuth && h.Token == "" {
+		return true
 	}
 
-	cert, err := tls.LoadX509KeyPair(h.TlsCert, h.TlsKey)
-	if err != nil {
-		return nil
-	}
-	tlsConf.Certificates = []tls.Certificate{cert}
-
-	if h.TlsAllowedCacerts != nil {
-		tlsConf.ClientAuth = tls.RequireAndVerifyClientCert
-		clientPool := x509.NewCertPool()
-		for _, ca := range h.TlsAllowedCacerts {
-			c, err := ioutil.ReadFile(ca)
-			if err != nil {
-				continue
+	if h.Token != "" {
+		auth := req.Header.Get("Authorization")
+		for _, scheme := range []string{"Bearer ", "Token "} {
+			if strings.HasPrefix(auth, scheme) &&
+				subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, scheme)), []byte(h.Token)) == 1 {
+				return true
 			}
-			clientPool.AppendCertsFromPEM(c)
 		}
-		tlsConf.ClientCAs = clientPool
 	}
 
-	return tlsConf
-}
-
-func (h *HTTPListener) AuthenticateIfSet(handler http.HandlerFunc, res http.ResponseWriter, req *http.Request) {
-	if h.BasicUsername != "" && h.BasicPassword != "" {
+	if basicAuth {
 		reqUsername, reqPassword, ok := req.BasicAuth()
-		if !ok ||
-			subtle.ConstantTimeCompare([]byte(reqUsername), []byte(h.BasicUsername)) != 1 ||
-			subtle.ConstantTimeCompare([]byte(reqPassword), []byte(h.BasicPassword)) != 1 {
-
-			h.AuthFailures.Incr(1)
-			http.Error(res, "Unauthorized.", http.StatusUnauthorized)
-			return
+		if ok &&
+			subtle.ConstantTimeCompare([]byte(reqUsername), []byte(h.BasicUsername)) == 1 &&
+			subtle.ConstantTimeCompare([]byte(reqPassword), []byte(h.BasicPassword)) == 1 {
+			return true
 		}
-		handler(res, req)
-	} else {
-		handler(res, req)
 	}
+	return false
 }
 
 func init() {
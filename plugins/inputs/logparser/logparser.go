@@ -8,11 +8,13 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/influxdata/tail"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal/globpath"
+	"github.com/influxdata/telegraf/internal/multiline"
 	"github.com/influxdata/telegraf/plugins/inputs"
 
 	// Parsers
@@ -40,6 +42,8 @@ type LogParserPlugin struct {
 	FromBeginning bool
 	WatchMethod   string
 
+	Multiline *multiline.Config `toml:"multiline"`
+
 	tailers map[string]*tail.Tail
 	lines   chan logEntry
 	done    chan struct{}
@@ -69,6 +73,24 @@ const sampleConfig = `
   ## Method used to watch for file updates.  Can be either "inotify" or "poll".
   # watch_method = "inotify"
 
+  ## Join multiple lines into one event before parsing, i.e. stack traces.
+  ## Exactly one of start_pattern or continuation_pattern must be set.  The
+  ## lines of an event are joined by newlines, use the (?s) flag in grok
+  ## patterns for "." to match them.
+  # [inputs.logparser.multiline]
+  #   ## Regular expression matching the first line of each event, other
+  #   ## lines are appended to the current event.
+  #   start_pattern = '^\d{4}-\d{2}-\d{2}'
+  #   ## Regular expression matching the lines appended to the current event,
+  #   ## other lines start a new event.
+  #   # continuation_pattern = '^\s'
+  #   ## Invert the match of the pattern.
+  #   # invert_match = false
+  #   ## Time after which the last event is parsed if no new line is read.
+  #   # timeout = "5s"
+  #   ## Maximum number of lines of an event.
+  #   # max_lines = 500
+
   ## Parse logstash-style "grok" patterns:
   ##   Telegraf built-in parsing patterns: https://goo.gl/dkay10
   [inputs.logparser.grok]
@@ -153,6 +175,12 @@ func (l *LogParserPlugin) Start(acc telegraf.Accumulator) error {
 		return fmt.Errorf("logparser input plugin: no parser defined")
 	}
 
+	if l.Multiline != nil {
+		if _, err := l.Multiline.New(); err != nil {
+			return err
+		}
+	}
+
 	// compile log parser patterns:
 	for _, parser := range l.parsers {
 		if err := parser.Compile(); err != nil {
@@ -209,9 +237,14 @@ func (l *LogParserPlugin) tailNewfiles(fromBeginning bool) error {
 				continue
 			}
 
+			var ml *multiline.Multiline
+			if l.Multiline != nil {
+				ml, _ = l.Multiline.New()
+			}
+
 			// create a goroutine for each "tailer"
 			l.wg.Add(1)
-			go l.receiver(tailer)
+			go l.receiver(tailer, ml)
 			l.tailers[file] = tailer
 		}
 	}
@@ -221,11 +254,29 @@ func (l *LogParserPlugin) tailNewfiles(fromBeginning bool) error {
 
 // receiver is launched as a goroutine to continuously watch a tailed logfile
 // for changes and send any log lines down the l.lines channel.
-func (l *LogParserPlugin) receiver(tailer *tail.Tail) {
+func (l *LogParserPlugin) receiver(tailer *tail.Tail, ml *multiline.Multiline) {
 	defer l.wg.Done()
 
-	var line *tail.Line
-	for line = range tailer.Lines {
+	// the timer flushes the last multiline event if no further line is read
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	var timeout <-chan time.Time
+
+	for {
+		var line *tail.Line
+		var ok bool
+		select {
+		case line, ok = <-tailer.Lines:
+		case <-timeout:
+			timeout = nil
+			if event, ok := ml.Flush(); ok {
+				l.send(tailer.Filename, event)
+			}
+			continue
+		}
+		if !ok {
+			break
+		}
 
 		if line.Err != nil {
 			log.Printf("E! Error tailing file %s, Error: %s\n",
@@ -236,15 +287,39 @@ func (l *LogParserPlugin) receiver(tailer *tail.Tail) {
 		// Fix up files with Windows line endings.
 		text := strings.TrimRight(line.Text, "\r")
 
-		entry := logEntry{
-			path: tailer.Filename,
-			line: text,
+		if ml == nil {
+			l.send(tailer.Filename, text)
+			continue
 		}
 
-		select {
-		case <-l.done:
-		case l.lines <- entry:
+		if event, ok := ml.AddLine(text); ok {
+			l.send(tailer.Filename, event)
 		}
+		if !timer.Stop() && timeout != nil {
+			<-timer.C
+		}
+		timer.Reset(ml.Timeout())
+		timeout = timer.C
+	}
+
+	timer.Stop()
+	if ml != nil {
+		if event, ok := ml.Flush(); ok {
+			l.send(tailer.Filename, event)
+		}
+	}
+}
+
+// send passes the line to the parser, unless stopping.
+func (l *LogParserPlugin) send(path, text string) {
+	entry := logEntry{
+		path: path,
+		line: text,
+	}
+
+	select {
+	case <-l.done:
+	case l.lines <- entry:
 	}
 }
 
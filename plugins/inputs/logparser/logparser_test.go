@@ -6,12 +6,16 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/multiline"
 	"github.com/influxdata/telegraf/testutil"
 
 	"github.com/influxdata/telegraf/plugins/inputs/logparser/grok"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestStartNoParsers(t *testing.T) {
@@ -162,6 +166,68 @@ func TestGrokParseLogFilesOneBad(t *testing.T) {
 		})
 }
 
+func TestGrokParseLogFilesMultiline(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+
+	_, err = tmpfile.WriteString("2018-01-02 first\n  at line two\n2018-01-03 second\n")
+	require.NoError(t, err)
+	require.NoError(t, tmpfile.Close())
+
+	p := &grok.Parser{
+		Patterns:       []string{"%{TEST_LOG_MULTILINE}"},
+		CustomPatterns: `TEST_LOG_MULTILINE %{NOTSPACE:date} (?s:%{GREEDYDATA:message})`,
+	}
+
+	logparser := &LogParserPlugin{
+		FromBeginning: true,
+		Files:         []string{tmpfile.Name()},
+		GrokParser:    p,
+		Multiline: &multiline.Config{
+			StartPattern: `^\d{4}-\d{2}-\d{2}`,
+			Timeout:      internal.Duration{Duration: 100 * time.Millisecond},
+		},
+	}
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, logparser.Start(&acc))
+
+	// the last event is parsed after the timeout
+	acc.Wait(2)
+	logparser.Stop()
+
+	acc.Lock()
+	defer acc.Unlock()
+	require.Len(t, acc.Metrics, 2)
+	assert.Equal(t, map[string]interface{}{
+		"date":    "2018-01-02",
+		"message": "first\n  at line two",
+	}, acc.Metrics[0].Fields)
+	assert.Equal(t, map[string]interface{}{
+		"date":    "2018-01-03",
+		"message": "second",
+	}, acc.Metrics[1].Fields)
+}
+
+func TestStartInvalidMultiline(t *testing.T) {
+	thisdir := getCurrentDir()
+	p := &grok.Parser{
+		Patterns:           []string{"%{TEST_LOG_A}"},
+		CustomPatternFiles: []string{thisdir + "grok/testdata/test-patterns"},
+	}
+
+	logparser := &LogParserPlugin{
+		FromBeginning: true,
+		Files:         []string{thisdir + "grok/testdata/test_a.log"},
+		GrokParser:    p,
+		Multiline:     &multiline.Config{},
+	}
+
+	acc := testutil.Accumulator{}
+	assert.Error(t, logparser.Start(&acc))
+}
+
 func getCurrentDir() string {
 	_, filename, _, _ := runtime.Caller(1)
 	return strings.Replace(filename, "logparser_test.go", "", 1)
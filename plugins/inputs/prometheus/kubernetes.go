@@ -0,0 +1,292 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+const (
+	// in cluster service account credentials
+	serviceAccountToken = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	serviceAccountCA    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+
+	scrapeAnnotation = "prometheus.io/scrape"
+	schemeAnnotation = "prometheus.io/scheme"
+	portAnnotation   = "prometheus.io/port"
+	pathAnnotation   = "prometheus.io/path"
+
+	defaultPodPort = "9102"
+	defaultPodPath = "/metrics"
+
+	// delay before listing the pods again after an error
+	watchRetryDelay = 5 * time.Second
+)
+
+type podMetadata struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+type pod struct {
+	Metadata podMetadata `json:"metadata"`
+	Status   struct {
+		PodIP string `json:"podIP"`
+	} `json:"status"`
+}
+
+type podList struct {
+	Metadata struct {
+		ResourceVersion string `json:"resourceVersion"`
+	} `json:"metadata"`
+	Items []pod `json:"items"`
+}
+
+type watchEvent struct {
+	Type   string          `json:"type"`
+	Object json.RawMessage `json:"object"`
+}
+
+// status is the object of ERROR watch events.
+type status struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+// kubernetesClient requests the pods of the Kubernetes API.
+type kubernetesClient struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// newKubernetesClient returns a client of the Kubernetes API at the URL, or
+// else of the cluster telegraf runs in with the credentials of its service
+// account.
+func newKubernetesClient(apiURL string) (*kubernetesClient, error) {
+	var token, ca string
+	if apiURL == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return nil, fmt.Errorf("kubernetes_url must be set when not running in a Kubernetes cluster")
+		}
+		apiURL = "https://" + net.JoinHostPort(host, port)
+
+		b, err := ioutil.ReadFile(serviceAccountToken)
+		if err != nil {
+			return nil, err
+		}
+		token = strings.TrimSpace(string(b))
+		ca = serviceAccountCA
+	}
+
+	tlsCfg, err := internal.GetTLSConfig("", "", ca, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kubernetesClient{
+		url:   strings.TrimRight(apiURL, "/"),
+		token: token,
+		client: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig:       tlsCfg,
+				ResponseHeaderTimeout: 30 * time.Second,
+			},
+		},
+	}, nil
+}
+
+// get requests the pods of the namespace, or of all namespaces if empty.
+func (k *kubernetesClient) get(ctx context.Context, namespace string, params url.Values) (*http.Response, error) {
+	path := "/api/v1/pods"
+	if namespace != "" {
+		path = "/api/v1/namespaces/" + url.PathEscape(namespace) + "/pods"
+	}
+
+	req, err := http.NewRequest("GET", k.url+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	if k.token != "" {
+		req.Header.Set("Authorization", "Bearer "+k.token)
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s returned HTTP status %s", k.url+path, resp.Status)
+	}
+	return resp, nil
+}
+
+// watchPods keeps the scraped pods up to date until the context is done.
+// The pods are listed, then watched until the resync interval elapses, when
+// they are listed again so that no change is missed.
+func (p *Prometheus) watchPods(ctx context.Context, k *kubernetesClient) {
+	defer p.wg.Done()
+
+	for {
+		version, err := p.listPods(ctx, k)
+		if err == nil {
+			err = p.watch(ctx, k, version)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Printf("E! [inputs.prometheus] Error watching Kubernetes pods: %s", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(watchRetryDelay):
+			}
+		}
+	}
+}
+
+// listPods replaces the scraped pods with the current pods and returns the
+// resource version to watch from.
+func (p *Prometheus) listPods(ctx context.Context, k *kubernetesClient) (string, error) {
+	resp, err := k.get(ctx, p.PodNamespace, url.Values{})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var list podList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return "", fmt.Errorf("error decoding pods: %s", err)
+	}
+
+	pods := make(map[string]URLAndAddress)
+	for _, pod := range list.Items {
+		if target, ok := podTarget(&pod); ok {
+			pods[podKey(&pod)] = target
+		}
+	}
+
+	p.lock.Lock()
+	p.kubernetesPods = pods
+	p.lock.Unlock()
+
+	return list.Metadata.ResourceVersion, nil
+}
+
+// watch applies the changes of the pods from the resource version until the
+// resync interval elapses.
+func (p *Prometheus) watch(ctx context.Context, k *kubernetesClient, version string) error {
+	params := url.Values{}
+	params.Set("watch", "true")
+	params.Set("resourceVersion", version)
+	params.Set("timeoutSeconds", strconv.Itoa(int(p.KubernetesResync.Duration/time.Second)))
+
+	resp, err := k.get(ctx, p.PodNamespace, params)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var event watchEvent
+		if err := dec.Decode(&event); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("error decoding watch event: %s", err)
+		}
+
+		switch event.Type {
+		case "ADDED", "MODIFIED", "DELETED":
+			var pod pod
+			if err := json.Unmarshal(event.Object, &pod); err != nil {
+				return fmt.Errorf("error decoding pod: %s", err)
+			}
+			p.updatePod(&pod, event.Type == "DELETED")
+		case "ERROR":
+			// i.e. the resource version is too old, the pods are listed again
+			var s status
+			json.Unmarshal(event.Object, &s)
+			return fmt.Errorf("watch error %d: %s", s.Code, s.Message)
+		}
+	}
+}
+
+// updatePod adds or removes the pod from the scraped pods.
+func (p *Prometheus) updatePod(pod *pod, deleted bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	key := podKey(pod)
+	if target, ok := podTarget(pod); ok && !deleted {
+		p.kubernetesPods[key] = target
+	} else {
+		delete(p.kubernetesPods, key)
+	}
+}
+
+func podKey(pod *pod) string {
+	return pod.Metadata.Namespace + "/" + pod.Metadata.Name
+}
+
+// podTarget returns the URL to scrape the pod at as set by its annotations,
+// along with its namespace, name and labels as tags.  It returns false if the
+// pod is not annotated to be scraped or has no IP address yet.
+func podTarget(pod *pod) (URLAndAddress, bool) {
+	annotations := pod.Metadata.Annotations
+	if annotations[scrapeAnnotation] != "true" || pod.Status.PodIP == "" {
+		return URLAndAddress{}, false
+	}
+
+	scheme := annotations[schemeAnnotation]
+	if scheme == "" {
+		scheme = "http"
+	}
+	port := annotations[portAnnotation]
+	if port == "" {
+		port = defaultPodPort
+	}
+	path := annotations[pathAnnotation]
+	if path == "" {
+		path = defaultPodPath
+	}
+
+	u, err := url.Parse(scheme + "://" + net.JoinHostPort(pod.Status.PodIP, port) + path)
+	if err != nil {
+		log.Printf("E! [inputs.prometheus] Invalid scrape URL of pod %s: %s", podKey(pod), err)
+		return URLAndAddress{}, false
+	}
+
+	tags := make(map[string]string, len(pod.Metadata.Labels)+2)
+	for k, v := range pod.Metadata.Labels {
+		tags[k] = v
+	}
+	tags["namespace"] = pod.Metadata.Namespace
+	tags["pod_name"] = pod.Metadata.Name
+
+	return URLAndAddress{
+		URL:         u,
+		OriginalURL: u,
+		Address:     pod.Status.PodIP,
+		Tags:        tags,
+	}, true
+}
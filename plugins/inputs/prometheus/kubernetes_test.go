@@ -0,0 +1,188 @@
+package prometheus
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPod(name string, annotations map[string]string, podIP string) *pod {
+	p := &pod{
+		Metadata: podMetadata{
+			Name:        name,
+			Namespace:   "default",
+			Labels:      map[string]string{"app": name},
+			Annotations: annotations,
+		},
+	}
+	p.Status.PodIP = podIP
+	return p
+}
+
+func TestPodTarget(t *testing.T) {
+	target, ok := podTarget(newPod("web", map[string]string{scrapeAnnotation: "true"}, "10.0.0.1"))
+	require.True(t, ok)
+	assert.Equal(t, "http://10.0.0.1:9102/metrics", target.URL.String())
+	assert.Equal(t, "10.0.0.1", target.Address)
+	assert.Equal(t, map[string]string{"app": "web", "namespace": "default", "pod_name": "web"}, target.Tags)
+
+	target, ok = podTarget(newPod("web", map[string]string{
+		scrapeAnnotation: "true",
+		schemeAnnotation: "https",
+		portAnnotation:   "8443",
+		pathAnnotation:   "/stats",
+	}, "10.0.0.1"))
+	require.True(t, ok)
+	assert.Equal(t, "https://10.0.0.1:8443/stats", target.URL.String())
+
+	_, ok = podTarget(newPod("web", nil, "10.0.0.1"))
+	assert.False(t, ok)
+
+	_, ok = podTarget(newPod("web", map[string]string{scrapeAnnotation: "false"}, "10.0.0.1"))
+	assert.False(t, ok)
+
+	_, ok = podTarget(newPod("web", map[string]string{scrapeAnnotation: "true"}, ""))
+	assert.False(t, ok)
+}
+
+func podJSON(name, port string) string {
+	return fmt.Sprintf(`{"metadata":{"name":%q,"namespace":"default","labels":{"app":%q},`+
+		`"annotations":{"prometheus.io/scrape":"true","prometheus.io/port":%q}},`+
+		`"status":{"podIP":"127.0.0.1"}}`, name, name, port)
+}
+
+func (p *Prometheus) podNames() []string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	var names []string
+	for _, target := range p.kubernetesPods {
+		names = append(names, target.Tags["pod_name"])
+	}
+	sort.Strings(names)
+	return names
+}
+
+func waitForPods(t *testing.T, p *Prometheus, expected ...string) {
+	for i := 0; i < 200; i++ {
+		if assert.ObjectsAreEqual(expected, p.podNames()) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, expected, p.podNames())
+}
+
+func TestMonitorKubernetesPods(t *testing.T) {
+	metrics := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, sampleTextFormat)
+	}))
+	defer metrics.Close()
+	u, err := url.Parse(metrics.URL)
+	require.NoError(t, err)
+
+	events := make(chan string)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces/default/pods" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if r.URL.Query().Get("watch") != "true" {
+			fmt.Fprintf(w, `{"metadata":{"resourceVersion":"10"},"items":[%s,%s]}`,
+				podJSON("web", u.Port()),
+				`{"metadata":{"name":"db","namespace":"default"},"status":{"podIP":"127.0.0.2"}}`)
+			return
+		}
+
+		assert.Equal(t, "10", r.URL.Query().Get("resourceVersion"))
+		assert.Equal(t, "300", r.URL.Query().Get("timeoutSeconds"))
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case event := <-events:
+				fmt.Fprintln(w, event)
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}))
+	defer api.Close()
+
+	p := &Prometheus{
+		MonitorPods:      true,
+		PodNamespace:     "default",
+		KubernetesURL:    api.URL,
+		KubernetesResync: internal.Duration{Duration: 5 * time.Minute},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, p.Start(&acc))
+	defer p.Stop()
+
+	waitForPods(t, p, "web")
+
+	require.NoError(t, acc.GatherError(p.Gather))
+	assert.True(t, acc.HasFloatField("go_goroutines", "gauge"))
+	assert.Equal(t, "web", acc.TagValue("go_goroutines", "pod_name"))
+	assert.Equal(t, "default", acc.TagValue("go_goroutines", "namespace"))
+	assert.Equal(t, "web", acc.TagValue("go_goroutines", "app"))
+	assert.Equal(t, "127.0.0.1", acc.TagValue("go_goroutines", "address"))
+
+	events <- `{"type":"ADDED","object":` + podJSON("api", u.Port()) + `}`
+	waitForPods(t, p, "api", "web")
+
+	events <- `{"type":"DELETED","object":` + podJSON("web", u.Port()) + `}`
+	waitForPods(t, p, "api")
+
+	// a pod no longer annotated is not scraped anymore
+	events <- `{"type":"MODIFIED","object":{"metadata":{"name":"api","namespace":"default"},"status":{"podIP":"127.0.0.1"}}}`
+	waitForPods(t, p)
+}
+
+func TestMonitorKubernetesPodsResync(t *testing.T) {
+	lists := 0
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("watch") == "true" {
+			// the watch ends, as after the resync interval
+			return
+		}
+
+		// the pod is removed while not watched, the list replaces the pods
+		lists++
+		if lists == 1 {
+			fmt.Fprintf(w, `{"metadata":{"resourceVersion":"1"},"items":[%s]}`, podJSON("web", "9100"))
+		} else {
+			fmt.Fprintf(w, `{"metadata":{"resourceVersion":"2"},"items":[]}`)
+		}
+	}))
+	defer api.Close()
+
+	p := &Prometheus{
+		MonitorPods:   true,
+		KubernetesURL: api.URL,
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, p.Start(&acc))
+	defer p.Stop()
+
+	waitForPods(t, p)
+}
+
+func TestMonitorKubernetesPodsOutsideCluster(t *testing.T) {
+	p := &Prometheus{MonitorPods: true}
+
+	var acc testutil.Accumulator
+	assert.Error(t, p.Start(&acc))
+}
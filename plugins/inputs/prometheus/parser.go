@@ -25,35 +25,9 @@ import (
 // metrics
 func Parse(buf []byte, header http.Header) ([]telegraf.Metric, error) {
 	var metrics []telegraf.Metric
-	var parser expfmt.TextParser
-	// parse even if the buffer begins with a newline
-	buf = bytes.TrimPrefix(buf, []byte("\n"))
-	// Read raw data
-	buffer := bytes.NewBuffer(buf)
-	reader := bufio.NewReader(buffer)
-
-	mediatype, params, err := mime.ParseMediaType(header.Get("Content-Type"))
-	// Prepare output
-	metricFamilies := make(map[string]*dto.MetricFamily)
-
-	if err == nil && mediatype == "application/vnd.google.protobuf" &&
-		params["encoding"] == "delimited" &&
-		params["proto"] == "io.prometheus.client.MetricFamily" {
-		for {
-			mf := &dto.MetricFamily{}
-			if _, ierr := pbutil.ReadDelimited(reader, mf); ierr != nil {
-				if ierr == io.EOF {
-					break
-				}
-				return nil, fmt.Errorf("reading metric family protocol buffer failed: %s", ierr)
-			}
-			metricFamilies[mf.GetName()] = mf
-		}
-	} else {
-		metricFamilies, err = parser.TextToMetricFamilies(reader)
-		if err != nil {
-			return nil, fmt.Errorf("reading text format failed: %s", err)
-		}
+	metricFamilies, err := parseMetricFamilies(buf, header)
+	if err != nil {
+		return nil, err
 	}
 
 	// read metrics
@@ -80,13 +54,7 @@ func Parse(buf []byte, header http.Header) ([]telegraf.Metric, error) {
 			}
 			// converting to telegraf metric
 			if len(fields) > 0 {
-				var t time.Time
-				if m.TimestampMs != nil && *m.TimestampMs > 0 {
-					t = time.Unix(0, *m.TimestampMs*1000000)
-				} else {
-					t = time.Now()
-				}
-				metric, err := metric.New(metricName, tags, fields, t, valueType(mf.GetType()))
+				metric, err := metric.New(metricName, tags, fields, sampleTime(m), valueType(mf.GetType()))
 				if err == nil {
 					metrics = append(metrics, metric)
 				}
@@ -97,6 +65,106 @@ func Parse(buf []byte, header http.Header) ([]telegraf.Metric, error) {
 	return metrics, err
 }
 
+// ParseV2 returns a Metric for each sample of a text or protocol buffer
+// representation of metrics.  The Metrics are named "prometheus" with the
+// sample value in a field named after the sample, i.e. with the "_bucket",
+// "_sum" and "_count" suffixes of histograms and summaries, and the bucket
+// upper bound or quantile in the "le" or "quantile" tag.
+func ParseV2(buf []byte, header http.Header) ([]telegraf.Metric, error) {
+	var metrics []telegraf.Metric
+	metricFamilies, err := parseMetricFamilies(buf, header)
+	if err != nil {
+		return nil, err
+	}
+
+	add := func(name string, tags map[string]string, value float64, t time.Time, vt telegraf.ValueType) {
+		if math.IsNaN(value) {
+			return
+		}
+		fields := map[string]interface{}{name: value}
+		m, err := metric.New("prometheus", tags, fields, t, vt)
+		if err == nil {
+			metrics = append(metrics, m)
+		}
+	}
+
+	for metricName, mf := range metricFamilies {
+		vt := valueType(mf.GetType())
+		for _, m := range mf.Metric {
+			t := sampleTime(m)
+			switch mf.GetType() {
+			case dto.MetricType_SUMMARY:
+				for _, q := range m.GetSummary().Quantile {
+					tags := makeLabels(m)
+					tags["quantile"] = fmt.Sprint(q.GetQuantile())
+					add(metricName, tags, q.GetValue(), t, vt)
+				}
+				add(metricName+"_sum", makeLabels(m), m.GetSummary().GetSampleSum(), t, vt)
+				add(metricName+"_count", makeLabels(m), float64(m.GetSummary().GetSampleCount()), t, vt)
+			case dto.MetricType_HISTOGRAM:
+				for _, b := range m.GetHistogram().Bucket {
+					tags := makeLabels(m)
+					tags["le"] = fmt.Sprint(b.GetUpperBound())
+					add(metricName+"_bucket", tags, float64(b.GetCumulativeCount()), t, vt)
+				}
+				add(metricName+"_sum", makeLabels(m), m.GetHistogram().GetSampleSum(), t, vt)
+				add(metricName+"_count", makeLabels(m), float64(m.GetHistogram().GetSampleCount()), t, vt)
+			default:
+				for _, value := range getNameAndValue(m) {
+					add(metricName, makeLabels(m), value.(float64), t, vt)
+				}
+			}
+		}
+	}
+
+	return metrics, nil
+}
+
+// parseMetricFamilies reads the metric families in the text or, if the
+// Content-Type header says so, the delimited protocol buffer format.
+func parseMetricFamilies(buf []byte, header http.Header) (map[string]*dto.MetricFamily, error) {
+	var parser expfmt.TextParser
+	// parse even if the buffer begins with a newline
+	buf = bytes.TrimPrefix(buf, []byte("\n"))
+	// Read raw data
+	buffer := bytes.NewBuffer(buf)
+	reader := bufio.NewReader(buffer)
+
+	mediatype, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	// Prepare output
+	metricFamilies := make(map[string]*dto.MetricFamily)
+
+	if err == nil && mediatype == "application/vnd.google.protobuf" &&
+		params["encoding"] == "delimited" &&
+		params["proto"] == "io.prometheus.client.MetricFamily" {
+		for {
+			mf := &dto.MetricFamily{}
+			if _, ierr := pbutil.ReadDelimited(reader, mf); ierr != nil {
+				if ierr == io.EOF {
+					break
+				}
+				return nil, fmt.Errorf("reading metric family protocol buffer failed: %s", ierr)
+			}
+			metricFamilies[mf.GetName()] = mf
+		}
+	} else {
+		metricFamilies, err = parser.TextToMetricFamilies(reader)
+		if err != nil {
+			return nil, fmt.Errorf("reading text format failed: %s", err)
+		}
+	}
+	return metricFamilies, nil
+}
+
+// sampleTime returns the timestamp of the metric, or the current time if it
+// has none.
+func sampleTime(m *dto.Metric) time.Time {
+	if m.TimestampMs != nil && *m.TimestampMs > 0 {
+		return time.Unix(0, *m.TimestampMs*1000000)
+	}
+	return time.Now()
+}
+
 func valueType(mt dto.MetricType) telegraf.ValueType {
 	switch mt {
 	case dto.MetricType_COUNTER:
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/influxdata/telegraf"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -163,3 +164,55 @@ func TestParseValidPrometheus(t *testing.T) {
 		metrics[0].Tags())
 
 }
+
+func TestParseV2(t *testing.T) {
+	metrics, err := ParseV2([]byte(validUniqueGauge), http.Header{})
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 1)
+	assert.Equal(t, "prometheus", metrics[0].Name())
+	assert.Equal(t, map[string]interface{}{"cadvisor_version_info": float64(1)}, metrics[0].Fields())
+	assert.Equal(t, "1.8.2", metrics[0].Tags()["dockerVersion"])
+	assert.Equal(t, telegraf.Gauge, metrics[0].Type())
+
+	// Summary: a metric per quantile with the sum and count
+	metrics, err = ParseV2([]byte(validUniqueSummary), http.Header{})
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 5)
+	fields := make(map[string]interface{})
+	for _, m := range metrics {
+		assert.Equal(t, telegraf.Summary, m.Type())
+		assert.Equal(t, "prometheus", m.Tags()["handler"])
+		for k, v := range m.Fields() {
+			if q, ok := m.Tags()["quantile"]; ok {
+				k += "/" + q
+			}
+			fields[k] = v
+		}
+	}
+	assert.Equal(t, map[string]interface{}{
+		"http_request_duration_microseconds/0.5":   552048.506,
+		"http_request_duration_microseconds/0.9":   5.876804288e+06,
+		"http_request_duration_microseconds/0.99":  5.876804288e+06,
+		"http_request_duration_microseconds_sum":   1.8909097205e+07,
+		"http_request_duration_microseconds_count": float64(9),
+	}, fields)
+
+	// Histogram: a metric per bucket with the sum and count
+	metrics, err = ParseV2([]byte(validUniqueHistogram), http.Header{})
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 10)
+	fields = make(map[string]interface{})
+	for _, m := range metrics {
+		assert.Equal(t, telegraf.Histogram, m.Type())
+		for k, v := range m.Fields() {
+			if le, ok := m.Tags()["le"]; ok {
+				k += "/" + le
+			}
+			fields[k] = v
+		}
+	}
+	assert.Equal(t, float64(1994), fields["apiserver_request_latencies_bucket/125000"])
+	assert.Equal(t, float64(2025), fields["apiserver_request_latencies_bucket/+Inf"])
+	assert.Equal(t, 1.02726334e+08, fields["apiserver_request_latencies_sum"])
+	assert.Equal(t, float64(2025), fields["apiserver_request_latencies_count"])
+}
@@ -1,6 +1,7 @@
 package prometheus
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -8,6 +9,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -25,6 +27,23 @@ type Prometheus struct {
 	// An array of Kubernetes services to scrape metrics from.
 	KubernetesServices []string
 
+	// Scrape the Kubernetes pods annotated with prometheus.io/scrape
+	MonitorPods bool `toml:"monitor_kubernetes_pods"`
+	// Namespace of the pods, all namespaces if empty
+	PodNamespace string `toml:"monitor_kubernetes_pods_namespace"`
+	// Kubernetes API URL, the cluster telegraf runs in if empty
+	KubernetesURL string `toml:"kubernetes_url"`
+	// Interval at which the pods are listed again
+	KubernetesResync internal.Duration `toml:"kubernetes_resync"`
+
+	// Version of the metrics, 2 for a metric per sample
+	MetricVersion int `toml:"metric_version"`
+
+	// Relabeling rules of the targets, before scraping
+	RelabelConfigs []*RelabelConfig `toml:"relabel_configs"`
+	// Relabeling rules of the scraped metrics
+	MetricRelabelConfigs []*RelabelConfig `toml:"metric_relabel_configs"`
+
 	// Bearer Token authorization file path
 	BearerToken string `toml:"bearer_token"`
 
@@ -39,7 +58,14 @@ type Prometheus struct {
 	// Use SSL but skip chain & host verification
 	InsecureSkipVerify bool
 
-	client *http.Client
+	client      *http.Client
+	relabelInit bool
+
+	// scraped Kubernetes pods by namespace/name
+	kubernetesPods map[string]URLAndAddress
+	lock           sync.Mutex
+	cancel         context.CancelFunc
+	wg             sync.WaitGroup
 }
 
 var sampleConfig = `
@@ -49,6 +75,28 @@ var sampleConfig = `
   ## An array of Kubernetes services to scrape metrics from.
   # kubernetes_services = ["http://my-service-dns.my-namespace:9100/metrics"]
 
+  ## Scrape the Kubernetes pods with the prometheus.io/scrape annotation set
+  ## to "true", at the port and path of the prometheus.io/port (default 9102)
+  ## and prometheus.io/path (default /metrics) annotations.  The namespace,
+  ## pod name and labels of the pods are added as tags.
+  # monitor_kubernetes_pods = true
+  ## Restrict the pods to a namespace, defaults to all namespaces.
+  # monitor_kubernetes_pods_namespace = ""
+  ## URL of the Kubernetes API, defaults to the cluster telegraf runs in
+  ## using the credentials of its service account.
+  # kubernetes_url = "http://localhost:8001"
+  ## Interval at which the pods are listed again, in addition to watching
+  ## their changes.
+  # kubernetes_resync = "5m"
+
+  ## Version of the metrics.  Version 1 adds a metric per Prometheus metric
+  ## named after it, with the histogram buckets or summary quantiles in
+  ## fields.  Version 2 adds a metric per sample named "prometheus", with a
+  ## field named after the sample and the "le" or "quantile" tag of histogram
+  ## buckets and summary quantiles, as read by prometheus_client with
+  ## metric_version = 2.
+  # metric_version = 1
+
   ## Use bearer token for authorization
   # bearer_token = /path/to/bearer/token
 
@@ -61,6 +109,25 @@ var sampleConfig = `
   # ssl_key = /path/to/keyfile
   ## Use SSL but skip chain & host verification
   # insecure_skip_verify = false
+
+  ## Relabeling rules of the targets, applied before scraping to their tags
+  ## and the __address__, __scheme__ and __metrics_path__ labels, as with
+  ## Prometheus.  Actions are replace (default), keep, drop, labelmap,
+  ## labeldrop and labelkeep.  Labels starting with "__" are removed after
+  ## relabeling.
+  # [[inputs.prometheus.relabel_configs]]
+  #   source_labels = ["__address__"]
+  #   regex = "([^:]+):.*"
+  #   target_label = "instance"
+  #   replacement = "$1"
+
+  ## Relabeling rules of the scraped metrics, applied to their tags and the
+  ## __name__ label holding the metric name, or the field name with
+  ## metric_version = 2.
+  # [[inputs.prometheus.metric_relabel_configs]]
+  #   source_labels = ["__name__"]
+  #   regex = "go_.*"
+  #   action = "drop"
 `
 
 func (p *Prometheus) SampleConfig() string {
@@ -96,6 +163,7 @@ type URLAndAddress struct {
 	OriginalURL *url.URL
 	URL         *url.URL
 	Address     string
+	Tags        map[string]string
 }
 
 func (p *Prometheus) GetAllURLs() ([]URLAndAddress, error) {
@@ -124,6 +192,13 @@ func (p *Prometheus) GetAllURLs() ([]URLAndAddress, error) {
 			allURLs = append(allURLs, URLAndAddress{URL: serviceURL, Address: resolved, OriginalURL: URL})
 		}
 	}
+
+	p.lock.Lock()
+	for _, pod := range p.kubernetesPods {
+		allURLs = append(allURLs, pod)
+	}
+	p.lock.Unlock()
+
 	return allURLs, nil
 }
 
@@ -138,6 +213,10 @@ func (p *Prometheus) Gather(acc telegraf.Accumulator) error {
 		p.client = client
 	}
 
+	if err := p.initRelabelConfigs(); err != nil {
+		return err
+	}
+
 	var wg sync.WaitGroup
 
 	allURLs, err := p.GetAllURLs()
@@ -145,6 +224,10 @@ func (p *Prometheus) Gather(acc telegraf.Accumulator) error {
 		return err
 	}
 	for _, URL := range allURLs {
+		URL, ok := relabelTarget(URL, p.RelabelConfigs)
+		if !ok {
+			continue
+		}
 		wg.Add(1)
 		go func(serviceURL URLAndAddress) {
 			defer wg.Done()
@@ -212,7 +295,12 @@ func (p *Prometheus) gatherURL(u URLAndAddress, acc telegraf.Accumulator) error
 		return fmt.Errorf("error reading body: %s", err)
 	}
 
-	metrics, err := Parse(body, resp.Header)
+	var metrics []telegraf.Metric
+	if p.MetricVersion == 2 {
+		metrics, err = ParseV2(body, resp.Header)
+	} else {
+		metrics, err = Parse(body, resp.Header)
+	}
 	if err != nil {
 		return fmt.Errorf("error reading metrics for %s: %s",
 			u.URL, err)
@@ -226,26 +314,124 @@ func (p *Prometheus) gatherURL(u URLAndAddress, acc telegraf.Accumulator) error
 		if u.Address != "" {
 			tags["address"] = u.Address
 		}
+		for k, v := range u.Tags {
+			tags[k] = v
+		}
+
+		name, fields := metric.Name(), metric.Fields()
+		if len(p.MetricRelabelConfigs) > 0 {
+			var ok bool
+			name, fields, tags, ok = p.relabelMetric(name, fields, tags)
+			if !ok {
+				continue
+			}
+		}
 
 		switch metric.Type() {
 		case telegraf.Counter:
-			acc.AddCounter(metric.Name(), metric.Fields(), tags, metric.Time())
+			acc.AddCounter(name, fields, tags, metric.Time())
 		case telegraf.Gauge:
-			acc.AddGauge(metric.Name(), metric.Fields(), tags, metric.Time())
+			acc.AddGauge(name, fields, tags, metric.Time())
 		case telegraf.Summary:
-			acc.AddSummary(metric.Name(), metric.Fields(), tags, metric.Time())
+			acc.AddSummary(name, fields, tags, metric.Time())
 		case telegraf.Histogram:
-			acc.AddHistogram(metric.Name(), metric.Fields(), tags, metric.Time())
+			acc.AddHistogram(name, fields, tags, metric.Time())
 		default:
-			acc.AddFields(metric.Name(), metric.Fields(), tags, metric.Time())
+			acc.AddFields(name, fields, tags, metric.Time())
+		}
+	}
+
+	return nil
+}
+
+// initRelabelConfigs validates the relabeling rules once.
+func (p *Prometheus) initRelabelConfigs() error {
+	if p.relabelInit {
+		return nil
+	}
+	for _, configs := range [][]*RelabelConfig{p.RelabelConfigs, p.MetricRelabelConfigs} {
+		for _, c := range configs {
+			if err := c.init(); err != nil {
+				return err
+			}
+		}
+	}
+	p.relabelInit = true
+	return nil
+}
+
+// relabelMetric applies the metric relabeling rules to the tags of a metric
+// and its name, which is the name of its single field with metric_version 2.
+// It returns false if the metric is dropped.
+func (p *Prometheus) relabelMetric(name string, fields map[string]interface{}, tags map[string]string) (string, map[string]interface{}, map[string]string, bool) {
+	var value interface{}
+	if p.MetricVersion == 2 {
+		for k, v := range fields {
+			name, value = k, v
 		}
 	}
 
+	labels := make(map[string]string, len(tags)+1)
+	for k, v := range tags {
+		labels[k] = v
+	}
+	labels[nameLabel] = name
+	if !relabel(labels, p.MetricRelabelConfigs) {
+		return "", nil, nil, false
+	}
+
+	name = labels[nameLabel]
+	if name == "" {
+		return "", nil, nil, false
+	}
+	tags = make(map[string]string, len(labels))
+	for k, v := range labels {
+		if !strings.HasPrefix(k, "__") {
+			tags[k] = v
+		}
+	}
+
+	if p.MetricVersion == 2 {
+		return "prometheus", map[string]interface{}{name: value}, tags, true
+	}
+	return name, fields, tags, true
+}
+
+// Start watches the Kubernetes pods if monitor_kubernetes_pods is set.
+func (p *Prometheus) Start(_ telegraf.Accumulator) error {
+	if !p.MonitorPods {
+		return nil
+	}
+
+	k, err := newKubernetesClient(p.KubernetesURL)
+	if err != nil {
+		return err
+	}
+	if p.KubernetesResync.Duration < time.Second {
+		p.KubernetesResync.Duration = 5 * time.Minute
+	}
+
+	p.kubernetesPods = make(map[string]URLAndAddress)
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	p.wg.Add(1)
+	go p.watchPods(ctx, k)
 	return nil
 }
 
+func (p *Prometheus) Stop() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+}
+
 func init() {
 	inputs.Add("prometheus", func() telegraf.Input {
-		return &Prometheus{ResponseTimeout: internal.Duration{Duration: time.Second * 3}}
+		return &Prometheus{
+			ResponseTimeout:  internal.Duration{Duration: time.Second * 3},
+			KubernetesResync: internal.Duration{Duration: 5 * time.Minute},
+		}
 	})
 }
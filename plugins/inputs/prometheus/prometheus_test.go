@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sort"
 	"testing"
 	"time"
 
@@ -103,3 +104,100 @@ func TestPrometheusGeneratesMetricsAlthoughFirstDNSFails(t *testing.T) {
 	assert.True(t, acc.HasFloatField("test_metric", "value"))
 	assert.True(t, acc.HasTimestamp("test_metric", time.Unix(1490802350, 0)))
 }
+
+func TestPrometheusGeneratesMetricsV2(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, sampleTextFormat)
+	}))
+	defer ts.Close()
+
+	p := &Prometheus{
+		URLs:          []string{ts.URL},
+		MetricVersion: 2,
+	}
+
+	var acc testutil.Accumulator
+
+	err := acc.GatherError(p.Gather)
+	require.NoError(t, err)
+
+	assert.True(t, acc.HasFloatField("prometheus", "go_gc_duration_seconds"))
+	assert.True(t, acc.HasFloatField("prometheus", "go_gc_duration_seconds_count"))
+	assert.True(t, acc.HasFloatField("prometheus", "go_goroutines"))
+	assert.True(t, acc.HasFloatField("prometheus", "test_metric"))
+	assert.True(t, acc.TagValue("prometheus", "url") == ts.URL)
+
+	var quantiles []string
+	for _, m := range acc.Metrics {
+		if q, ok := m.Tags["quantile"]; ok {
+			quantiles = append(quantiles, q)
+		}
+		if _, ok := m.Fields["test_metric"]; ok {
+			assert.Equal(t, time.Unix(1490802350, 0), m.Time)
+		}
+	}
+	sort.Strings(quantiles)
+	assert.Equal(t, []string{"0", "0.25", "0.5", "0.75", "1"}, quantiles)
+}
+
+func TestPrometheusMetricRelabel(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, sampleTextFormat)
+	}))
+	defer ts.Close()
+
+	p := &Prometheus{
+		URLs: []string{ts.URL},
+		MetricRelabelConfigs: []*RelabelConfig{
+			{SourceLabels: []string{"__name__"}, Regex: "go_gc_.*", Action: "drop"},
+			{SourceLabels: []string{"__name__"}, Regex: "go_(.*)", TargetLabel: "__name__", Replacement: "golang_$1"},
+			{Regex: "url", Action: "labeldrop"},
+		},
+	}
+
+	var acc testutil.Accumulator
+
+	err := acc.GatherError(p.Gather)
+	require.NoError(t, err)
+
+	assert.False(t, acc.HasMeasurement("go_gc_duration_seconds"))
+	assert.False(t, acc.HasMeasurement("go_goroutines"))
+	assert.True(t, acc.HasFloatField("golang_goroutines", "gauge"))
+	assert.True(t, acc.HasFloatField("test_metric", "value"))
+	assert.False(t, acc.HasTag("test_metric", "url"))
+	assert.Equal(t, "value", acc.TagValue("test_metric", "label"))
+
+	p.MetricVersion = 2
+	acc.ClearMetrics()
+	err = acc.GatherError(p.Gather)
+	require.NoError(t, err)
+
+	assert.True(t, acc.HasFloatField("prometheus", "golang_goroutines"))
+	assert.False(t, acc.HasFloatField("prometheus", "go_gc_duration_seconds_sum"))
+}
+
+func TestPrometheusRelabelDropsTarget(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, sampleTextFormat)
+	}))
+	defer ts.Close()
+
+	p := &Prometheus{
+		URLs: []string{ts.URL},
+		RelabelConfigs: []*RelabelConfig{
+			{SourceLabels: []string{"__scheme__"}, Regex: "http", Action: "drop"},
+		},
+	}
+
+	var acc testutil.Accumulator
+
+	err := acc.GatherError(p.Gather)
+	require.NoError(t, err)
+	assert.Empty(t, acc.Metrics)
+
+	p = &Prometheus{
+		URLs:           []string{ts.URL},
+		RelabelConfigs: []*RelabelConfig{{Action: "unknown"}},
+	}
+	assert.Error(t, acc.GatherError(p.Gather))
+}
@@ -0,0 +1,173 @@
+package prometheus
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	relabelReplace   = "replace"
+	relabelKeep      = "keep"
+	relabelDrop      = "drop"
+	relabelLabelMap  = "labelmap"
+	relabelLabelDrop = "labeldrop"
+	relabelLabelKeep = "labelkeep"
+
+	// labels set on the targets before relabeling
+	addressLabel     = "__address__"
+	schemeLabel      = "__scheme__"
+	metricsPathLabel = "__metrics_path__"
+
+	// label of the metric name in metric relabeling
+	nameLabel = "__name__"
+)
+
+// RelabelConfig is a Prometheus relabeling rule, applied to the labels of a
+// target or of a scraped metric.
+type RelabelConfig struct {
+	// Labels whose values are concatenated to be matched
+	SourceLabels []string `toml:"source_labels"`
+	// Separator of the concatenated values, defaults to ";"
+	Separator string `toml:"separator"`
+	// Regular expression matched against the concatenated values, anchored
+	// at both ends, defaults to "(.*)"
+	Regex string `toml:"regex"`
+	// Label set by the replace action
+	TargetLabel string `toml:"target_label"`
+	// Value of the target label, may refer to the regex groups, defaults
+	// to "$1"
+	Replacement string `toml:"replacement"`
+	// One of replace, keep, drop, labelmap, labeldrop and labelkeep,
+	// defaults to replace
+	Action string `toml:"action"`
+
+	regex *regexp.Regexp
+}
+
+// init sets the defaults of the rule and compiles its regex.
+func (c *RelabelConfig) init() error {
+	if c.Separator == "" {
+		c.Separator = ";"
+	}
+	if c.Regex == "" {
+		c.Regex = "(.*)"
+	}
+	if c.Replacement == "" {
+		c.Replacement = "$1"
+	}
+	if c.Action == "" {
+		c.Action = relabelReplace
+	}
+
+	switch c.Action {
+	case relabelReplace:
+		if c.TargetLabel == "" {
+			return fmt.Errorf("relabel action %s requires a target_label", c.Action)
+		}
+	case relabelKeep, relabelDrop:
+		if len(c.SourceLabels) == 0 {
+			return fmt.Errorf("relabel action %s requires source_labels", c.Action)
+		}
+	case relabelLabelMap, relabelLabelDrop, relabelLabelKeep:
+	default:
+		return fmt.Errorf("unknown relabel action %q", c.Action)
+	}
+
+	regex, err := regexp.Compile("^(?:" + c.Regex + ")$")
+	if err != nil {
+		return fmt.Errorf("invalid relabel regex %q: %s", c.Regex, err)
+	}
+	c.regex = regex
+	return nil
+}
+
+// relabel applies the rules in order to the labels, which are modified in
+// place.  It returns false if a rule drops the labels.
+func relabel(labels map[string]string, configs []*RelabelConfig) bool {
+	for _, c := range configs {
+		values := make([]string, 0, len(c.SourceLabels))
+		for _, name := range c.SourceLabels {
+			values = append(values, labels[name])
+		}
+		value := strings.Join(values, c.Separator)
+
+		switch c.Action {
+		case relabelReplace:
+			match := c.regex.FindStringSubmatchIndex(value)
+			if match == nil {
+				continue
+			}
+			target := string(c.regex.ExpandString(nil, c.TargetLabel, value, match))
+			replacement := string(c.regex.ExpandString(nil, c.Replacement, value, match))
+			if replacement == "" {
+				delete(labels, target)
+			} else {
+				labels[target] = replacement
+			}
+		case relabelKeep:
+			if !c.regex.MatchString(value) {
+				return false
+			}
+		case relabelDrop:
+			if c.regex.MatchString(value) {
+				return false
+			}
+		case relabelLabelMap:
+			mapped := make(map[string]string)
+			for name, v := range labels {
+				if c.regex.MatchString(name) {
+					mapped[c.regex.ReplaceAllString(name, c.Replacement)] = v
+				}
+			}
+			for name, v := range mapped {
+				labels[name] = v
+			}
+		case relabelLabelDrop, relabelLabelKeep:
+			for name := range labels {
+				if c.regex.MatchString(name) == (c.Action == relabelLabelDrop) {
+					delete(labels, name)
+				}
+			}
+		}
+	}
+	return true
+}
+
+// relabelTarget applies the relabel rules to the target, whose address,
+// scheme and path are exposed as the __address__, __scheme__ and
+// __metrics_path__ labels along with its tags.  The labels starting with "__"
+// are removed from the tags once relabeled.  It returns false if the target
+// is dropped.
+func relabelTarget(u URLAndAddress, configs []*RelabelConfig) (URLAndAddress, bool) {
+	if len(configs) == 0 {
+		return u, true
+	}
+
+	labels := map[string]string{
+		addressLabel:     u.URL.Host,
+		schemeLabel:      u.URL.Scheme,
+		metricsPathLabel: u.URL.Path,
+	}
+	for k, v := range u.Tags {
+		labels[k] = v
+	}
+	if !relabel(labels, configs) {
+		return u, false
+	}
+
+	target := *u.URL
+	target.Host = labels[addressLabel]
+	target.Scheme = labels[schemeLabel]
+	target.Path = labels[metricsPathLabel]
+	target.RawPath = ""
+	u.URL = &target
+
+	u.Tags = make(map[string]string)
+	for k, v := range labels {
+		if !strings.HasPrefix(k, "__") {
+			u.Tags[k] = v
+		}
+	}
+	return u, true
+}
@@ -0,0 +1,128 @@
+package prometheus
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelabel(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   RelabelConfig
+		labels   map[string]string
+		expected map[string]string
+	}{
+		{
+			name: "replace",
+			config: RelabelConfig{
+				SourceLabels: []string{"a", "b"},
+				Regex:        "(.*);(.*)",
+				TargetLabel:  "c",
+				Replacement:  "${2}-${1}",
+			},
+			labels:   map[string]string{"a": "x", "b": "y"},
+			expected: map[string]string{"a": "x", "b": "y", "c": "y-x"},
+		},
+		{
+			name: "replace without match",
+			config: RelabelConfig{
+				SourceLabels: []string{"a"},
+				Regex:        "z",
+				TargetLabel:  "c",
+			},
+			labels:   map[string]string{"a": "x"},
+			expected: map[string]string{"a": "x"},
+		},
+		{
+			name: "replace with empty value",
+			config: RelabelConfig{
+				SourceLabels: []string{"b"},
+				TargetLabel:  "a",
+			},
+			labels:   map[string]string{"a": "x"},
+			expected: map[string]string{},
+		},
+		{
+			name: "labelmap",
+			config: RelabelConfig{
+				Regex:       "meta_(.+)",
+				Action:      "labelmap",
+				Replacement: "$1",
+			},
+			labels:   map[string]string{"meta_app": "web", "b": "y"},
+			expected: map[string]string{"meta_app": "web", "app": "web", "b": "y"},
+		},
+		{
+			name:     "labeldrop",
+			config:   RelabelConfig{Regex: "a|b", Action: "labeldrop"},
+			labels:   map[string]string{"a": "x", "b": "y", "ab": "z"},
+			expected: map[string]string{"ab": "z"},
+		},
+		{
+			name:     "labelkeep",
+			config:   RelabelConfig{Regex: "a|b", Action: "labelkeep"},
+			labels:   map[string]string{"a": "x", "b": "y", "ab": "z"},
+			expected: map[string]string{"a": "x", "b": "y"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.config.init())
+			assert.True(t, relabel(tt.labels, []*RelabelConfig{&tt.config}))
+			assert.Equal(t, tt.expected, tt.labels)
+		})
+	}
+}
+
+func TestRelabelKeepDrop(t *testing.T) {
+	keep := &RelabelConfig{SourceLabels: []string{"a"}, Regex: "x.*", Action: "keep"}
+	drop := &RelabelConfig{SourceLabels: []string{"a"}, Regex: "x.*", Action: "drop"}
+	require.NoError(t, keep.init())
+	require.NoError(t, drop.init())
+
+	assert.True(t, relabel(map[string]string{"a": "xy"}, []*RelabelConfig{keep}))
+	assert.False(t, relabel(map[string]string{"a": "yx"}, []*RelabelConfig{keep}))
+	assert.False(t, relabel(map[string]string{"a": "xy"}, []*RelabelConfig{drop}))
+	assert.True(t, relabel(map[string]string{"a": "yx"}, []*RelabelConfig{drop}))
+}
+
+func TestRelabelInvalidConfig(t *testing.T) {
+	for _, c := range []*RelabelConfig{
+		{Action: "unknown"},
+		{Action: "replace"},
+		{Action: "keep"},
+		{Action: "labeldrop", Regex: "("},
+	} {
+		assert.Error(t, c.init(), c.Action)
+	}
+}
+
+func TestRelabelTarget(t *testing.T) {
+	u, err := url.Parse("http://10.0.0.1:9102/metrics")
+	require.NoError(t, err)
+	target := URLAndAddress{URL: u, OriginalURL: u, Tags: map[string]string{"app": "web"}}
+
+	configs := []*RelabelConfig{
+		{SourceLabels: []string{"__address__"}, Regex: "([^:]+):.*", TargetLabel: "__address__", Replacement: "$1:9100"},
+		{SourceLabels: []string{"app"}, TargetLabel: "__metrics_path__", Replacement: "/$1/metrics"},
+		{SourceLabels: []string{"__address__"}, TargetLabel: "instance"},
+	}
+	for _, c := range configs {
+		require.NoError(t, c.init())
+	}
+
+	relabeled, ok := relabelTarget(target, configs)
+	require.True(t, ok)
+	assert.Equal(t, "http://10.0.0.1:9100/web/metrics", relabeled.URL.String())
+	assert.Equal(t, "http://10.0.0.1:9102/metrics", relabeled.OriginalURL.String())
+	assert.Equal(t, map[string]string{"app": "web", "instance": "10.0.0.1:9100"}, relabeled.Tags)
+
+	drop := &RelabelConfig{SourceLabels: []string{"app"}, Regex: "web", Action: "drop"}
+	require.NoError(t, drop.init())
+	_, ok = relabelTarget(target, []*RelabelConfig{drop})
+	assert.False(t, ok)
+}
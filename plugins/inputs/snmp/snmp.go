@@ -14,6 +14,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	mib "github.com/influxdata/telegraf/internal/snmp"
 	"github.com/influxdata/telegraf/plugins/inputs"
 
 	"github.com/soniah/gosnmp"
@@ -44,6 +45,11 @@ const sampleConfig = `
   #priv_protocol = ""         # Values: "DES", "AES", ""
   #priv_password = ""
 
+  ## MIB translation, "netsnmp" uses the net-snmp tools, "native" loads the
+  ## MIB files of the directories of path.
+  #translator = "netsnmp"       # Values: "netsnmp", "native"
+  #path = ["/usr/share/snmp/mibs"]
+
   ## measurement name
   name = "system"
   [[inputs.snmp.field]]
@@ -128,6 +134,11 @@ type Snmp struct {
 	EngineBoots  uint32
 	EngineTime   uint32
 
+	// Translator of the OIDs, values: "netsnmp", "native". Default: "netsnmp"
+	Translator string
+	// Path of the MIB directories loaded by the native translator.
+	Path []string
+
 	Tables []Table `toml:"table"`
 
 	// Name & Fields are the elements of a Table.
@@ -137,6 +148,7 @@ type Snmp struct {
 	Fields []Field `toml:"field"`
 
 	connectionCache []snmpConnection
+	translator      translator
 	initialized     bool
 }
 
@@ -147,14 +159,20 @@ func (s *Snmp) init() error {
 
 	s.connectionCache = make([]snmpConnection, len(s.Agents))
 
+	tr, err := newTranslator(s.Translator, s.Path)
+	if err != nil {
+		return err
+	}
+	s.translator = tr
+
 	for i := range s.Tables {
-		if err := s.Tables[i].init(); err != nil {
+		if err := s.Tables[i].init(tr); err != nil {
 			return Errorf(err, "initializing table %s", s.Tables[i].Name)
 		}
 	}
 
 	for i := range s.Fields {
-		if err := s.Fields[i].init(); err != nil {
+		if err := s.Fields[i].init(tr); err != nil {
 			return Errorf(err, "initializing field %s", s.Fields[i].Name)
 		}
 	}
@@ -186,18 +204,18 @@ type Table struct {
 }
 
 // init() builds & initializes the nested fields.
-func (t *Table) init() error {
+func (t *Table) init(tr translator) error {
 	if t.initialized {
 		return nil
 	}
 
-	if err := t.initBuild(); err != nil {
+	if err := t.initBuild(tr); err != nil {
 		return err
 	}
 
 	// initialize all the nested fields
 	for i := range t.Fields {
-		if err := t.Fields[i].init(); err != nil {
+		if err := t.Fields[i].init(tr); err != nil {
 			return Errorf(err, "initializing field %s", t.Fields[i].Name)
 		}
 	}
@@ -207,14 +225,14 @@ func (t *Table) init() error {
 }
 
 // initBuild initializes the table if it has an OID configured. If so, the
-// net-snmp tools will be used to look up the OID and auto-populate the table's
+// translator will be used to look up the OID and auto-populate the table's
 // fields.
-func (t *Table) initBuild() error {
+func (t *Table) initBuild(tr translator) error {
 	if t.Oid == "" {
 		return nil
 	}
 
-	_, _, oidText, fields, err := snmpTable(t.Oid)
+	_, _, oidText, fields, err := tr.SnmpTable(t.Oid)
 	if err != nil {
 		return err
 	}
@@ -245,18 +263,21 @@ type Field struct {
 	//  "int" will conver the value into an integer.
 	//  "hwaddr" will convert a 6-byte string to a MAC address.
 	//  "ipaddr" will convert the value to an IPv4 or IPv6 address.
+	//  "enum" will convert an integer to its name in the MIB.
 	Conversion string
 
+	// enums are the names of the integer values, for the "enum" conversion.
+	enums       map[int64]string
 	initialized bool
 }
 
 // init() converts OID names to numbers, and sets the .Name attribute if unset.
-func (f *Field) init() error {
+func (f *Field) init(tr translator) error {
 	if f.initialized {
 		return nil
 	}
 
-	_, oidNum, oidText, conversion, err := snmpTranslate(f.Oid)
+	_, oidNum, oidText, conversion, err := tr.SnmpTranslate(f.Oid)
 	if err != nil {
 		return Errorf(err, "translating")
 	}
@@ -267,6 +288,11 @@ func (f *Field) init() error {
 	if f.Conversion == "" {
 		f.Conversion = conversion
 	}
+	if f.Conversion == "enum" {
+		if f.enums, err = tr.SnmpEnums(f.Oid); err != nil {
+			return Errorf(err, "getting enums")
+		}
+	}
 
 	//TODO use textual convention conversion from the MIB
 
@@ -274,6 +300,14 @@ func (f *Field) init() error {
 	return nil
 }
 
+// convert converts the value according to the conversion of the field.
+func (f *Field) convert(v interface{}) (interface{}, error) {
+	if f.Conversion == "enum" {
+		return enumConvert(f.enums, v), nil
+	}
+	return fieldConvert(f.Conversion, v)
+}
+
 // RTable is the resulting table built from a Table.
 type RTable struct {
 	// Name is the name of the field, copied from Table.Name.
@@ -323,6 +357,8 @@ func init() {
 			Timeout:        internal.Duration{Duration: 5 * time.Second},
 			Version:        2,
 			Community:      "public",
+			Translator:     "netsnmp",
+			Path:           []string{"/usr/share/snmp/mibs"},
 		}
 	})
 }
@@ -442,7 +478,7 @@ func (t Table) Build(gs snmpConnection, walk bool) (*RTable, error) {
 				return nil, Errorf(err, "performing get on field %s", f.Name)
 			} else if pkt != nil && len(pkt.Variables) > 0 && pkt.Variables[0].Type != gosnmp.NoSuchObject && pkt.Variables[0].Type != gosnmp.NoSuchInstance {
 				ent := pkt.Variables[0]
-				fv, err := fieldConvert(f.Conversion, ent.Value)
+				fv, err := f.convert(ent.Value)
 				if err != nil {
 					return nil, Errorf(err, "converting %q (OID %s) for field %s", ent.Value, ent.Name, f.Name)
 				}
@@ -463,7 +499,7 @@ func (t Table) Build(gs snmpConnection, walk bool) (*RTable, error) {
 					idx = idx[:len(idx)-len(f.OidIndexSuffix)]
 				}
 
-				fv, err := fieldConvert(f.Conversion, ent.Value)
+				fv, err := f.convert(ent.Value)
 				if err != nil {
 					return Errorf(err, "converting %q (OID %s) for field %s", ent.Value, ent.Name, f.Name)
 				}
@@ -814,6 +850,41 @@ func fieldConvert(conv string, v interface{}) (interface{}, error) {
 	return nil, fmt.Errorf("invalid conversion type '%s'", conv)
 }
 
+// enumConvert converts an integer value to its name in the enums. An integer
+// without name is converted to a string of its number, so the type of the
+// field does not change, and the other values are unchanged.
+func enumConvert(enums map[int64]string, v interface{}) interface{} {
+	var i int64
+	switch vt := v.(type) {
+	case int:
+		i = int64(vt)
+	case int8:
+		i = int64(vt)
+	case int16:
+		i = int64(vt)
+	case int32:
+		i = int64(vt)
+	case int64:
+		i = vt
+	case uint:
+		i = int64(vt)
+	case uint8:
+		i = int64(vt)
+	case uint16:
+		i = int64(vt)
+	case uint32:
+		i = int64(vt)
+	case uint64:
+		i = int64(vt)
+	default:
+		return v
+	}
+	if name, ok := enums[i]; ok {
+		return name
+	}
+	return strconv.FormatInt(i, 10)
+}
+
 type snmpTableCache struct {
 	mibName string
 	oidNum  string
@@ -845,7 +916,7 @@ func snmpTable(oid string) (mibName string, oidNum string, oidText string, field
 }
 
 func snmpTableCall(oid string) (mibName string, oidNum string, oidText string, fields []Field, err error) {
-	mibName, oidNum, oidText, _, err = snmpTranslate(oid)
+	mibName, oidNum, oidText, _, err = mib.NetsnmpTranslate(oid)
 	if err != nil {
 		return "", "", "", nil, Errorf(err, "translating")
 	}
@@ -902,111 +973,3 @@ func snmpTableCall(oid string) (mibName string, oidNum string, oidText string, f
 
 	return mibName, oidNum, oidText, fields, err
 }
-
-type snmpTranslateCache struct {
-	mibName    string
-	oidNum     string
-	oidText    string
-	conversion string
-	err        error
-}
-
-var snmpTranslateCachesLock sync.Mutex
-var snmpTranslateCaches map[string]snmpTranslateCache
-
-// snmpTranslate resolves the given OID.
-func snmpTranslate(oid string) (mibName string, oidNum string, oidText string, conversion string, err error) {
-	snmpTranslateCachesLock.Lock()
-	if snmpTranslateCaches == nil {
-		snmpTranslateCaches = map[string]snmpTranslateCache{}
-	}
-
-	var stc snmpTranslateCache
-	var ok bool
-	if stc, ok = snmpTranslateCaches[oid]; !ok {
-		// This will result in only one call to snmptranslate running at a time.
-		// We could speed it up by putting a lock in snmpTranslateCache and then
-		// returning it immediately, and multiple callers would then release the
-		// snmpTranslateCachesLock and instead wait on the individual
-		// snmpTranlsation.Lock to release. But I don't know that the extra complexity
-		// is worth it. Especially when it would slam the system pretty hard if lots
-		// of lookups are being perfomed.
-
-		stc.mibName, stc.oidNum, stc.oidText, stc.conversion, stc.err = snmpTranslateCall(oid)
-		snmpTranslateCaches[oid] = stc
-	}
-
-	snmpTranslateCachesLock.Unlock()
-
-	return stc.mibName, stc.oidNum, stc.oidText, stc.conversion, stc.err
-}
-
-func snmpTranslateCall(oid string) (mibName string, oidNum string, oidText string, conversion string, err error) {
-	var out []byte
-	if strings.ContainsAny(oid, ":abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ") {
-		out, err = execCmd("snmptranslate", "-Td", "-Ob", oid)
-	} else {
-		out, err = execCmd("snmptranslate", "-Td", "-Ob", "-m", "all", oid)
-		if err, ok := err.(*exec.Error); ok && err.Err == exec.ErrNotFound {
-			// Silently discard error if snmptranslate not found and we have a numeric OID.
-			// Meaning we can get by without the lookup.
-			return "", oid, oid, "", nil
-		}
-	}
-	if err != nil {
-		return "", "", "", "", err
-	}
-
-	scanner := bufio.NewScanner(bytes.NewBuffer(out))
-	ok := scanner.Scan()
-	if !ok && scanner.Err() != nil {
-		return "", "", "", "", Errorf(scanner.Err(), "getting OID text")
-	}
-
-	oidText = scanner.Text()
-
-	i := strings.Index(oidText, "::")
-	if i == -1 {
-		// was not found in MIB.
-		if bytes.Contains(out, []byte("[TRUNCATED]")) {
-			return "", oid, oid, "", nil
-		}
-		// not truncated, but not fully found. We still need to parse out numeric OID, so keep going
-		oidText = oid
-	} else {
-		mibName = oidText[:i]
-		oidText = oidText[i+2:]
-	}
-
-	for scanner.Scan() {
-		line := scanner.Text()
-
-		if strings.HasPrefix(line, "  -- TEXTUAL CONVENTION ") {
-			tc := strings.TrimPrefix(line, "  -- TEXTUAL CONVENTION ")
-			switch tc {
-			case "MacAddress", "PhysAddress":
-				conversion = "hwaddr"
-			case "InetAddressIPv4", "InetAddressIPv6", "InetAddress":
-				conversion = "ipaddr"
-			}
-		} else if strings.HasPrefix(line, "::= { ") {
-			objs := strings.TrimPrefix(line, "::= { ")
-			objs = strings.TrimSuffix(objs, " }")
-
-			for _, obj := range strings.Split(objs, " ") {
-				if len(obj) == 0 {
-					continue
-				}
-				if i := strings.Index(obj, "("); i != -1 {
-					obj = obj[i+1:]
-					oidNum += "." + obj[:strings.Index(obj, ")")]
-				} else {
-					oidNum += "." + obj
-				}
-			}
-			break
-		}
-	}
-
-	return mibName, oidNum, oidText, conversion, nil
-}
@@ -6,6 +6,8 @@ import (
 	"os/exec"
 	"strings"
 	"testing"
+
+	mib "github.com/influxdata/telegraf/internal/snmp"
 )
 
 type mockedCommandResult struct {
@@ -57,6 +59,7 @@ func TestMockExecCommand(t *testing.T) {
 
 func init() {
 	execCommand = mockExecCommand
+	mib.ExecCommand = mockExecCommand
 }
 
 // BEGIN GO GENERATE CONTENT
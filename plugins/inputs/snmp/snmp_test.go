@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf/internal"
+	mib "github.com/influxdata/telegraf/internal/snmp"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/influxdata/toml"
 	"github.com/soniah/gosnmp"
@@ -148,7 +149,7 @@ func TestFieldInit(t *testing.T) {
 
 	for _, txl := range translations {
 		f := Field{Oid: txl.inputOid, Name: txl.inputName, Conversion: txl.inputConversion}
-		err := f.init()
+		err := f.init(netsnmpTranslator{})
 		if !assert.NoError(t, err, "inputOid='%s' inputName='%s'", txl.inputOid, txl.inputName) {
 			continue
 		}
@@ -162,7 +163,7 @@ func TestTableInit(t *testing.T) {
 		Oid:    ".1.0.0.0",
 		Fields: []Field{{Oid: ".999", Name: "foo"}},
 	}
-	err := tbl.init()
+	err := tbl.init(netsnmpTranslator{})
 	require.NoError(t, err)
 
 	assert.Equal(t, "testTable", tbl.Name)
@@ -205,6 +206,8 @@ func TestSnmpInit_noTranslate(t *testing.T) {
 	execCommand = func(_ string, _ ...string) *exec.Cmd {
 		return exec.Command("snmptranslateExecErrNotFound")
 	}
+	defer func(ec func(string, ...string) *exec.Cmd) { mib.ExecCommand = ec }(mib.ExecCommand)
+	mib.ExecCommand = execCommand
 
 	s := &Snmp{
 		Fields: []Field{
@@ -249,6 +252,110 @@ func TestSnmpInit_noTranslate(t *testing.T) {
 	assert.Equal(t, false, s.Tables[0].Fields[2].IsTag)
 }
 
+func TestFieldInit_native(t *testing.T) {
+	tr, err := newTranslator("native", []string{"testdata"})
+	require.NoError(t, err)
+
+	translations := []struct {
+		inputOid     string
+		inputName    string
+		expectedOid  string
+		expectedName string
+	}{
+		{".1.2.3", "foo", ".1.2.3", "foo"},
+		{".iso.2.3", "foo", ".1.2.3", "foo"},
+		{".1.0.0.0.1.1", "", ".1.0.0.0.1.1", "server"},
+		{".1.0.0.0.1.1.0", "", ".1.0.0.0.1.1.0", "server.0"},
+		{".999", "", ".999", ".999"},
+		{"TEST::server", "", ".1.0.0.0.1.1", "server"},
+		{"TEST::server.0", "", ".1.0.0.0.1.1.0", "server.0"},
+		{"TEST::server", "foo", ".1.0.0.0.1.1", "foo"},
+	}
+
+	for _, txl := range translations {
+		f := Field{Oid: txl.inputOid, Name: txl.inputName}
+		err := f.init(tr)
+		if !assert.NoError(t, err, "inputOid='%s' inputName='%s'", txl.inputOid, txl.inputName) {
+			continue
+		}
+		assert.Equal(t, txl.expectedOid, f.Oid, "inputOid='%s' inputName='%s'", txl.inputOid, txl.inputName)
+		assert.Equal(t, txl.expectedName, f.Name, "inputOid='%s' inputName='%s'", txl.inputOid, txl.inputName)
+	}
+
+	f := Field{Oid: "TEST::foo"}
+	assert.Error(t, f.init(tr))
+}
+
+func TestFieldInit_enum(t *testing.T) {
+	tr, err := newTranslator("native", []string{"testdata"})
+	require.NoError(t, err)
+
+	f := Field{Oid: "TEST::status", Conversion: "enum"}
+	err = f.init(tr)
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]string{1: "up", 2: "down"}, f.enums)
+
+	f = Field{Oid: "TEST::status", Conversion: "enum"}
+	assert.Error(t, f.init(netsnmpTranslator{}))
+}
+
+func TestTableInit_native(t *testing.T) {
+	tr, err := newTranslator("native", []string{"testdata"})
+	require.NoError(t, err)
+
+	tbl := Table{
+		Oid:    ".1.0.0.0",
+		Fields: []Field{{Oid: ".999", Name: "foo"}},
+	}
+	err = tbl.init(tr)
+	require.NoError(t, err)
+
+	assert.Equal(t, "testTable", tbl.Name)
+
+	assert.Len(t, tbl.Fields, 4)
+	assert.Contains(t, tbl.Fields, Field{Oid: ".999", Name: "foo", initialized: true})
+	assert.Contains(t, tbl.Fields, Field{Oid: ".1.0.0.0.1.1", Name: "server", IsTag: true, initialized: true})
+	assert.Contains(t, tbl.Fields, Field{Oid: ".1.0.0.0.1.2", Name: "connections", initialized: true})
+	assert.Contains(t, tbl.Fields, Field{Oid: ".1.0.0.0.1.3", Name: "latency", initialized: true})
+
+	tbl = Table{Oid: "TEST::hostname"}
+	assert.Error(t, tbl.init(tr))
+}
+
+func TestSnmpInit_native(t *testing.T) {
+	s := &Snmp{
+		Translator: "native",
+		Path:       []string{"testdata"},
+		Tables: []Table{
+			{Oid: "TEST::testTable"},
+		},
+		Fields: []Field{
+			{Oid: "TEST::hostname"},
+		},
+	}
+
+	err := s.init()
+	require.NoError(t, err)
+
+	assert.Len(t, s.Tables[0].Fields, 3)
+	assert.Contains(t, s.Tables[0].Fields, Field{Oid: ".1.0.0.0.1.1", Name: "server", IsTag: true, initialized: true})
+	assert.Contains(t, s.Tables[0].Fields, Field{Oid: ".1.0.0.0.1.2", Name: "connections", initialized: true})
+	assert.Contains(t, s.Tables[0].Fields, Field{Oid: ".1.0.0.0.1.3", Name: "latency", initialized: true})
+
+	assert.Equal(t, Field{
+		Oid:         ".1.0.0.1.1",
+		Name:        "hostname",
+		initialized: true,
+	}, s.Fields[0])
+}
+
+func TestSnmpInit_invalidTranslator(t *testing.T) {
+	s := &Snmp{
+		Translator: "foo",
+	}
+	assert.Error(t, s.init())
+}
+
 func TestGetSNMPConnection_v2(t *testing.T) {
 	s := &Snmp{
 		Agents:    []string{"1.2.3.4:567", "1.2.3.4"},
@@ -506,6 +613,28 @@ func TestTableBuild_walk(t *testing.T) {
 	assert.Contains(t, tb.Rows, rtr4)
 }
 
+func TestTableBuild_enum(t *testing.T) {
+	tbl := Table{
+		Name: "mytable",
+		Fields: []Field{
+			{
+				Name:       "myfield1",
+				Oid:        ".1.0.0.0.1.2",
+				Conversion: "enum",
+				enums:      map[int64]string{1: "up", 2: "down"},
+			},
+		},
+	}
+
+	tb, err := tbl.Build(tsc, true)
+	require.NoError(t, err)
+
+	assert.Len(t, tb.Rows, 3)
+	assert.Contains(t, tb.Rows, RTableRow{Tags: map[string]string{}, Fields: map[string]interface{}{"myfield1": "up"}})
+	assert.Contains(t, tb.Rows, RTableRow{Tags: map[string]string{}, Fields: map[string]interface{}{"myfield1": "down"}})
+	assert.Contains(t, tb.Rows, RTableRow{Tags: map[string]string{}, Fields: map[string]interface{}{"myfield1": "0"}})
+}
+
 func TestTableBuild_noWalk(t *testing.T) {
 	tbl := Table{
 		Name: "mytable",
@@ -693,37 +822,14 @@ func TestFieldConvert(t *testing.T) {
 	}
 }
 
-func TestSnmpTranslateCache_miss(t *testing.T) {
-	snmpTranslateCaches = nil
-	oid := "IF-MIB::ifPhysAddress.1"
-	mibName, oidNum, oidText, conversion, err := snmpTranslate(oid)
-	assert.Len(t, snmpTranslateCaches, 1)
-	stc := snmpTranslateCaches[oid]
-	require.NotNil(t, stc)
-	assert.Equal(t, mibName, stc.mibName)
-	assert.Equal(t, oidNum, stc.oidNum)
-	assert.Equal(t, oidText, stc.oidText)
-	assert.Equal(t, conversion, stc.conversion)
-	assert.Equal(t, err, stc.err)
-}
-
-func TestSnmpTranslateCache_hit(t *testing.T) {
-	snmpTranslateCaches = map[string]snmpTranslateCache{
-		"foo": snmpTranslateCache{
-			mibName:    "a",
-			oidNum:     "b",
-			oidText:    "c",
-			conversion: "d",
-			err:        fmt.Errorf("e"),
-		},
-	}
-	mibName, oidNum, oidText, conversion, err := snmpTranslate("foo")
-	assert.Equal(t, "a", mibName)
-	assert.Equal(t, "b", oidNum)
-	assert.Equal(t, "c", oidText)
-	assert.Equal(t, "d", conversion)
-	assert.Equal(t, fmt.Errorf("e"), err)
-	snmpTranslateCaches = nil
+func TestEnumConvert(t *testing.T) {
+	enums := map[int64]string{1: "up", 2: "down"}
+	assert.Equal(t, "up", enumConvert(enums, 1))
+	assert.Equal(t, "down", enumConvert(enums, int32(2)))
+	assert.Equal(t, "down", enumConvert(enums, uint64(2)))
+	assert.Equal(t, "3", enumConvert(enums, 3))
+	assert.Equal(t, "foo", enumConvert(enums, "foo"))
+	assert.Equal(t, []byte("foo"), enumConvert(enums, []byte("foo")))
 }
 
 func TestSnmpTableCache_miss(t *testing.T) {
@@ -0,0 +1,78 @@
+package snmp
+
+import (
+	"fmt"
+
+	mib "github.com/influxdata/telegraf/internal/snmp"
+)
+
+// translator resolves the OIDs of the fields and tables.
+type translator interface {
+	// SnmpTranslate resolves the given OID.
+	SnmpTranslate(oid string) (mibName string, oidNum string, oidText string, conversion string, err error)
+	// SnmpTable resolves the given OID as a table, providing information
+	// about the table and fields within.
+	SnmpTable(oid string) (mibName string, oidNum string, oidText string, fields []Field, err error)
+	// SnmpEnums returns the names of the integer values of the given OID.
+	SnmpEnums(oid string) (map[int64]string, error)
+}
+
+// newTranslator returns the translator of the name, loading the MIBs of the
+// paths for the native translator.
+func newTranslator(name string, paths []string) (translator, error) {
+	switch name {
+	case "", "netsnmp":
+		return netsnmpTranslator{}, nil
+	case "native":
+		tree, err := mib.LoadTree(paths)
+		if err != nil {
+			return nil, Errorf(err, "loading MIBs")
+		}
+		return nativeTranslator{tree: tree}, nil
+	default:
+		return nil, fmt.Errorf("invalid translator '%s'", name)
+	}
+}
+
+// netsnmpTranslator uses the net-snmp tools to resolve the OIDs.
+type netsnmpTranslator struct{}
+
+func (netsnmpTranslator) SnmpTranslate(oid string) (mibName string, oidNum string, oidText string, conversion string, err error) {
+	return mib.NetsnmpTranslate(oid)
+}
+
+func (netsnmpTranslator) SnmpTable(oid string) (mibName string, oidNum string, oidText string, fields []Field, err error) {
+	return snmpTable(oid)
+}
+
+func (netsnmpTranslator) SnmpEnums(oid string) (map[int64]string, error) {
+	return nil, fmt.Errorf("enum conversion requires the native translator")
+}
+
+// nativeTranslator resolves the OIDs with the MIBs loaded by the plugin.  The
+// MIBs of the same paths are shared by all the instances of the plugin.
+type nativeTranslator struct {
+	tree *mib.Tree
+}
+
+func (n nativeTranslator) SnmpTranslate(oid string) (mibName string, oidNum string, oidText string, conversion string, err error) {
+	return n.tree.Translate(oid)
+}
+
+func (n nativeTranslator) SnmpTable(oid string) (mibName string, oidNum string, oidText string, fields []Field, err error) {
+	mibName, oidNum, oidText, columns, err := n.tree.Table(oid)
+	if err != nil {
+		return "", "", "", nil, err
+	}
+	if len(columns) == 0 {
+		return "", "", "", nil, fmt.Errorf("could not find any columns in table")
+	}
+	for _, c := range columns {
+		fields = append(fields, Field{Name: c.Name, Oid: c.Oid, IsTag: c.IsIndex})
+	}
+	return mibName, oidNum, oidText, fields, nil
+}
+
+func (n nativeTranslator) SnmpEnums(oid string) (map[int64]string, error) {
+	return n.tree.Enums(oid)
+}
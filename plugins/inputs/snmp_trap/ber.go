@@ -0,0 +1,226 @@
+package snmp_trap
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// BER tags of the SNMP types and PDUs
+const (
+	tagInteger          = 0x02
+	tagOctetString      = 0x04
+	tagNull             = 0x05
+	tagObjectIdentifier = 0x06
+	tagSequence         = 0x30
+	tagIPAddress        = 0x40
+	tagCounter32        = 0x41
+	tagGauge32          = 0x42
+	tagTimeTicks        = 0x43
+	tagOpaque           = 0x44
+	tagCounter64        = 0x46
+	tagNoSuchObject     = 0x80
+	tagNoSuchInstance   = 0x81
+	tagEndOfMibView     = 0x82
+
+	pduGetResponse = 0xa2
+	pduTrapV1      = 0xa4
+	pduInform      = 0xa6
+	pduTrapV2      = 0xa7
+)
+
+var errTruncated = errors.New("truncated packet")
+
+// objectIdentifier is the value of a varbind of type OBJECT IDENTIFIER.
+type objectIdentifier string
+
+// element is a BER encoded type-length-value.
+type element struct {
+	tag   byte
+	value []byte
+	// offsets of the tag and of the value in the packet
+	start  int
+	offset int
+}
+
+// decoder reads the elements encoded in the value of a constructed element.
+type decoder struct {
+	buf    []byte
+	pos    int
+	offset int
+}
+
+func newDecoder(e element) *decoder {
+	return &decoder{buf: e.value, offset: e.offset}
+}
+
+// more returns true if elements are left to read.
+func (d *decoder) more() bool {
+	return d.pos < len(d.buf)
+}
+
+// read reads the next element.
+func (d *decoder) read() (element, error) {
+	if d.pos+2 > len(d.buf) {
+		return element{}, errTruncated
+	}
+	start := d.pos
+	tag := d.buf[d.pos]
+	length := int(d.buf[d.pos+1])
+	d.pos += 2
+
+	if length&0x80 != 0 {
+		n := length & 0x7f
+		if n == 0 || n > 4 {
+			return element{}, fmt.Errorf("unsupported length of %d bytes", n)
+		}
+		if d.pos+n > len(d.buf) {
+			return element{}, errTruncated
+		}
+		length = 0
+		for _, b := range d.buf[d.pos : d.pos+n] {
+			length = length<<8 | int(b)
+		}
+		d.pos += n
+	}
+
+	if length < 0 || d.pos+length > len(d.buf) {
+		return element{}, errTruncated
+	}
+	e := element{
+		tag:    tag,
+		value:  d.buf[d.pos : d.pos+length],
+		start:  d.offset + start,
+		offset: d.offset + d.pos,
+	}
+	d.pos += length
+	return e, nil
+}
+
+// expect reads the next element, which must have the tag.
+func (d *decoder) expect(tag byte) (element, error) {
+	e, err := d.read()
+	if err != nil {
+		return e, err
+	}
+	if e.tag != tag {
+		return e, fmt.Errorf("unexpected tag 0x%02x, expected 0x%02x", e.tag, tag)
+	}
+	return e, nil
+}
+
+func (d *decoder) readInt() (int64, error) {
+	e, err := d.expect(tagInteger)
+	if err != nil {
+		return 0, err
+	}
+	return decodeInt(e.value)
+}
+
+func (d *decoder) readBytes() ([]byte, error) {
+	e, err := d.expect(tagOctetString)
+	if err != nil {
+		return nil, err
+	}
+	return e.value, nil
+}
+
+func (d *decoder) readOID() (string, error) {
+	e, err := d.expect(tagObjectIdentifier)
+	if err != nil {
+		return "", err
+	}
+	return decodeOID(e.value)
+}
+
+// decodeInt decodes a two's complement integer.
+func decodeInt(b []byte) (int64, error) {
+	if len(b) == 0 || len(b) > 8 {
+		return 0, fmt.Errorf("invalid integer of %d bytes", len(b))
+	}
+	v := int64(int8(b[0]))
+	for _, c := range b[1:] {
+		v = v<<8 | int64(c)
+	}
+	return v, nil
+}
+
+// decodeUint decodes an unsigned integer, which may be prefixed by a zero
+// byte.
+func decodeUint(b []byte) (uint64, error) {
+	if len(b) > 1 && b[0] == 0 {
+		b = b[1:]
+	}
+	if len(b) == 0 || len(b) > 8 {
+		return 0, fmt.Errorf("invalid unsigned integer of %d bytes", len(b))
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+// decodeOID decodes an object identifier to its numeric form with a leading
+// dot, such as ".1.3.6.1.2.1.1.3.0".
+func decodeOID(b []byte) (string, error) {
+	if len(b) == 0 {
+		return "", errors.New("empty object identifier")
+	}
+
+	var ids []uint64
+	var v uint64
+	for i, c := range b {
+		v = v<<7 | uint64(c&0x7f)
+		if c&0x80 != 0 {
+			if i == len(b)-1 {
+				return "", errors.New("truncated object identifier")
+			}
+			continue
+		}
+		if len(ids) == 0 {
+			// the first sub-identifier encodes the first two
+			if v < 80 {
+				ids = append(ids, v/40, v%40)
+			} else {
+				ids = append(ids, 2, v-80)
+			}
+		} else {
+			ids = append(ids, v)
+		}
+		v = 0
+	}
+
+	oid := make([]byte, 0, len(b)*3)
+	for _, id := range ids {
+		oid = append(oid, '.')
+		oid = strconv.AppendUint(oid, id, 10)
+	}
+	return string(oid), nil
+}
+
+// decodeValue decodes the value of a varbind.  The value is nil for the
+// types without value, such as NULL and noSuchObject.
+func decodeValue(e element) (interface{}, error) {
+	switch e.tag {
+	case tagInteger:
+		return decodeInt(e.value)
+	case tagOctetString, tagOpaque:
+		return e.value, nil
+	case tagObjectIdentifier:
+		oid, err := decodeOID(e.value)
+		return objectIdentifier(oid), err
+	case tagIPAddress:
+		if len(e.value) != 4 {
+			return nil, fmt.Errorf("invalid IP address of %d bytes", len(e.value))
+		}
+		return net.IP(e.value).String(), nil
+	case tagCounter32, tagGauge32, tagTimeTicks, tagCounter64:
+		return decodeUint(e.value)
+	case tagNull, tagNoSuchObject, tagNoSuchInstance, tagEndOfMibView:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported type 0x%02x", e.tag)
+	}
+}
@@ -0,0 +1,197 @@
+package snmp_trap
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+)
+
+const (
+	// OIDs of the SNMPv2 notification header varbinds
+	sysUpTimeOID = ".1.3.6.1.2.1.1.3.0"
+	trapOIDOID   = ".1.3.6.1.6.3.1.1.4.1.0"
+
+	// OID prefix of the generic SNMPv1 traps, see RFC 3584 section 3.1
+	genericTrapsOID = ".1.3.6.1.6.3.1.1.5"
+	// specific SNMPv1 traps
+	enterpriseSpecific = 6
+)
+
+// trap is a decoded SNMP notification.
+type trap struct {
+	version string
+	pduType byte
+
+	// OID of the notification
+	oid string
+	// agent address of SNMPv1 traps
+	agentAddress string
+
+	varbinds []varbind
+
+	// offset of the PDU tag in the packet, where the type of an inform is
+	// replaced to acknowledge it
+	pduStart int
+}
+
+type varbind struct {
+	oid   string
+	value interface{}
+}
+
+// parse decodes an SNMP notification message.  The SNMPv3 messages are
+// authenticated and decrypted by the usm, which is nil if SNMPv3 is not
+// enabled.
+func parse(packet []byte, u *usm) (*trap, error) {
+	msg, err := newDecoder(element{value: packet}).expect(tagSequence)
+	if err != nil {
+		return nil, err
+	}
+	d := newDecoder(msg)
+
+	version, err := d.readInt()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &trap{}
+	var pdu element
+	switch version {
+	case 0, 1:
+		t.version = "1"
+		if version == 1 {
+			t.version = "2c"
+		}
+		// community
+		if _, err := d.readBytes(); err != nil {
+			return nil, err
+		}
+		if pdu, err = d.read(); err != nil {
+			return nil, err
+		}
+	case 3:
+		t.version = "3"
+		if u == nil {
+			return nil, errors.New("SNMPv3 is not enabled, sec_name is not set")
+		}
+		if pdu, err = u.decode(packet, d); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported SNMP version %d", version)
+	}
+
+	if err := t.parsePDU(pdu); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// parsePDU decodes the notification PDU.  The SNMPv1 traps are converted to
+// SNMPv2 notifications following RFC 3584, with the timestamp as the value of
+// sysUpTime.
+func (t *trap) parsePDU(pdu element) error {
+	t.pduType = pdu.tag
+	t.pduStart = pdu.start
+	d := newDecoder(pdu)
+
+	switch pdu.tag {
+	case pduTrapV1:
+		if t.version != "1" {
+			return fmt.Errorf("SNMPv1 trap in a SNMPv%s message", t.version)
+		}
+		enterprise, err := d.readOID()
+		if err != nil {
+			return err
+		}
+		addr, err := d.expect(tagIPAddress)
+		if err != nil {
+			return err
+		}
+		if len(addr.value) == 4 {
+			t.agentAddress = net.IP(addr.value).String()
+		}
+		generic, err := d.readInt()
+		if err != nil {
+			return err
+		}
+		specific, err := d.readInt()
+		if err != nil {
+			return err
+		}
+		e, err := d.expect(tagTimeTicks)
+		if err != nil {
+			return err
+		}
+		timestamp, err := decodeUint(e.value)
+		if err != nil {
+			return err
+		}
+
+		if generic == enterpriseSpecific {
+			t.oid = enterprise + ".0." + strconv.FormatInt(specific, 10)
+		} else {
+			t.oid = genericTrapsOID + "." + strconv.FormatInt(generic+1, 10)
+		}
+		t.varbinds = append(t.varbinds, varbind{oid: sysUpTimeOID, value: timestamp})
+	case pduTrapV2, pduInform:
+		// request-id, error-status and error-index
+		for i := 0; i < 3; i++ {
+			if _, err := d.readInt(); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported PDU type 0x%02x", pdu.tag)
+	}
+
+	vbl, err := d.expect(tagSequence)
+	if err != nil {
+		return err
+	}
+	d = newDecoder(vbl)
+	for d.more() {
+		vb, err := d.expect(tagSequence)
+		if err != nil {
+			return err
+		}
+		vd := newDecoder(vb)
+		oid, err := vd.readOID()
+		if err != nil {
+			return err
+		}
+		e, err := vd.read()
+		if err != nil {
+			return err
+		}
+		value, err := decodeValue(e)
+		if err != nil {
+			return fmt.Errorf("varbind %s: %s", oid, err)
+		}
+
+		if oid == trapOIDOID && pdu.tag != pduTrapV1 {
+			oid, ok := value.(objectIdentifier)
+			if !ok {
+				return errors.New("snmpTrapOID.0 is not an object identifier")
+			}
+			t.oid = string(oid)
+			continue
+		}
+		t.varbinds = append(t.varbinds, varbind{oid: oid, value: value})
+	}
+
+	if t.oid == "" {
+		return errors.New("notification without snmpTrapOID.0")
+	}
+	return nil
+}
+
+// response returns the response acknowledging an inform of an SNMPv2c
+// message, that is the same message with a response PDU.
+func (t *trap) response(packet []byte) []byte {
+	resp := make([]byte, len(packet))
+	copy(resp, packet)
+	resp[t.pduStart] = pduGetResponse
+	return resp
+}
@@ -0,0 +1,252 @@
+package snmp_trap
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	mib "github.com/influxdata/telegraf/internal/snmp"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const sampleConfig = `
+  ## Transport, local address, and port to listen on.  Transport must
+  ## be "udp://".  Omit local address to listen on all interfaces.
+  ##   example: "udp://127.0.0.1:1234"
+  # service_address = "udp://:162"
+
+  ## MIB translation, "netsnmp" uses the net-snmp tools, "native" loads the
+  ## MIB files of the directories of path.
+  # translator = "netsnmp"
+  # path = ["/usr/share/snmp/mibs"]
+
+  ## SNMPv3 user of the notifications, SNMPv3 is not accepted if unset.
+  # sec_name = "myuser"
+  ## Minimum security level of the notifications, values:
+  ## "noAuthNoPriv", "authNoPriv", "authPriv".  Defaults to "authPriv" if
+  ## priv_protocol is set, "authNoPriv" if auth_protocol is set, otherwise
+  ## "noAuthNoPriv".
+  # sec_level = "authNoPriv"
+  ## Values: "MD5", "SHA", ""
+  # auth_protocol = "MD5"
+  # auth_password = "pass"
+  ## Values: "DES", "AES", ""
+  # priv_protocol = ""
+  # priv_password = ""
+`
+
+const (
+	measurement = "snmp_trap"
+
+	// maximum size of a UDP datagram
+	maxPacketSize = 65535
+)
+
+type SnmpTrap struct {
+	ServiceAddress string `toml:"service_address"`
+	// Translator of the OIDs, values: "netsnmp", "native"
+	Translator string `toml:"translator"`
+	// Path of the MIB directories loaded by the native translator
+	Path []string `toml:"path"`
+
+	SecName      string `toml:"sec_name"`
+	SecLevel     string `toml:"sec_level"`
+	AuthProtocol string `toml:"auth_protocol"`
+	AuthPassword string `toml:"auth_password"`
+	PrivProtocol string `toml:"priv_protocol"`
+	PrivPassword string `toml:"priv_password"`
+
+	acc  telegraf.Accumulator
+	conn net.PacketConn
+	usm  *usm
+	wg   sync.WaitGroup
+
+	// lookup translates an OID to its MIB and name, replaced by the tests
+	lookup func(oid string) (mibName string, oidText string, err error)
+}
+
+func (s *SnmpTrap) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *SnmpTrap) Description() string {
+	return "Receive SNMP traps and informs"
+}
+
+// Gather does nothing, the notifications are added as they are received.
+func (s *SnmpTrap) Gather(_ telegraf.Accumulator) error {
+	return nil
+}
+
+func (s *SnmpTrap) Start(acc telegraf.Accumulator) error {
+	spl := strings.SplitN(s.ServiceAddress, "://", 2)
+	if len(spl) != 2 {
+		return fmt.Errorf("invalid service address: %s", s.ServiceAddress)
+	}
+	switch spl[0] {
+	case "udp", "udp4", "udp6":
+	default:
+		return fmt.Errorf("unsupported transport %s, must be udp", spl[0])
+	}
+
+	if s.SecName != "" {
+		u, err := newUSM(s.SecName, s.SecLevel, s.AuthProtocol, s.AuthPassword, s.PrivProtocol, s.PrivPassword)
+		if err != nil {
+			return err
+		}
+		s.usm = u
+	}
+	if s.lookup == nil {
+		lookup, err := newLookup(s.Translator, s.Path)
+		if err != nil {
+			return err
+		}
+		s.lookup = lookup
+	}
+
+	conn, err := net.ListenPacket(spl[0], spl[1])
+	if err != nil {
+		return err
+	}
+	log.Printf("I! [inputs.snmp_trap] Listening on %s://%s", spl[0], conn.LocalAddr())
+
+	s.acc = acc
+	s.conn = conn
+	s.wg.Add(1)
+	go s.listen()
+	return nil
+}
+
+func (s *SnmpTrap) Stop() {
+	if s.conn == nil {
+		return
+	}
+	s.conn.Close()
+	s.wg.Wait()
+}
+
+// listen receives the notifications until the connection is closed.
+func (s *SnmpTrap) listen() {
+	defer s.wg.Done()
+
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			if !strings.HasSuffix(err.Error(), ": use of closed network connection") {
+				s.acc.AddError(err)
+			}
+			return
+		}
+
+		packet := buf[:n]
+		t, err := parse(packet, s.usm)
+		if err != nil {
+			s.acc.AddError(fmt.Errorf("unable to decode notification from %s: %s", addr, err))
+			continue
+		}
+
+		// SNMPv3 informs cannot be acknowledged, as the plugin is not an
+		// authoritative engine
+		if t.pduType == pduInform && t.version == "2c" {
+			if _, err := s.conn.WriteTo(t.response(packet), addr); err != nil {
+				s.acc.AddError(fmt.Errorf("unable to acknowledge inform from %s: %s", addr, err))
+			}
+		}
+
+		s.addTrap(t, addr)
+	}
+}
+
+// addTrap adds the notification as a metric, whose fields are the varbinds
+// named after their OIDs.
+func (s *SnmpTrap) addTrap(t *trap, addr net.Addr) {
+	now := time.Now()
+
+	tags := map[string]string{
+		"version": t.version,
+		"oid":     t.oid,
+	}
+	if host, _, err := net.SplitHostPort(addr.String()); err == nil {
+		tags["source"] = host
+	}
+	mibName, name := s.translate(t.oid)
+	tags["name"] = name
+	if mibName != "" {
+		tags["mib"] = mibName
+	}
+
+	fields := make(map[string]interface{}, len(t.varbinds)+1)
+	for _, vb := range t.varbinds {
+		var value interface{}
+		switch v := vb.value.(type) {
+		case nil:
+			continue
+		case []byte:
+			value = string(v)
+		case objectIdentifier:
+			_, value = s.translate(string(v))
+		default:
+			value = v
+		}
+		_, field := s.translate(vb.oid)
+		fields[field] = value
+	}
+	if t.agentAddress != "" {
+		fields["agent_address"] = t.agentAddress
+	}
+
+	s.acc.AddFields(measurement, fields, tags, now)
+}
+
+// translate returns the MIB and the name of the OID, the name being the
+// numeric OID if it cannot be translated.
+func (s *SnmpTrap) translate(oid string) (string, string) {
+	mibName, oidText, err := s.lookup(oid)
+	if err != nil {
+		s.acc.AddError(fmt.Errorf("unable to translate %s: %s", oid, err))
+		return "", oid
+	}
+	return mibName, oidText
+}
+
+// newLookup returns the lookup of the translator, loading the MIBs of the
+// paths for the native translator.  The MIBs of the same paths are shared
+// with the snmp input.
+func newLookup(translator string, paths []string) (func(oid string) (string, string, error), error) {
+	switch translator {
+	case "", "netsnmp":
+		return netsnmpLookup, nil
+	case "native":
+		tree, err := mib.LoadTree(paths)
+		if err != nil {
+			return nil, fmt.Errorf("loading MIBs: %s", err)
+		}
+		return func(oid string) (string, string, error) {
+			mibName, _, oidText, _, err := tree.Translate(oid)
+			return mibName, oidText, err
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid translator '%s'", translator)
+	}
+}
+
+// netsnmpLookup translates the OID with the net-snmp tools, like the snmp
+// input.
+func netsnmpLookup(oid string) (string, string, error) {
+	mibName, _, oidText, _, err := mib.NetsnmpTranslate(oid)
+	return mibName, oidText, err
+}
+
+func init() {
+	inputs.Add("snmp_trap", func() telegraf.Input {
+		return &SnmpTrap{
+			ServiceAddress: "udp://:162",
+			Path:           []string{"/usr/share/snmp/mibs"},
+		}
+	})
+}
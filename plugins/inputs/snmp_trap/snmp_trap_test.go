@@ -0,0 +1,557 @@
+package snmp_trap
+
+import (
+	"encoding/hex"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/soniah/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testMIB = map[string][2]string{
+	".1.3.6.1.2.1.1.3.0":        {"DISMAN-EVENT-MIB", "sysUpTimeInstance"},
+	".1.3.6.1.6.3.1.1.5.1":      {"SNMPv2-MIB", "coldStart"},
+	".1.3.6.1.6.3.1.1.5.4":      {"IF-MIB", "linkUp"},
+	".1.3.6.1.2.1.2.2.1.1.3":    {"IF-MIB", "ifIndex.3"},
+	".1.3.6.1.2.1.2.2.1.2.3":    {"IF-MIB", "ifDescr.3"},
+	".1.3.6.1.2.1.2.2.1.10.3":   {"IF-MIB", "ifInOctets.3"},
+	".1.3.6.1.2.1.31.1.1.1.6.3": {"IF-MIB", "ifHCInOctets.3"},
+	".1.3.6.1.2.1.4.20.1.1.1":   {"IP-MIB", "ipAdEntAddr.1"},
+	".1.3.6.1.2.1.2.2.1.22.3":   {"IF-MIB", "ifSpecific.3"},
+}
+
+func testLookup(oid string) (string, string, error) {
+	if e, ok := testMIB[oid]; ok {
+		return e[0], e[1], nil
+	}
+	return "", oid, nil
+}
+
+// newTestSnmpTrap starts the input on a random local port, returning the
+// connection to the port.
+func newTestSnmpTrap(t *testing.T, acc *testutil.Accumulator, s *SnmpTrap) net.Conn {
+	s.ServiceAddress = "udp://127.0.0.1:0"
+	if s.lookup == nil {
+		s.lookup = testLookup
+	}
+	require.NoError(t, s.Start(acc))
+
+	conn, err := net.Dial("udp", s.conn.LocalAddr().String())
+	require.NoError(t, err)
+	return conn
+}
+
+// tlv encodes a BER type-length-value.
+func tlv(tag byte, values ...[]byte) []byte {
+	var value []byte
+	for _, v := range values {
+		value = append(value, v...)
+	}
+	b := []byte{tag}
+	if len(value) < 0x80 {
+		b = append(b, byte(len(value)))
+	} else {
+		b = append(b, 0x82, byte(len(value)>>8), byte(len(value)))
+	}
+	return append(b, value...)
+}
+
+func integer(v byte) []byte {
+	return tlv(tagInteger, []byte{v})
+}
+
+// oid encodes a numeric OID.
+func oid(s string) []byte {
+	var ids []uint64
+	for _, id := range strings.Split(strings.TrimPrefix(s, "."), ".") {
+		v, _ := strconv.ParseUint(id, 10, 64)
+		ids = append(ids, v)
+	}
+	ids = append([]uint64{ids[0]*40 + ids[1]}, ids[2:]...)
+
+	var b []byte
+	for _, id := range ids {
+		enc := []byte{byte(id & 0x7f)}
+		for id >>= 7; id > 0; id >>= 7 {
+			enc = append([]byte{byte(id&0x7f) | 0x80}, enc...)
+		}
+		b = append(b, enc...)
+	}
+	return tlv(tagObjectIdentifier, b)
+}
+
+func vb(name string, value []byte) []byte {
+	return tlv(tagSequence, oid(name), value)
+}
+
+// linkUp returns the varbinds of a linkUp notification.
+func linkUp() []byte {
+	return tlv(tagSequence,
+		vb(".1.3.6.1.2.1.1.3.0", tlv(tagTimeTicks, []byte{0x04, 0xd2})),
+		vb(".1.3.6.1.6.3.1.1.4.1.0", oid(".1.3.6.1.6.3.1.1.5.4")),
+		vb(".1.3.6.1.2.1.2.2.1.1.3", integer(3)),
+		vb(".1.3.6.1.2.1.2.2.1.2.3", tlv(tagOctetString, []byte("eth0"))),
+		vb(".1.3.6.1.2.1.2.2.1.10.3", tlv(tagCounter32, []byte{0x00, 0xee, 0x6b, 0x28, 0x00})),
+		vb(".1.3.6.1.2.1.31.1.1.1.6.3", tlv(tagCounter64, []byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00})),
+		vb(".1.3.6.1.2.1.4.20.1.1.1", tlv(tagIPAddress, []byte{192, 168, 1, 1})),
+		vb(".1.3.6.1.2.1.2.2.1.22.3", oid(".1.3.6.1.6.3.1.1.5.1")),
+		vb(".1.3.6.1.2.1.2.2.1.3.3", tlv(tagNull)),
+	)
+}
+
+func linkUpFields() map[string]interface{} {
+	return map[string]interface{}{
+		"sysUpTimeInstance": uint64(1234),
+		"ifIndex.3":         int64(3),
+		"ifDescr.3":         "eth0",
+		"ifInOctets.3":      uint64(4000000000),
+		"ifHCInOctets.3":    uint64(1) << 40,
+		"ipAdEntAddr.1":     "192.168.1.1",
+		"ifSpecific.3":      "coldStart",
+	}
+}
+
+func linkUpTags(version string) map[string]string {
+	return map[string]string{
+		"source":  "127.0.0.1",
+		"version": version,
+		"oid":     ".1.3.6.1.6.3.1.1.5.4",
+		"name":    "linkUp",
+		"mib":     "IF-MIB",
+	}
+}
+
+func TestReceiveTrapV2c(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	s := &SnmpTrap{}
+	conn := newTestSnmpTrap(t, acc, s)
+	defer s.Stop()
+	defer conn.Close()
+
+	_, err := conn.Write(tlv(tagSequence,
+		integer(1),
+		tlv(tagOctetString, []byte("public")),
+		tlv(pduTrapV2, integer(42), integer(0), integer(0), linkUp()),
+	))
+	require.NoError(t, err)
+
+	acc.Wait(1)
+	require.Empty(t, acc.Errors)
+	acc.AssertContainsTaggedFields(t, "snmp_trap", linkUpFields(), linkUpTags("2c"))
+}
+
+func TestReceiveTrapV3(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	s := &SnmpTrap{SecName: "myuser"}
+	conn := newTestSnmpTrap(t, acc, s)
+	defer s.Stop()
+	defer conn.Close()
+
+	engineID := []byte("\x80\x00\x1f\x88\x04test")
+	_, err := conn.Write(tlv(tagSequence,
+		integer(3),
+		tlv(tagSequence,
+			integer(1),
+			tlv(tagInteger, []byte{0x00, 0xff, 0xe3}),
+			tlv(tagOctetString, []byte{0}),
+			integer(userSecurityModel),
+		),
+		tlv(tagOctetString, tlv(tagSequence,
+			tlv(tagOctetString, engineID),
+			integer(1),
+			integer(100),
+			tlv(tagOctetString, []byte("myuser")),
+			tlv(tagOctetString),
+			tlv(tagOctetString),
+		)),
+		tlv(tagSequence,
+			tlv(tagOctetString, engineID),
+			tlv(tagOctetString),
+			tlv(pduTrapV2, integer(42), integer(0), integer(0), linkUp()),
+		),
+	))
+	require.NoError(t, err)
+
+	acc.Wait(1)
+	require.Empty(t, acc.Errors)
+	acc.AssertContainsTaggedFields(t, "snmp_trap", linkUpFields(), linkUpTags("3"))
+}
+
+// captureRequest returns the message of a get request sent by gosnmp, which
+// does not send SNMPv3 traps.
+func captureRequest(t *testing.T, g *gosnmp.GoSNMP) []byte {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	g.Target = "127.0.0.1"
+	g.Port = uint16(conn.LocalAddr().(*net.UDPAddr).Port)
+	g.Timeout = 100 * time.Millisecond
+	require.NoError(t, g.Connect())
+	defer g.Conn.Close()
+	go g.Get([]string{".1.3.6.1.2.1.1.3.0"})
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, maxPacketSize)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	return buf[:n]
+}
+
+func TestUSMDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		secLevel string
+		msgFlags gosnmp.SnmpV3MsgFlags
+		auth     gosnmp.SnmpV3AuthProtocol
+		priv     gosnmp.SnmpV3PrivProtocol
+	}{
+		{"noAuthNoPriv", "noAuthNoPriv", gosnmp.NoAuthNoPriv, gosnmp.NoAuth, gosnmp.NoPriv},
+		{"MD5", "authNoPriv", gosnmp.AuthNoPriv, gosnmp.MD5, gosnmp.NoPriv},
+		{"SHA", "authNoPriv", gosnmp.AuthNoPriv, gosnmp.SHA, gosnmp.NoPriv},
+		{"MD5 DES", "authPriv", gosnmp.AuthPriv, gosnmp.MD5, gosnmp.DES},
+		{"SHA DES", "authPriv", gosnmp.AuthPriv, gosnmp.SHA, gosnmp.DES},
+		{"MD5 AES", "authPriv", gosnmp.AuthPriv, gosnmp.MD5, gosnmp.AES},
+		{"SHA AES", "authPriv", gosnmp.AuthPriv, gosnmp.SHA, gosnmp.AES},
+	}
+
+	protocols := map[gosnmp.SnmpV3AuthProtocol]string{gosnmp.MD5: "MD5", gosnmp.SHA: "SHA"}
+	privProtocols := map[gosnmp.SnmpV3PrivProtocol]string{gosnmp.DES: "DES", gosnmp.AES: "AES"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packet := captureRequest(t, &gosnmp.GoSNMP{
+				Version:       gosnmp.Version3,
+				MsgFlags:      tt.msgFlags,
+				SecurityModel: gosnmp.UserSecurityModel,
+				SecurityParameters: &gosnmp.UsmSecurityParameters{
+					AuthoritativeEngineID:    "\x80\x00\x1f\x88\x04test",
+					AuthoritativeEngineBoots: 1,
+					AuthoritativeEngineTime:  1000,
+					UserName:                 "myuser",
+					AuthenticationProtocol:   tt.auth,
+					AuthenticationPassphrase: "authpassword",
+					PrivacyProtocol:          tt.priv,
+					PrivacyPassphrase:        "privpassword",
+				},
+			})
+
+			u, err := newUSM("myuser", tt.secLevel, protocols[tt.auth], "authpassword", privProtocols[tt.priv], "privpassword")
+			require.NoError(t, err)
+
+			d := newDecoder(element{value: packet})
+			msg, err := d.expect(tagSequence)
+			require.NoError(t, err)
+			d = newDecoder(msg)
+			_, err = d.readInt()
+			require.NoError(t, err)
+
+			pdu, err := u.decode(packet, d)
+			require.NoError(t, err)
+			assert.Equal(t, byte(0xa0), pdu.tag)
+
+			// request-id, error-status and error-index, then the varbinds
+			d = newDecoder(pdu)
+			for i := 0; i < 3; i++ {
+				_, err := d.readInt()
+				require.NoError(t, err)
+			}
+			vbl, err := d.expect(tagSequence)
+			require.NoError(t, err)
+			vb, err := newDecoder(vbl).expect(tagSequence)
+			require.NoError(t, err)
+			name, err := newDecoder(vb).readOID()
+			require.NoError(t, err)
+			assert.Equal(t, ".1.3.6.1.2.1.1.3.0", name)
+		})
+	}
+}
+
+func TestUSMDecodeErrors(t *testing.T) {
+	packet := captureRequest(t, &gosnmp.GoSNMP{
+		Version:       gosnmp.Version3,
+		MsgFlags:      gosnmp.AuthNoPriv,
+		SecurityModel: gosnmp.UserSecurityModel,
+		SecurityParameters: &gosnmp.UsmSecurityParameters{
+			AuthoritativeEngineID:    "\x80\x00\x1f\x88\x04test",
+			UserName:                 "myuser",
+			AuthenticationProtocol:   gosnmp.SHA,
+			AuthenticationPassphrase: "authpassword",
+		},
+	})
+
+	tests := []struct {
+		name     string
+		secName  string
+		secLevel string
+		password string
+		err      string
+	}{
+		{"wrong password", "myuser", "authNoPriv", "wrongpassword", "authentication failed"},
+		{"unknown user", "otheruser", "authNoPriv", "authpassword", "unknown user"},
+		{"security level", "myuser", "authPriv", "authpassword", "lower than sec_level"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := newUSM(tt.secName, tt.secLevel, "SHA", tt.password, "AES", "privpassword")
+			require.NoError(t, err)
+			_, err = parse(packet, u)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestUSMDefaultSecLevel(t *testing.T) {
+	packet := captureRequest(t, &gosnmp.GoSNMP{
+		Version:       gosnmp.Version3,
+		MsgFlags:      gosnmp.NoAuthNoPriv,
+		SecurityModel: gosnmp.UserSecurityModel,
+		SecurityParameters: &gosnmp.UsmSecurityParameters{
+			AuthoritativeEngineID: "\x80\x00\x1f\x88\x04test",
+			UserName:              "myuser",
+		},
+	})
+
+	// the authentication settings require authenticated messages
+	u, err := newUSM("myuser", "", "SHA", "authpassword", "", "")
+	require.NoError(t, err)
+	assert.True(t, u.authRequired)
+	assert.False(t, u.privRequired)
+	_, err = parse(packet, u)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "lower than sec_level")
+
+	u, err = newUSM("myuser", "", "SHA", "authpassword", "AES", "privpassword")
+	require.NoError(t, err)
+	assert.True(t, u.authRequired)
+	assert.True(t, u.privRequired)
+
+	u, err = newUSM("myuser", "", "", "", "", "")
+	require.NoError(t, err)
+	assert.False(t, u.authRequired)
+	// the message is accepted, but the get request is not a notification
+	_, err = parse(packet, u)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported PDU type")
+}
+
+func TestReceiveTrapV1(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	s := &SnmpTrap{}
+	conn := newTestSnmpTrap(t, acc, s)
+	defer s.Stop()
+	defer conn.Close()
+
+	tests := []struct {
+		generic  byte
+		specific byte
+		oid      string
+		name     string
+	}{
+		{3, 0, ".1.3.6.1.6.3.1.1.5.4", "linkUp"},
+		{6, 17, ".1.3.6.1.4.1.9999.0.17", ".1.3.6.1.4.1.9999.0.17"},
+	}
+
+	for _, tt := range tests {
+		packet := tlv(tagSequence,
+			integer(0),
+			tlv(tagOctetString, []byte("public")),
+			tlv(pduTrapV1,
+				oid(".1.3.6.1.4.1.9999"),
+				tlv(tagIPAddress, []byte{10, 0, 0, 1}),
+				integer(tt.generic),
+				integer(tt.specific),
+				tlv(tagTimeTicks, []byte{0x04, 0xd2}),
+				tlv(tagSequence, vb(".1.3.6.1.2.1.2.2.1.1.3", integer(3))),
+			),
+		)
+		_, err := conn.Write(packet)
+		require.NoError(t, err)
+	}
+
+	acc.Wait(2)
+	require.Empty(t, acc.Errors)
+	for i, tt := range tests {
+		m := acc.Metrics[i]
+		assert.Equal(t, "snmp_trap", m.Measurement)
+		assert.Equal(t, map[string]string{
+			"source":  "127.0.0.1",
+			"version": "1",
+			"oid":     tt.oid,
+			"name":    tt.name,
+		}, filterMIB(m.Tags))
+		assert.Equal(t, map[string]interface{}{
+			"sysUpTimeInstance": uint64(1234),
+			"ifIndex.3":         int64(3),
+			"agent_address":     "10.0.0.1",
+		}, m.Fields)
+	}
+}
+
+func TestReceiveTrapNativeTranslator(t *testing.T) {
+	lookup, err := newLookup("native", []string{"../../../internal/snmp/testdata"})
+	require.NoError(t, err)
+
+	acc := &testutil.Accumulator{}
+	s := &SnmpTrap{lookup: lookup}
+	conn := newTestSnmpTrap(t, acc, s)
+	defer s.Stop()
+	defer conn.Close()
+
+	packet := tlv(tagSequence,
+		integer(0),
+		tlv(tagOctetString, []byte("public")),
+		tlv(pduTrapV1,
+			oid(".1.3.6.1.4.1.9999"),
+			tlv(tagIPAddress, []byte{10, 0, 0, 1}),
+			integer(6),
+			integer(17),
+			tlv(tagTimeTicks, []byte{0x04, 0xd2}),
+			tlv(tagSequence,
+				vb(".1.3.6.1.2.1.2.2.1.1.3", integer(3)),
+				vb(".1.3.6.1.4.1.9999.1.0", tlv(tagOctetString, []byte("alarm")))),
+		),
+	)
+	_, err = conn.Write(packet)
+	require.NoError(t, err)
+
+	acc.Wait(1)
+	require.Empty(t, acc.Errors)
+	m := acc.Metrics[0]
+	assert.Equal(t, map[string]string{
+		"source":  "127.0.0.1",
+		"version": "1",
+		"oid":     ".1.3.6.1.4.1.9999.0.17",
+		"name":    "testAlarm",
+		"mib":     "TEST-TRAP-MIB",
+	}, m.Tags)
+	assert.Equal(t, map[string]interface{}{
+		"sysUpTime.0":   uint64(1234),
+		"ifIndex.3":     int64(3),
+		"testMessage.0": "alarm",
+		"agent_address": "10.0.0.1",
+	}, m.Fields)
+}
+
+func TestNewLookupInvalidTranslator(t *testing.T) {
+	_, err := newLookup("foo", nil)
+	require.Error(t, err)
+}
+
+func filterMIB(tags map[string]string) map[string]string {
+	filtered := make(map[string]string)
+	for k, v := range tags {
+		if k != "mib" {
+			filtered[k] = v
+		}
+	}
+	return filtered
+}
+
+func TestReceiveInformV2c(t *testing.T) {
+	acc := &testutil.Accumulator{}
+	s := &SnmpTrap{}
+	conn := newTestSnmpTrap(t, acc, s)
+	defer s.Stop()
+	defer conn.Close()
+
+	packet := tlv(tagSequence,
+		integer(1),
+		tlv(tagOctetString, []byte("public")),
+		tlv(pduInform,
+			integer(42),
+			integer(0),
+			integer(0),
+			tlv(tagSequence,
+				vb(".1.3.6.1.2.1.1.3.0", tlv(tagTimeTicks, []byte{0x04, 0xd2})),
+				vb(".1.3.6.1.6.3.1.1.4.1.0", oid(".1.3.6.1.6.3.1.1.5.1")),
+			),
+		),
+	)
+	_, err := conn.Write(packet)
+	require.NoError(t, err)
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	resp := make([]byte, 1024)
+	n, err := conn.Read(resp)
+	require.NoError(t, err)
+	expected := append([]byte(nil), packet...)
+	expected[13] = pduGetResponse
+	assert.Equal(t, expected, resp[:n])
+
+	acc.Wait(1)
+	acc.AssertContainsTaggedFields(t, "snmp_trap", map[string]interface{}{
+		"sysUpTimeInstance": uint64(1234),
+	}, map[string]string{
+		"source":  "127.0.0.1",
+		"version": "2c",
+		"oid":     ".1.3.6.1.6.3.1.1.5.1",
+		"name":    "coldStart",
+		"mib":     "SNMPv2-MIB",
+	})
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		packet []byte
+		err    string
+	}{
+		{"empty", []byte{}, "truncated packet"},
+		{"truncated", []byte{0x30, 0x10, 0x02}, "truncated packet"},
+		{"version", tlv(tagSequence, integer(2)), "unsupported SNMP version 2"},
+		{"v3 disabled", tlv(tagSequence, integer(3)), "SNMPv3 is not enabled"},
+		{
+			"get request",
+			tlv(tagSequence, integer(1), tlv(tagOctetString), tlv(0xa0, integer(1), integer(0), integer(0), tlv(tagSequence))),
+			"unsupported PDU type 0xa0",
+		},
+		{
+			"no trap oid",
+			tlv(tagSequence, integer(1), tlv(tagOctetString), tlv(pduTrapV2, integer(1), integer(0), integer(0), tlv(tagSequence))),
+			"notification without snmpTrapOID.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse(tt.packet, nil)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestDecodeOID(t *testing.T) {
+	oid, err := decodeOID([]byte{0x2b, 6, 1, 4, 1, 0x82, 0x37, 0x8f, 0xff, 0x7f})
+	require.NoError(t, err)
+	assert.Equal(t, ".1.3.6.1.4.1.311.262143", oid)
+
+	_, err = decodeOID([]byte{0x2b, 0x82})
+	assert.Error(t, err)
+}
+
+func TestPasswordToKey(t *testing.T) {
+	// RFC 3414 appendix A.3
+	engineID, _ := hex.DecodeString("000000000000000000000002")
+	tests := []struct {
+		protocol  string
+		key       string
+		localized string
+	}{
+		{"MD5", "9faf3283884e92834ebc9847d8edd963", "526f5eed9fcce26f8964c2930787d82b"},
+		{"SHA", "9fb5cc0381497b3793528939ff788d5d79145211", "6695febc9288e36282235fc7151f128497b38f3f"},
+	}
+	for _, tt := range tests {
+		u, err := newUSM("user", "authNoPriv", tt.protocol, "maplesyrup", "", "")
+		require.NoError(t, err)
+		assert.Equal(t, tt.key, hex.EncodeToString(u.authKey), tt.protocol)
+		assert.Equal(t, tt.localized, hex.EncodeToString(u.localize(u.authKey, engineID)), tt.protocol)
+	}
+}
@@ -0,0 +1,309 @@
+package snmp_trap
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+const (
+	// security model of the SNMPv3 messages
+	userSecurityModel = 3
+
+	// msgFlags of the SNMPv3 messages
+	flagAuth = 0x01
+	flagPriv = 0x02
+
+	// length of the truncated HMAC of HMAC-MD5-96 and HMAC-SHA-96
+	authParamsLen = 12
+)
+
+// usm is the user-based security model of RFC 3414, which authenticates and
+// decrypts the SNMPv3 notifications of a user.  As the sender of a
+// notification is the authoritative engine, the keys are localized with the
+// engine ID of each message.
+type usm struct {
+	secName      string
+	authRequired bool
+	privRequired bool
+
+	hash         func() hash.Hash
+	privProtocol string
+
+	// keys derived from the passwords, before localization
+	authKey []byte
+	privKey []byte
+}
+
+// newUSM returns the security model of the user, whose messages must have at
+// least the security level.  Without security level, the messages must be
+// authenticated if the authentication protocol is set, and encrypted if the
+// privacy protocol is set.
+func newUSM(secName, secLevel, authProtocol, authPassword, privProtocol, privPassword string) (*usm, error) {
+	u := &usm{secName: secName}
+
+	if secLevel == "" {
+		switch {
+		case privProtocol != "":
+			secLevel = "authPriv"
+		case authProtocol != "":
+			secLevel = "authNoPriv"
+		default:
+			secLevel = "noAuthNoPriv"
+		}
+	}
+
+	switch strings.ToLower(secLevel) {
+	case "noauthnopriv":
+	case "authnopriv":
+		u.authRequired = true
+	case "authpriv":
+		u.authRequired = true
+		u.privRequired = true
+	default:
+		return nil, fmt.Errorf("invalid sec_level %q", secLevel)
+	}
+
+	switch strings.ToLower(authProtocol) {
+	case "md5":
+		u.hash = md5.New
+	case "sha":
+		u.hash = sha1.New
+	case "":
+		if u.authRequired {
+			return nil, errors.New("auth_protocol is required for sec_level " + secLevel)
+		}
+		return u, nil
+	default:
+		return nil, fmt.Errorf("invalid auth_protocol %q", authProtocol)
+	}
+	if authPassword == "" {
+		return nil, errors.New("auth_password is required with auth_protocol")
+	}
+	u.authKey = passwordToKey(u.hash, authPassword)
+
+	switch strings.ToLower(privProtocol) {
+	case "des", "aes":
+		u.privProtocol = strings.ToLower(privProtocol)
+	case "":
+		if u.privRequired {
+			return nil, errors.New("priv_protocol is required for sec_level " + secLevel)
+		}
+		return u, nil
+	default:
+		return nil, fmt.Errorf("invalid priv_protocol %q", privProtocol)
+	}
+	if privPassword == "" {
+		return nil, errors.New("priv_password is required with priv_protocol")
+	}
+	u.privKey = passwordToKey(u.hash, privPassword)
+
+	return u, nil
+}
+
+// decode authenticates and decrypts the SNMPv3 message, whose version was
+// read by the decoder, and returns its PDU.
+func (u *usm) decode(packet []byte, d *decoder) (element, error) {
+	global, err := d.expect(tagSequence)
+	if err != nil {
+		return element{}, err
+	}
+	gd := newDecoder(global)
+	// msgID and msgMaxSize
+	for i := 0; i < 2; i++ {
+		if _, err := gd.readInt(); err != nil {
+			return element{}, err
+		}
+	}
+	flags, err := gd.readBytes()
+	if err != nil {
+		return element{}, err
+	}
+	if len(flags) != 1 {
+		return element{}, fmt.Errorf("invalid msgFlags of %d bytes", len(flags))
+	}
+	model, err := gd.readInt()
+	if err != nil {
+		return element{}, err
+	}
+	if model != userSecurityModel {
+		return element{}, fmt.Errorf("unsupported security model %d", model)
+	}
+
+	params, err := d.expect(tagOctetString)
+	if err != nil {
+		return element{}, err
+	}
+	sp, err := newDecoder(params).expect(tagSequence)
+	if err != nil {
+		return element{}, err
+	}
+	sd := newDecoder(sp)
+	engineID, err := sd.readBytes()
+	if err != nil {
+		return element{}, err
+	}
+	boots, err := sd.readInt()
+	if err != nil {
+		return element{}, err
+	}
+	engineTime, err := sd.readInt()
+	if err != nil {
+		return element{}, err
+	}
+	userName, err := sd.readBytes()
+	if err != nil {
+		return element{}, err
+	}
+	authParams, err := sd.expect(tagOctetString)
+	if err != nil {
+		return element{}, err
+	}
+	privParams, err := sd.readBytes()
+	if err != nil {
+		return element{}, err
+	}
+
+	if string(userName) != u.secName {
+		return element{}, fmt.Errorf("unknown user %q", userName)
+	}
+	auth := flags[0]&flagAuth != 0
+	priv := flags[0]&flagPriv != 0
+	if (u.authRequired && !auth) || (u.privRequired && !priv) {
+		return element{}, errors.New("security level of the message is lower than sec_level")
+	}
+	if priv && !auth {
+		return element{}, errors.New("invalid msgFlags, privacy without authentication")
+	}
+
+	if auth {
+		if u.authKey == nil {
+			return element{}, errors.New("authenticated message but auth_protocol is not set")
+		}
+		if err := u.authenticate(packet, authParams, u.localize(u.authKey, engineID)); err != nil {
+			return element{}, err
+		}
+	}
+
+	data, err := d.read()
+	if err != nil {
+		return element{}, err
+	}
+	if priv {
+		if u.privKey == nil {
+			return element{}, errors.New("encrypted message but priv_protocol is not set")
+		}
+		if data.tag != tagOctetString {
+			return element{}, fmt.Errorf("unexpected tag 0x%02x of encrypted PDU", data.tag)
+		}
+		plain, err := u.decrypt(data.value, u.localize(u.privKey, engineID), uint32(boots), uint32(engineTime), privParams)
+		if err != nil {
+			return element{}, err
+		}
+		// the decrypted scoped PDU may be padded
+		data, err = newDecoder(element{value: plain}).read()
+		if err != nil {
+			return element{}, err
+		}
+	}
+	if data.tag != tagSequence {
+		return element{}, fmt.Errorf("unexpected tag 0x%02x of scoped PDU", data.tag)
+	}
+
+	pd := newDecoder(data)
+	// contextEngineID and contextName
+	for i := 0; i < 2; i++ {
+		if _, err := pd.readBytes(); err != nil {
+			return element{}, err
+		}
+	}
+	return pd.read()
+}
+
+// authenticate checks the HMAC of the message, computed with its
+// authentication parameters zeroed.
+func (u *usm) authenticate(packet []byte, authParams element, key []byte) error {
+	if len(authParams.value) != authParamsLen {
+		return fmt.Errorf("invalid authentication parameters of %d bytes", len(authParams.value))
+	}
+
+	msg := make([]byte, len(packet))
+	copy(msg, packet)
+	for i := 0; i < authParamsLen; i++ {
+		msg[authParams.offset+i] = 0
+	}
+
+	mac := hmac.New(u.hash, key)
+	mac.Write(msg)
+	if !hmac.Equal(mac.Sum(nil)[:authParamsLen], authParams.value) {
+		return errors.New("authentication failed, wrong digest")
+	}
+	return nil
+}
+
+// decrypt decrypts the scoped PDU with CBC-DES (RFC 3414) or CFB128-AES-128
+// (RFC 3826).
+func (u *usm) decrypt(data []byte, key []byte, boots, engineTime uint32, privParams []byte) ([]byte, error) {
+	if len(privParams) != 8 {
+		return nil, fmt.Errorf("invalid privacy parameters of %d bytes", len(privParams))
+	}
+
+	plain := make([]byte, len(data))
+	switch u.privProtocol {
+	case "des":
+		if len(data)%des.BlockSize != 0 {
+			return nil, fmt.Errorf("invalid length %d of DES encrypted PDU", len(data))
+		}
+		block, err := des.NewCipher(key[:8])
+		if err != nil {
+			return nil, err
+		}
+		iv := make([]byte, des.BlockSize)
+		for i := range iv {
+			iv[i] = key[8+i] ^ privParams[i]
+		}
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+	case "aes":
+		block, err := aes.NewCipher(key[:16])
+		if err != nil {
+			return nil, err
+		}
+		iv := make([]byte, aes.BlockSize)
+		binary.BigEndian.PutUint32(iv, boots)
+		binary.BigEndian.PutUint32(iv[4:], engineTime)
+		copy(iv[8:], privParams)
+		cipher.NewCFBDecrypter(block, iv).XORKeyStream(plain, data)
+	}
+	return plain, nil
+}
+
+// localize localizes the key to the engine, see RFC 3414 section 2.6.
+func (u *usm) localize(key []byte, engineID []byte) []byte {
+	h := u.hash()
+	h.Write(key)
+	h.Write(engineID)
+	h.Write(key)
+	return h.Sum(nil)
+}
+
+// passwordToKey derives the key of the password, hashing one megabyte of the
+// repeated password, see RFC 3414 appendix A.2.
+func passwordToKey(hashFunc func() hash.Hash, password string) []byte {
+	h := hashFunc()
+	buf := make([]byte, 64)
+	for i := 0; i < 1048576; i += len(buf) {
+		for j := range buf {
+			buf[j] = password[(i+j)%len(password)]
+		}
+		h.Write(buf)
+	}
+	return h.Sum(nil)
+}
@@ -2,6 +2,7 @@ package socket_listener
 
 import (
 	"bufio"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"log"
@@ -26,7 +27,8 @@ type streamSocketListener struct {
 	net.Listener
 	*SocketListener
 
-	sockType string
+	sockType  string
+	tlsConfig *tls.Config
 
 	connections    map[string]net.Conn
 	connectionsMtx sync.Mutex
@@ -65,6 +67,10 @@ func (ssl *streamSocketListener) listen() {
 			ssl.AddError(fmt.Errorf("unable to configure keep alive (%s): %s", ssl.ServiceAddress, err))
 		}
 
+		if ssl.tlsConfig != nil {
+			c = tls.Server(c, ssl.tlsConfig)
+		}
+
 		go ssl.read(c)
 	}
 
@@ -122,7 +128,7 @@ func (ssl *streamSocketListener) read(c net.Conn) {
 	}
 
 	if err := scnr.Err(); err != nil {
-		if err, ok := err.(net.Error); ok && err.Timeout() {
+		if nerr, ok := err.(net.Error); ok && nerr.Timeout() {
 			log.Printf("D! Timeout in plugin [input.socket_listener]: %s", err)
 		} else if !strings.HasSuffix(err.Error(), ": use of closed network connection") {
 			ssl.AddError(err)
@@ -165,6 +171,11 @@ type SocketListener struct {
 	ReadTimeout     *internal.Duration
 	KeepAlivePeriod *internal.Duration
 
+	TlsAllowedCacerts []string
+	TlsAllowedCns     []string
+	TlsCert           string
+	TlsKey            string
+
 	parsers.Parser
 	telegraf.Accumulator
 	io.Closer
@@ -210,6 +221,19 @@ func (sl *SocketListener) SampleConfig() string {
   ## Defaults to the OS configuration.
   # keep_alive_period = "5m"
 
+  ## Set the service certificate and key to enable TLS.
+  ## Only applies to stream sockets (e.g. TCP).
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+
+  ## Set one or more allowed client CA certificate file names to
+  ## enable mutually authenticated TLS connections.
+  # tls_allowed_cacerts = ["/etc/telegraf/clientca.pem"]
+
+  ## Optional list of common names allowed in client certificates, requires
+  ## tls_allowed_cacerts.
+  # tls_allowed_cns = ["telegraf"]
+
   ## Data format to consume.
   ## Each data format has its own unique set of configuration options, read
   ## more about them here:
@@ -240,6 +264,12 @@ func (sl *SocketListener) Start(acc telegraf.Accumulator) error {
 		os.Remove(spl[1])
 	}
 
+	tlsConfig, err := internal.GetServerTLSConfig(sl.TlsCert, sl.TlsKey,
+		sl.TlsAllowedCacerts, sl.TlsAllowedCns)
+	if err != nil {
+		return err
+	}
+
 	switch spl[0] {
 	case "tcp", "tcp4", "tcp6", "unix", "unixpacket":
 		l, err := net.Listen(spl[0], spl[1])
@@ -251,11 +281,16 @@ func (sl *SocketListener) Start(acc telegraf.Accumulator) error {
 			Listener:       l,
 			SocketListener: sl,
 			sockType:       spl[0],
+			tlsConfig:      tlsConfig,
 		}
 
 		sl.Closer = ssl
 		go ssl.listen()
 	case "udp", "udp4", "udp6", "ip", "ip4", "ip6", "unixgram":
+		if tlsConfig != nil {
+			return fmt.Errorf("TLS is not supported on %s sockets", spl[0])
+		}
+
 		pc, err := net.ListenPacket(spl[0], spl[1])
 		if err != nil {
 			return err
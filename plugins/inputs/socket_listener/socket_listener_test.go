@@ -2,6 +2,7 @@ package socket_listener
 
 import (
 	"bytes"
+	"crypto/tls"
 	"log"
 	"net"
 	"os"
@@ -43,6 +44,55 @@ func TestSocketListener_tcp(t *testing.T) {
 	testSocketListener(t, sl, client)
 }
 
+func TestSocketListener_tls(t *testing.T) {
+	defer testEmptyLog(t)()
+
+	sl := newSocketListener()
+	sl.ServiceAddress = "tcp://127.0.0.1:0"
+	sl.TlsCert = testutil.TLSFile("servercert.pem")
+	sl.TlsKey = testutil.TLSFile("serverkey.pem")
+	sl.TlsAllowedCacerts = []string{testutil.TLSFile("cacert.pem")}
+	sl.TlsAllowedCns = []string{"telegraf"}
+
+	acc := &testutil.Accumulator{}
+	err := sl.Start(acc)
+	require.NoError(t, err)
+	defer sl.Stop()
+
+	tlsConfig, err := testutil.TLSClientConfig()
+	require.NoError(t, err)
+
+	client, err := tls.Dial("tcp", sl.Closer.(net.Listener).Addr().String(), tlsConfig)
+	require.NoError(t, err)
+
+	testSocketListener(t, sl, client)
+}
+
+func TestSocketListener_tlsCommonNameNotAllowed(t *testing.T) {
+	sl := newSocketListener()
+	sl.ServiceAddress = "tcp://127.0.0.1:0"
+	sl.TlsCert = testutil.TLSFile("servercert.pem")
+	sl.TlsKey = testutil.TLSFile("serverkey.pem")
+	sl.TlsAllowedCacerts = []string{testutil.TLSFile("cacert.pem")}
+	sl.TlsAllowedCns = []string{"other"}
+
+	acc := &testutil.Accumulator{}
+	err := sl.Start(acc)
+	require.NoError(t, err)
+	defer sl.Stop()
+
+	tlsConfig, err := testutil.TLSClientConfig()
+	require.NoError(t, err)
+
+	client, err := tls.Dial("tcp", sl.Closer.(net.Listener).Addr().String(), tlsConfig)
+	require.NoError(t, err)
+	defer client.Close()
+	client.Write([]byte("test,foo=bar v=1i 123456789\n"))
+
+	acc.WaitError(1)
+	assert.Equal(t, 0, len(acc.Metrics))
+}
+
 func TestSocketListener_udp(t *testing.T) {
 	defer testEmptyLog(t)()
 
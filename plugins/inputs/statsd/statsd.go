@@ -3,6 +3,7 @@ package statsd
 import (
 	"bufio"
 	"bytes"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"log"
@@ -116,6 +117,13 @@ type Statsd struct {
 	TCPKeepAlive       bool               `toml:"tcp_keep_alive"`
 	TCPKeepAlivePeriod *internal.Duration `toml:"tcp_keep_alive_period"`
 
+	TLSAllowedCACerts []string `toml:"tls_allowed_cacerts"`
+	TLSAllowedCNs     []string `toml:"tls_allowed_cns"`
+	TLSCert           string   `toml:"tls_cert"`
+	TLSKey            string   `toml:"tls_key"`
+
+	tlsConfig *tls.Config
+
 	graphiteParser *graphite.GraphiteParser
 
 	acc telegraf.Accumulator
@@ -188,6 +196,19 @@ const sampleConfig = `
   ## Defaults to the OS configuration.
   # tcp_keep_alive_period = "2h"
 
+  ## Set the service certificate and key to enable TLS.
+  ## Only applies when protocol is set to tcp.
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+
+  ## Set one or more allowed client CA certificate file names to
+  ## enable mutually authenticated TLS connections.
+  # tls_allowed_cacerts = ["/etc/telegraf/clientca.pem"]
+
+  ## Optional list of common names allowed in client certificates, requires
+  ## tls_allowed_cacerts.
+  # tls_allowed_cns = ["telegraf"]
+
   ## Address and port to host UDP listener on
   service_address = ":8125"
 
@@ -295,6 +316,16 @@ func (s *Statsd) Gather(acc telegraf.Accumulator) error {
 }
 
 func (s *Statsd) Start(_ telegraf.Accumulator) error {
+	tlsConfig, err := internal.GetServerTLSConfig(s.TLSCert, s.TLSKey,
+		s.TLSAllowedCACerts, s.TLSAllowedCNs)
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil && s.isUDP() {
+		return fmt.Errorf("TLS is not supported with the %s protocol", s.Protocol)
+	}
+	s.tlsConfig = tlsConfig
+
 	// Make data structures
 	s.gauges = make(map[string]cachedgauge)
 	s.counters = make(map[string]cachedcounter)
@@ -391,7 +422,11 @@ func (s *Statsd) tcpListen() error {
 				// generate a random id for this TCPConn
 				id := internal.RandomString(6)
 				s.remember(id, conn)
-				go s.handler(conn, id)
+				if s.tlsConfig != nil {
+					go s.handler(tls.Server(conn, s.tlsConfig), id)
+				} else {
+					go s.handler(conn, id)
+				}
 			default:
 				// We are over the connection limit, refuse & close.
 				s.refuser(conn)
@@ -774,7 +809,7 @@ func (s *Statsd) aggregate(m metric) {
 }
 
 // handler handles a single TCP Connection
-func (s *Statsd) handler(conn *net.TCPConn, id string) {
+func (s *Statsd) handler(conn net.Conn, id string) {
 	s.CurrentConnections.Incr(1)
 	s.TotalConnections.Incr(1)
 	// connection cleanup function
@@ -2,6 +2,7 @@ package statsd
 
 import (
 	"bytes"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
@@ -125,6 +126,54 @@ func TestCloseConcurrentConns(t *testing.T) {
 	listener.Stop()
 }
 
+// Test that metrics are received over TLS from allowed clients
+func TestTCPTLS(t *testing.T) {
+	listener := Statsd{
+		Protocol:               "tcp",
+		ServiceAddress:         "localhost:8125",
+		AllowedPendingMessages: 10000,
+		MaxTCPConnections:      2,
+		TLSCert:                testutil.TLSFile("servercert.pem"),
+		TLSKey:                 testutil.TLSFile("serverkey.pem"),
+		TLSAllowedCACerts:      []string{testutil.TLSFile("cacert.pem")},
+		TLSAllowedCNs:          []string{"telegraf"},
+	}
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	tlsConfig, err := testutil.TLSClientConfig()
+	require.NoError(t, err)
+
+	time.Sleep(time.Millisecond * 25)
+	conn, err := tls.Dial("tcp", "127.0.0.1:8125", tlsConfig)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte(testMsg + "\n"))
+	require.NoError(t, err)
+
+	for i := 0; i < 100 && acc.NFields() == 0; i++ {
+		time.Sleep(time.Millisecond * 10)
+		require.NoError(t, listener.Gather(acc))
+	}
+	acc.AssertContainsFields(t, "test_tcp_msg",
+		map[string]interface{}{"value": int64(100)})
+}
+
+// Test that TLS is refused with UDP
+func TestUDPTLS(t *testing.T) {
+	listener := Statsd{
+		Protocol:       "udp",
+		ServiceAddress: "localhost:8125",
+		TLSCert:        testutil.TLSFile("servercert.pem"),
+		TLSKey:         testutil.TLSFile("serverkey.pem"),
+	}
+
+	acc := &testutil.Accumulator{}
+	assert.Error(t, listener.Start(acc))
+}
+
 // benchmark how long it takes to accept & process 100,000 metrics:
 func BenchmarkUDP(b *testing.B) {
 	listener := Statsd{
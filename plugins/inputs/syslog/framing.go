@@ -0,0 +1,85 @@
+package syslog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// maxMessageSize is the maximum size of a message, which is also the
+// maximum size of an UDP datagram.
+const maxMessageSize = 64 * 1024
+
+// frameReader returns the messages read from a stream socket one at a time,
+// and io.EOF at the end of the stream.
+type frameReader func() ([]byte, error)
+
+// newOctetCountingReader reads messages framed by octet counting as defined
+// by RFC5425 and RFC6587, each message is prefixed by its length:
+//
+//	MSG-LEN SP SYSLOG-MSG
+func newOctetCountingReader(r io.Reader) frameReader {
+	br := bufio.NewReader(r)
+	return func() ([]byte, error) {
+		var length int
+		for digits := 0; ; digits++ {
+			c, err := br.ReadByte()
+			if err == io.EOF && digits > 0 {
+				return nil, io.ErrUnexpectedEOF
+			}
+			if err != nil {
+				return nil, err
+			}
+			if c == ' ' && digits > 0 {
+				break
+			}
+			if !isDigit(c) || digits >= 6 {
+				return nil, fmt.Errorf("invalid octet count framing")
+			}
+			length = length*10 + int(c-'0')
+		}
+		if length > maxMessageSize {
+			return nil, fmt.Errorf("message of %d bytes exceeds the maximum size of %d bytes",
+				length, maxMessageSize)
+		}
+
+		buf := make([]byte, length)
+		if _, err := io.ReadFull(br, buf); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		return buf, nil
+	}
+}
+
+// newNonTransparentReader reads messages ended by the trailer as defined by
+// RFC6587, the trailer is not part of the message.
+func newNonTransparentReader(r io.Reader, trailer byte) frameReader {
+	scnr := bufio.NewScanner(r)
+	scnr.Buffer(make([]byte, 0, 4096), maxMessageSize)
+	scnr.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		if i := bytes.IndexByte(data, trailer); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	})
+
+	return func() ([]byte, error) {
+		if scnr.Scan() {
+			return bytes.TrimRight(scnr.Bytes(), "\r"), nil
+		}
+		if err := scnr.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+}
@@ -0,0 +1,315 @@
+package syslog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const nilValue = "-"
+
+// bom is the byte order mark which may prefix the UTF-8 message of RFC5424.
+var bom = []byte("\xEF\xBB\xBF")
+
+var errUnexpectedEnd = errors.New("unexpected end of message")
+
+// syslogMessage is a RFC5424 or RFC3164 syslog message, unset header fields
+// are empty.
+type syslogMessage struct {
+	facility  uint8
+	severity  uint8
+	version   int // 0 for RFC3164 messages
+	timestamp time.Time
+	hostname  string
+	appname   string
+	procID    string
+	msgID     string
+	sd        []sdElement
+	message   string
+}
+
+// sdElement is a structured data element.
+type sdElement struct {
+	id     string
+	params []sdParam
+}
+
+type sdParam struct {
+	name  string
+	value string
+}
+
+// parseMessage parses a RFC5424 message, or a RFC3164 message if there is
+// no version after the priority.  RFC3164 timestamps have no year, the year
+// is taken from now.
+func parseMessage(b []byte, now time.Time) (*syslogMessage, error) {
+	p := &parser{buf: b}
+
+	pri, err := p.priority()
+	if err != nil {
+		return nil, err
+	}
+	msg := &syslogMessage{
+		facility: uint8(pri / 8),
+		severity: uint8(pri % 8),
+	}
+
+	if version, ok := p.version(); ok {
+		msg.version = version
+		err = p.rfc5424(msg)
+	} else {
+		err = p.rfc3164(msg, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+type parser struct {
+	buf []byte
+	pos int
+}
+
+// priority reads the "<PRI>" prefix.
+func (p *parser) priority() (int, error) {
+	if p.pos >= len(p.buf) || p.buf[p.pos] != '<' {
+		return 0, fmt.Errorf("expected '<' at position %d", p.pos)
+	}
+	p.pos++
+
+	start := p.pos
+	for p.pos < len(p.buf) && p.pos-start < 3 && isDigit(p.buf[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start || p.pos >= len(p.buf) || p.buf[p.pos] != '>' {
+		return 0, fmt.Errorf("invalid priority at position %d", start)
+	}
+	pri, _ := strconv.Atoi(string(p.buf[start:p.pos]))
+	if pri > 191 {
+		return 0, fmt.Errorf("invalid priority %d", pri)
+	}
+	p.pos++
+	return pri, nil
+}
+
+// version reads the version followed by a space, it returns false and leaves
+// the position unchanged if there is none.
+func (p *parser) version() (int, bool) {
+	i := p.pos
+	for i < len(p.buf) && i-p.pos < 3 && isDigit(p.buf[i]) {
+		i++
+	}
+	if i == p.pos || i >= len(p.buf) || p.buf[i] != ' ' || p.buf[p.pos] == '0' {
+		return 0, false
+	}
+	version, _ := strconv.Atoi(string(p.buf[p.pos:i]))
+	p.pos = i + 1
+	return version, true
+}
+
+// rfc5424 parses the rest of a RFC5424 message:
+//
+//	TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]
+func (p *parser) rfc5424(msg *syslogMessage) error {
+	ts, err := p.field()
+	if err != nil {
+		return err
+	}
+	if ts != nilValue {
+		t, err := time.Parse(time.RFC3339Nano, ts)
+		if err != nil {
+			return fmt.Errorf("invalid timestamp %q", ts)
+		}
+		msg.timestamp = t
+	}
+
+	for _, f := range []*string{&msg.hostname, &msg.appname, &msg.procID, &msg.msgID} {
+		v, err := p.field()
+		if err != nil {
+			return err
+		}
+		if v != nilValue {
+			*f = v
+		}
+	}
+
+	if err := p.structuredData(msg); err != nil {
+		return err
+	}
+
+	if p.pos < len(p.buf) {
+		if p.buf[p.pos] != ' ' {
+			return fmt.Errorf("expected space at position %d", p.pos)
+		}
+		msg.message = string(bytes.TrimPrefix(p.buf[p.pos+1:], bom))
+	}
+	return nil
+}
+
+// field reads a header field followed by a space.
+func (p *parser) field() (string, error) {
+	i := bytes.IndexByte(p.buf[p.pos:], ' ')
+	if i < 0 {
+		return "", errUnexpectedEnd
+	}
+	if i == 0 {
+		return "", fmt.Errorf("empty field at position %d", p.pos)
+	}
+	v := string(p.buf[p.pos : p.pos+i])
+	p.pos += i + 1
+	return v, nil
+}
+
+// structuredData reads the nil value or the structured data elements:
+//
+//	[SD-ID SD-PARAM-NAME="SD-PARAM-VALUE" ...]...
+func (p *parser) structuredData(msg *syslogMessage) error {
+	if p.pos >= len(p.buf) {
+		return errUnexpectedEnd
+	}
+	if p.buf[p.pos] == '-' {
+		p.pos++
+		return nil
+	}
+	if p.buf[p.pos] != '[' {
+		return fmt.Errorf("invalid structured data at position %d", p.pos)
+	}
+
+	for p.pos < len(p.buf) && p.buf[p.pos] == '[' {
+		p.pos++
+		id, err := p.sdName()
+		if err != nil {
+			return err
+		}
+		elem := sdElement{id: id}
+
+		for {
+			if p.pos >= len(p.buf) {
+				return errUnexpectedEnd
+			}
+			if p.buf[p.pos] == ']' {
+				p.pos++
+				break
+			}
+			if p.buf[p.pos] != ' ' {
+				return fmt.Errorf("expected space at position %d", p.pos)
+			}
+			p.pos++
+
+			name, err := p.sdName()
+			if err != nil {
+				return err
+			}
+			if p.pos+1 >= len(p.buf) || p.buf[p.pos] != '=' || p.buf[p.pos+1] != '"' {
+				return fmt.Errorf("expected '=\"' at position %d", p.pos)
+			}
+			p.pos += 2
+			value, err := p.paramValue()
+			if err != nil {
+				return err
+			}
+			elem.params = append(elem.params, sdParam{name: name, value: value})
+		}
+
+		msg.sd = append(msg.sd, elem)
+	}
+	return nil
+}
+
+// sdName reads a structured data id or param name.
+func (p *parser) sdName() (string, error) {
+	start := p.pos
+	for p.pos < len(p.buf) {
+		c := p.buf[p.pos]
+		if c <= ' ' || c >= 127 || c == '=' || c == ']' || c == '"' {
+			break
+		}
+		p.pos++
+	}
+	if p.pos == start {
+		return "", fmt.Errorf("invalid structured data name at position %d", start)
+	}
+	return string(p.buf[start:p.pos]), nil
+}
+
+// paramValue reads a param value up to the closing quote, unescaping '"',
+// '\' and ']'.
+func (p *parser) paramValue() (string, error) {
+	var value []byte
+	for p.pos < len(p.buf) {
+		c := p.buf[p.pos]
+		p.pos++
+		switch c {
+		case '"':
+			return string(value), nil
+		case '\\':
+			if p.pos < len(p.buf) {
+				switch p.buf[p.pos] {
+				case '"', '\\', ']':
+					c = p.buf[p.pos]
+					p.pos++
+				}
+			}
+		}
+		value = append(value, c)
+	}
+	return "", errUnexpectedEnd
+}
+
+// rfc3164 parses the rest of a RFC3164 message:
+//
+//	TIMESTAMP HOSTNAME TAG[PID]: MSG
+//
+// The header is optional, without a valid timestamp the whole content is the
+// message.  The tag is only taken if it is followed by a colon.
+func (p *parser) rfc3164(msg *syslogMessage, now time.Time) error {
+	rest := p.buf[p.pos:]
+	if len(rest) > len(time.Stamp) && rest[len(time.Stamp)] == ' ' {
+		if t, err := time.Parse(time.Stamp, string(rest[:len(time.Stamp)])); err == nil {
+			msg.timestamp = rfc3164Time(t, now)
+			p.pos += len(time.Stamp) + 1
+			if hostname, err := p.field(); err == nil {
+				msg.hostname = hostname
+			}
+		}
+	}
+
+	content := p.buf[p.pos:]
+	if i := bytes.IndexAny(content, "[: "); i > 0 && content[i] != ' ' {
+		tag := content[:i]
+		rest := content[i:]
+
+		var procID []byte
+		if rest[0] == '[' {
+			if j := bytes.IndexByte(rest, ']'); j > 0 {
+				procID = rest[1:j]
+				rest = rest[j+1:]
+			}
+		}
+		if len(rest) > 0 && rest[0] == ':' {
+			msg.appname = string(tag)
+			msg.procID = string(procID)
+			content = bytes.TrimPrefix(rest[1:], []byte(" "))
+		}
+	}
+	msg.message = string(content)
+	return nil
+}
+
+// rfc3164Time sets the year of the timestamp, a timestamp more than a day
+// ahead of now is from the previous year.
+func rfc3164Time(t time.Time, now time.Time) time.Time {
+	ts := time.Date(now.Year(), t.Month(), t.Day(),
+		t.Hour(), t.Minute(), t.Second(), 0, now.Location())
+	if ts.After(now.Add(24 * time.Hour)) {
+		ts = ts.AddDate(-1, 0, 0)
+	}
+	return ts
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,146 @@
+package syslog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRFC5424(t *testing.T) {
+	now := time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected *syslogMessage
+	}{
+		{
+			name:  "full",
+			input: `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog 1234 ID47 [exampleSDID@32473 iut="3" eventID="1011"][examplePriority@32473 class="high"] ` + "\xEF\xBB\xBF" + `An application event`,
+			expected: &syslogMessage{
+				facility:  20,
+				severity:  5,
+				version:   1,
+				timestamp: time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC),
+				hostname:  "mymachine.example.com",
+				appname:   "evntslog",
+				procID:    "1234",
+				msgID:     "ID47",
+				sd: []sdElement{
+					{id: "exampleSDID@32473", params: []sdParam{{"iut", "3"}, {"eventID", "1011"}}},
+					{id: "examplePriority@32473", params: []sdParam{{"class", "high"}}},
+				},
+				message: "An application event",
+			},
+		},
+		{
+			name:  "nil values",
+			input: `<34>1 - - - - - -`,
+			expected: &syslogMessage{
+				facility: 4,
+				severity: 2,
+				version:  1,
+			},
+		},
+		{
+			name:  "escaped param value",
+			input: `<13>1 2003-08-24T05:14:15.000003-07:00 host app - - [id a="q\"b\\c\]d" e=""] msg`,
+			expected: &syslogMessage{
+				facility:  1,
+				severity:  5,
+				version:   1,
+				timestamp: time.Date(2003, 8, 24, 5, 14, 15, 3000, time.FixedZone("", -7*60*60)),
+				hostname:  "host",
+				appname:   "app",
+				sd: []sdElement{
+					{id: "id", params: []sdParam{{"a", `q"b\c]d`}, {"e", ""}}},
+				},
+				message: "msg",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := parseMessage([]byte(tt.input), now)
+			require.NoError(t, err)
+			assert.True(t, tt.expected.timestamp.Equal(msg.timestamp))
+			tt.expected.timestamp = msg.timestamp
+			assert.Equal(t, tt.expected, msg)
+		})
+	}
+}
+
+func TestParseRFC3164(t *testing.T) {
+	now := time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected *syslogMessage
+	}{
+		{
+			name:  "tag",
+			input: `<34>Oct 11 22:14:15 mymachine su: 'su root' failed for lonvick on /dev/pts/8`,
+			expected: &syslogMessage{
+				facility:  4,
+				severity:  2,
+				timestamp: time.Date(2017, 10, 11, 22, 14, 15, 0, time.UTC),
+				hostname:  "mymachine",
+				appname:   "su",
+				message:   "'su root' failed for lonvick on /dev/pts/8",
+			},
+		},
+		{
+			name:  "pid",
+			input: `<13>Jan  1 23:00:00 host sshd[123]: Accepted publickey`,
+			expected: &syslogMessage{
+				facility:  1,
+				severity:  5,
+				timestamp: time.Date(2018, 1, 1, 23, 0, 0, 0, time.UTC),
+				hostname:  "host",
+				appname:   "sshd",
+				procID:    "123",
+				message:   "Accepted publickey",
+			},
+		},
+		{
+			name:  "no header",
+			input: `<13>Use the BFG!`,
+			expected: &syslogMessage{
+				facility: 1,
+				severity: 5,
+				message:  "Use the BFG!",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := parseMessage([]byte(tt.input), now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, msg)
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	now := time.Now()
+
+	for _, input := range []string{
+		``,
+		`no priority`,
+		`<192>1 - - - - - -`,
+		`<13>1 yesterday - - - - -`,
+		`<13>1 - - - - -`,
+		`<13>1 - - - - - [id`,
+		`<13>1 - - - - - [id a="b]`,
+		`<13>1 - - - - - [id a=b]`,
+		`<13>1 - - - - - -msg`,
+	} {
+		_, err := parseMessage([]byte(input), now)
+		assert.Error(t, err, input)
+	}
+}
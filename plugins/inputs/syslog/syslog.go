@@ -0,0 +1,462 @@
+package syslog
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+const (
+	framingOctetCounting  = "octet-counting"
+	framingNonTransparent = "non-transparent"
+)
+
+var severityNames = []string{
+	"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug",
+}
+
+var facilityNames = []string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "solaris-cron",
+	"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+}
+
+type setReadBufferer interface {
+	SetReadBuffer(bytes int) error
+}
+
+// Syslog is a service input receiving syslog messages.
+type Syslog struct {
+	ServiceAddress   string             `toml:"service_address"`
+	MaxConnections   int                `toml:"max_connections"`
+	ReadTimeout      *internal.Duration `toml:"read_timeout"`
+	ReadBufferSize   int                `toml:"read_buffer_size"`
+	KeepAlivePeriod  *internal.Duration `toml:"keep_alive_period"`
+	Framing          string             `toml:"framing"`
+	Trailer          string             `toml:"trailer"`
+	SdparamSeparator string             `toml:"sdparam_separator"`
+
+	TlsAllowedCacerts []string `toml:"tls_allowed_cacerts"`
+	TlsAllowedCns     []string `toml:"tls_allowed_cns"`
+	TlsCert           string   `toml:"tls_cert"`
+	TlsKey            string   `toml:"tls_key"`
+
+	now func() time.Time
+	acc telegraf.Accumulator
+	wg  sync.WaitGroup
+
+	io.Closer
+}
+
+var sampleConfig = `
+  ## URL to listen on
+  # service_address = "tcp://:6514"
+  # service_address = "tcp4://:6514"
+  # service_address = "tcp6://:6514"
+  # service_address = "udp://:6514"
+  # service_address = "udp4://:6514"
+  # service_address = "udp6://:6514"
+  # service_address = "unix:///tmp/telegraf.sock"
+  # service_address = "unixgram:///tmp/telegraf.sock"
+  service_address = "tcp://:6514"
+
+  ## Maximum number of concurrent connections.
+  ## Only applies to stream sockets (e.g. TCP).
+  ## 0 (default) is unlimited.
+  # max_connections = 1024
+
+  ## Read timeout.
+  ## Only applies to stream sockets (e.g. TCP).
+  ## 0 (default) is unlimited.
+  # read_timeout = "30s"
+
+  ## Maximum socket buffer size in bytes.
+  ## For stream sockets, once the buffer fills up, the sender will start backing up.
+  ## For datagram sockets, once the buffer fills up, messages will start dropping.
+  ## Defaults to the OS default.
+  # read_buffer_size = 65535
+
+  ## Period between keep alive probes.
+  ## Only applies to TCP sockets.
+  ## 0 disables keep alive probes.
+  ## Defaults to the OS configuration.
+  # keep_alive_period = "5m"
+
+  ## Set the service certificate and key to enable TLS (RFC5425).
+  ## Only applies to stream sockets (e.g. TCP).
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+
+  ## Set one or more allowed client CA certificate file names to
+  ## enable mutually authenticated TLS connections.
+  # tls_allowed_cacerts = ["/etc/telegraf/clientca.pem"]
+
+  ## Optional list of common names allowed in client certificates, requires
+  ## tls_allowed_cacerts.
+  # tls_allowed_cns = ["telegraf"]
+
+  ## The framing technique with which messages are transported over stream
+  ## sockets, either "octet-counting" (RFC5425) or "non-transparent" (RFC6587).
+  ## Messages received over datagram sockets are not framed.
+  # framing = "octet-counting"
+
+  ## The trailer ending messages with non-transparent framing, either "LF"
+  ## or "NUL".
+  # trailer = "LF"
+
+  ## Separator between the structured data id and the param name of the
+  ## structured data fields.
+  # sdparam_separator = "_"
+`
+
+func (s *Syslog) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Syslog) Description() string {
+	return "Accepts syslog messages following RFC5424 or RFC3164 over UDP, TCP, TLS or unix sockets"
+}
+
+func (s *Syslog) Gather(_ telegraf.Accumulator) error {
+	return nil
+}
+
+func (s *Syslog) Start(acc telegraf.Accumulator) error {
+	s.acc = acc
+
+	spl := strings.SplitN(s.ServiceAddress, "://", 2)
+	if len(spl) != 2 {
+		return fmt.Errorf("invalid service address: %s", s.ServiceAddress)
+	}
+
+	newReader, err := s.frameReader()
+	if err != nil {
+		return err
+	}
+
+	tlsConfig, err := internal.GetServerTLSConfig(s.TlsCert, s.TlsKey,
+		s.TlsAllowedCacerts, s.TlsAllowedCns)
+	if err != nil {
+		return err
+	}
+
+	if spl[0] == "unix" || spl[0] == "unixgram" {
+		// ignore the error, listening fails if the file can't be removed
+		os.Remove(spl[1])
+	}
+
+	switch spl[0] {
+	case "tcp", "tcp4", "tcp6", "unix":
+		l, err := net.Listen(spl[0], spl[1])
+		if err != nil {
+			return err
+		}
+
+		ssl := &streamListener{
+			Listener:  l,
+			Syslog:    s,
+			tlsConfig: tlsConfig,
+			newReader: newReader,
+		}
+
+		s.Closer = ssl
+		s.wg.Add(1)
+		go ssl.listen()
+	case "udp", "udp4", "udp6", "unixgram":
+		if tlsConfig != nil {
+			return fmt.Errorf("TLS is not supported on %s sockets", spl[0])
+		}
+
+		pc, err := net.ListenPacket(spl[0], spl[1])
+		if err != nil {
+			return err
+		}
+
+		if s.ReadBufferSize > 0 {
+			if srb, ok := pc.(setReadBufferer); ok {
+				srb.SetReadBuffer(s.ReadBufferSize)
+			} else {
+				log.Printf("W! [inputs.syslog] Unable to set read buffer on a %s socket", spl[0])
+			}
+		}
+
+		psl := &packetListener{
+			PacketConn: pc,
+			Syslog:     s,
+		}
+
+		s.Closer = psl
+		s.wg.Add(1)
+		go psl.listen()
+	default:
+		return fmt.Errorf("unknown protocol '%s' in '%s'", spl[0], s.ServiceAddress)
+	}
+
+	if spl[0] == "unix" || spl[0] == "unixgram" {
+		s.Closer = unixCloser{path: spl[1], closer: s.Closer}
+	}
+
+	return nil
+}
+
+func (s *Syslog) Stop() {
+	if s.Closer != nil {
+		s.Close()
+		s.Closer = nil
+	}
+	s.wg.Wait()
+}
+
+// frameReader returns the constructor of the readers of the configured
+// framing.
+func (s *Syslog) frameReader() (func(io.Reader) frameReader, error) {
+	switch s.Framing {
+	case "", framingOctetCounting:
+		return newOctetCountingReader, nil
+	case framingNonTransparent:
+	default:
+		return nil, fmt.Errorf("unknown framing %q", s.Framing)
+	}
+
+	var trailer byte
+	switch strings.ToUpper(s.Trailer) {
+	case "", "LF":
+		trailer = '\n'
+	case "NUL":
+		trailer = 0
+	default:
+		return nil, fmt.Errorf("unknown trailer %q", s.Trailer)
+	}
+	return func(r io.Reader) frameReader {
+		return newNonTransparentReader(r, trailer)
+	}, nil
+}
+
+// parse parses the message and adds it as a metric.
+func (s *Syslog) parse(b []byte) {
+	now := s.now()
+	msg, err := parseMessage(b, now)
+	if err != nil {
+		s.acc.AddError(fmt.Errorf("unable to parse syslog message: %s", err))
+		return
+	}
+	s.addMessage(msg, now)
+}
+
+// addMessage adds the message as a syslog metric.  The header fields are
+// tags or fields, the params of each structured data element are fields
+// named by the element id and the param name, along with a true field named
+// by the element id.  The metric has the timestamp of the message, or else
+// the time it was received.
+func (s *Syslog) addMessage(msg *syslogMessage, now time.Time) {
+	tags := map[string]string{
+		"severity": severityNames[msg.severity],
+		"facility": facilityNames[msg.facility],
+	}
+	if msg.hostname != "" {
+		tags["hostname"] = msg.hostname
+	}
+	if msg.appname != "" {
+		tags["appname"] = msg.appname
+	}
+
+	fields := map[string]interface{}{
+		"severity_code": int(msg.severity),
+		"facility_code": int(msg.facility),
+	}
+	if msg.version > 0 {
+		fields["version"] = msg.version
+	}
+	if msg.procID != "" {
+		fields["procid"] = msg.procID
+	}
+	if msg.msgID != "" {
+		fields["msgid"] = msg.msgID
+	}
+	if msg.message != "" {
+		fields["message"] = msg.message
+	}
+	for _, elem := range msg.sd {
+		fields[elem.id] = true
+		for _, param := range elem.params {
+			fields[elem.id+s.SdparamSeparator+param.name] = param.value
+		}
+	}
+
+	ts := msg.timestamp
+	if ts.IsZero() {
+		ts = now
+	}
+	s.acc.AddFields("syslog", fields, tags, ts)
+}
+
+type streamListener struct {
+	net.Listener
+	*Syslog
+
+	tlsConfig *tls.Config
+	newReader func(io.Reader) frameReader
+
+	connections    map[string]net.Conn
+	connectionsMtx sync.Mutex
+}
+
+func (ssl *streamListener) listen() {
+	defer ssl.wg.Done()
+	ssl.connections = map[string]net.Conn{}
+
+	for {
+		c, err := ssl.Accept()
+		if err != nil {
+			if !strings.HasSuffix(err.Error(), ": use of closed network connection") {
+				ssl.acc.AddError(err)
+			}
+			break
+		}
+
+		ssl.connectionsMtx.Lock()
+		if ssl.MaxConnections > 0 && len(ssl.connections) >= ssl.MaxConnections {
+			ssl.connectionsMtx.Unlock()
+			c.Close()
+			continue
+		}
+		ssl.connections[c.RemoteAddr().String()] = c
+		ssl.connectionsMtx.Unlock()
+
+		if err := ssl.configureConn(c); err != nil {
+			ssl.acc.AddError(err)
+		}
+		if ssl.tlsConfig != nil {
+			c = tls.Server(c, ssl.tlsConfig)
+		}
+
+		ssl.wg.Add(1)
+		go ssl.read(c)
+	}
+
+	ssl.connectionsMtx.Lock()
+	for _, c := range ssl.connections {
+		c.Close()
+	}
+	ssl.connectionsMtx.Unlock()
+}
+
+// configureConn sets the read buffer size and keep alive period of the
+// connection.
+func (ssl *streamListener) configureConn(c net.Conn) error {
+	if ssl.ReadBufferSize > 0 {
+		if srb, ok := c.(setReadBufferer); ok {
+			srb.SetReadBuffer(ssl.ReadBufferSize)
+		} else {
+			log.Printf("W! [inputs.syslog] Unable to set read buffer on a %T connection", c)
+		}
+	}
+
+	if ssl.KeepAlivePeriod == nil {
+		return nil
+	}
+	tcpc, ok := c.(*net.TCPConn)
+	if !ok {
+		return fmt.Errorf("cannot set keep alive on a %T connection", c)
+	}
+	if ssl.KeepAlivePeriod.Duration == 0 {
+		return tcpc.SetKeepAlive(false)
+	}
+	if err := tcpc.SetKeepAlive(true); err != nil {
+		return err
+	}
+	return tcpc.SetKeepAlivePeriod(ssl.KeepAlivePeriod.Duration)
+}
+
+func (ssl *streamListener) removeConnection(c net.Conn) {
+	ssl.connectionsMtx.Lock()
+	delete(ssl.connections, c.RemoteAddr().String())
+	ssl.connectionsMtx.Unlock()
+}
+
+func (ssl *streamListener) read(c net.Conn) {
+	defer ssl.wg.Done()
+	defer ssl.removeConnection(c)
+	defer c.Close()
+
+	next := ssl.newReader(c)
+	for {
+		if ssl.ReadTimeout != nil && ssl.ReadTimeout.Duration > 0 {
+			c.SetReadDeadline(time.Now().Add(ssl.ReadTimeout.Duration))
+		}
+
+		b, err := next()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			if nerr, ok := err.(net.Error); ok && nerr.Timeout() {
+				log.Printf("D! Timeout in plugin [inputs.syslog]: %s", err)
+			} else if !strings.HasSuffix(err.Error(), ": use of closed network connection") {
+				ssl.acc.AddError(err)
+			}
+			return
+		}
+
+		ssl.parse(b)
+	}
+}
+
+type packetListener struct {
+	net.PacketConn
+	*Syslog
+}
+
+func (psl *packetListener) listen() {
+	defer psl.wg.Done()
+
+	buf := make([]byte, maxMessageSize)
+	for {
+		n, _, err := psl.ReadFrom(buf)
+		if err != nil {
+			if !strings.HasSuffix(err.Error(), ": use of closed network connection") {
+				psl.acc.AddError(err)
+			}
+			break
+		}
+
+		// each datagram is one message, possibly followed by a trailer
+		psl.parse(bytes.TrimRight(buf[:n], "\r\n\x00"))
+	}
+}
+
+type unixCloser struct {
+	path   string
+	closer io.Closer
+}
+
+func (uc unixCloser) Close() error {
+	err := uc.closer.Close()
+	os.Remove(uc.path) // ignore error
+	return err
+}
+
+func newSyslog() *Syslog {
+	return &Syslog{
+		ServiceAddress:   "tcp://:6514",
+		Framing:          framingOctetCounting,
+		Trailer:          "LF",
+		SdparamSeparator: "_",
+		now:              time.Now,
+	}
+}
+
+func init() {
+	inputs.Add("syslog", func() telegraf.Input { return newSyslog() })
+}
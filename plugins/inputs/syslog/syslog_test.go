@@ -0,0 +1,207 @@
+package syslog
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMessage = `<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventID="1011"] An application event`
+
+func newTestSyslog(address string) *Syslog {
+	s := newSyslog()
+	s.ServiceAddress = address
+	s.now = func() time.Time {
+		return time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC)
+	}
+	return s
+}
+
+func assertTestMessage(t *testing.T, acc *testutil.Accumulator) {
+	acc.AssertContainsTaggedFields(t, "syslog",
+		map[string]interface{}{
+			"version":                   1,
+			"severity_code":             5,
+			"facility_code":             20,
+			"msgid":                     "ID47",
+			"message":                   "An application event",
+			"exampleSDID@32473":         true,
+			"exampleSDID@32473_iut":     "3",
+			"exampleSDID@32473_eventID": "1011",
+		},
+		map[string]string{
+			"severity": "notice",
+			"facility": "local4",
+			"hostname": "mymachine.example.com",
+			"appname":  "evntslog",
+		})
+}
+
+func TestSyslogOctetCounting(t *testing.T) {
+	s := newTestSyslog("tcp://127.0.0.1:0")
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+	defer s.Stop()
+
+	client, err := net.Dial("tcp", s.Closer.(net.Listener).Addr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.Write([]byte("17 <13>1 - - - - - -"))
+	require.NoError(t, err)
+	_, err = fmt.Fprintf(client, "%d %s", len(testMessage), testMessage)
+	require.NoError(t, err)
+
+	acc.Wait(2)
+	assertTestMessage(t, acc)
+	acc.AssertContainsTaggedFields(t, "syslog",
+		map[string]interface{}{
+			"version":       1,
+			"severity_code": 5,
+			"facility_code": 1,
+		},
+		map[string]string{
+			"severity": "notice",
+			"facility": "user",
+		})
+}
+
+func TestSyslogNonTransparent(t *testing.T) {
+	s := newTestSyslog("tcp://127.0.0.1:0")
+	s.Framing = framingNonTransparent
+	s.Trailer = "NUL"
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+	defer s.Stop()
+
+	client, err := net.Dial("tcp", s.Closer.(net.Listener).Addr().String())
+	require.NoError(t, err)
+
+	_, err = client.Write([]byte(testMessage + "\x00<13>Oct 11 22:14:15 host su: failed\x00"))
+	require.NoError(t, err)
+	client.Close()
+
+	acc.Wait(2)
+	assertTestMessage(t, acc)
+	acc.AssertContainsTaggedFields(t, "syslog",
+		map[string]interface{}{
+			"severity_code": 5,
+			"facility_code": 1,
+			"message":       "failed",
+		},
+		map[string]string{
+			"severity": "notice",
+			"facility": "user",
+			"hostname": "host",
+			"appname":  "su",
+		})
+
+	acc.Lock()
+	defer acc.Unlock()
+	assert.Equal(t, time.Date(2017, 10, 11, 22, 14, 15, 0, time.UTC), acc.Metrics[1].Time)
+}
+
+func TestSyslogTLS(t *testing.T) {
+	s := newTestSyslog("tcp://127.0.0.1:0")
+	s.TlsCert = testutil.TLSFile("servercert.pem")
+	s.TlsKey = testutil.TLSFile("serverkey.pem")
+	s.TlsAllowedCacerts = []string{testutil.TLSFile("cacert.pem")}
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+	defer s.Stop()
+
+	tlsConfig, err := testutil.TLSClientConfig()
+	require.NoError(t, err)
+
+	client, err := tls.Dial("tcp", s.Closer.(net.Listener).Addr().String(), tlsConfig)
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = fmt.Fprintf(client, "%d %s", len(testMessage), testMessage)
+	require.NoError(t, err)
+
+	acc.Wait(1)
+	assertTestMessage(t, acc)
+}
+
+func TestSyslogUDP(t *testing.T) {
+	s := newTestSyslog("udp://127.0.0.1:0")
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+	defer s.Stop()
+
+	client, err := net.Dial("udp", s.Closer.(net.PacketConn).LocalAddr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.Write([]byte(testMessage + "\n"))
+	require.NoError(t, err)
+
+	acc.Wait(1)
+	assertTestMessage(t, acc)
+}
+
+func TestSyslogUnix(t *testing.T) {
+	sock := "/tmp/telegraf_syslog_test.sock"
+	s := newTestSyslog("unix://" + sock)
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+	defer s.Stop()
+
+	client, err := net.Dial("unix", sock)
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = fmt.Fprintf(client, "%d %s", len(testMessage), testMessage)
+	require.NoError(t, err)
+
+	acc.Wait(1)
+	assertTestMessage(t, acc)
+
+	s.Stop()
+	_, err = os.Stat(sock)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSyslogParseError(t *testing.T) {
+	s := newTestSyslog("udp://127.0.0.1:0")
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, s.Start(acc))
+	defer s.Stop()
+
+	client, err := net.Dial("udp", s.Closer.(net.PacketConn).LocalAddr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.Write([]byte("not syslog"))
+	require.NoError(t, err)
+
+	acc.WaitError(1)
+	assert.Contains(t, acc.Errors[0].Error(), "unable to parse syslog message")
+}
+
+func TestSyslogInvalidConfig(t *testing.T) {
+	for _, s := range []*Syslog{
+		{ServiceAddress: "tcp:127.0.0.1:0"},
+		{ServiceAddress: "http://127.0.0.1:0"},
+		{ServiceAddress: "tcp://127.0.0.1:0", Framing: "unknown"},
+		{ServiceAddress: "tcp://127.0.0.1:0", Framing: framingNonTransparent, Trailer: "CR"},
+		{ServiceAddress: "tcp://127.0.0.1:0", TlsCert: "missing.pem", TlsKey: "missing.pem"},
+	} {
+		acc := &testutil.Accumulator{}
+		assert.Error(t, s.Start(acc), s.ServiceAddress)
+	}
+}
@@ -0,0 +1,17 @@
+// +build !solaris,!windows
+
+package tail
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileInode returns the inode number of the file.
+func fileInode(fi os.FileInfo) (uint64, bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(st.Ino), true
+}
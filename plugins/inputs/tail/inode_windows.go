@@ -0,0 +1,13 @@
+// +build windows
+
+package tail
+
+import (
+	"os"
+)
+
+// fileInode is not supported on Windows, the identity of files is not
+// checked when resuming from saved offsets.
+func fileInode(fi os.FileInfo) (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,196 @@
+// +build !solaris
+
+package tail
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/influxdata/tail"
+)
+
+// fileOffset is the read offset of a file, along with the inode identifying
+// the file it applies to.
+type fileOffset struct {
+	Offset int64  `json:"offset"`
+	Inode  uint64 `json:"inode,omitempty"`
+}
+
+// offsetsState is the content of the offsets file.
+type offsetsState struct {
+	Files map[string]fileOffset `json:"files"`
+}
+
+// loadOffsets reads the offsets saved in the file, a missing file is not an
+// error.
+func loadOffsets(path string) (map[string]fileOffset, error) {
+	state := offsetsState{Files: make(map[string]fileOffset)}
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state.Files, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, err
+	}
+	if state.Files == nil {
+		state.Files = make(map[string]fileOffset)
+	}
+	return state.Files, nil
+}
+
+// saveOffsets replaces the offsets file, by writing a temporary file which
+// is then renamed so that the file is never partially written.
+func saveOffsets(path string, offsets map[string]fileOffset) error {
+	b, err := json.Marshal(offsetsState{Files: offsets})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// resumeOffset returns where to resume reading the file from its saved
+// offset.  It returns false if there is no usable offset: the file was
+// replaced, i.e. by log rotation, or truncated, and should be read from the
+// beginning, or there is no saved offset.
+func resumeOffset(file string, offset fileOffset, ok bool) (*tail.SeekInfo, bool) {
+	if !ok {
+		return nil, false
+	}
+
+	fi, err := os.Stat(file)
+	if err != nil {
+		return nil, false
+	}
+
+	if inode, ok := fileInode(fi); ok && offset.Inode != 0 && inode != offset.Inode {
+		// a new file replaced the one the offset applies to
+		return &tail.SeekInfo{Whence: 0, Offset: 0}, true
+	}
+	if fi.Size() < offset.Offset {
+		// the file was truncated
+		return &tail.SeekInfo{Whence: 0, Offset: 0}, true
+	}
+	// an offset within a line resumes at the beginning of the line
+	start, err := lineStart(file, offset.Offset)
+	if err != nil {
+		return &tail.SeekInfo{Whence: 0, Offset: 0}, true
+	}
+	return &tail.SeekInfo{Whence: 0, Offset: start}, true
+}
+
+// lineStart returns the offset of the beginning of the line containing the
+// byte at offset, which is offset itself if the previous byte is a newline.
+func lineStart(file string, offset int64) (int64, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, 4096)
+	for offset > 0 {
+		n := int64(len(buf))
+		if offset < n {
+			n = offset
+		}
+		if _, err := f.ReadAt(buf[:n], offset-n); err != nil {
+			return 0, err
+		}
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			return offset - n + int64(i) + 1, nil
+		}
+		offset -= n
+	}
+	return 0, nil
+}
+
+// readOffset tracks the offset of a file up to the end of the last line
+// processed, that is parsed or joined into a parsed multiline event, so that
+// lines read by the tailer but not processed yet are read again on restart.
+// The lines are counted with their newline, as the tailer only sends
+// complete lines.
+type readOffset struct {
+	sync.Mutex
+	offset fileOffset
+	// read is the offset of the end of the last line received, pending the
+	// sizes of the lines received but not processed yet.
+	read    int64
+	pending []int64
+}
+
+func newReadOffset(offset fileOffset) *readOffset {
+	return &readOffset{offset: offset, read: offset.Offset}
+}
+
+// received records a line of size bytes received from the tailer.
+//
+// A tailer behind the lines received reopened the file if it was replaced or
+// truncated, and reads the new file from the beginning.  The count restarts
+// at the beginning of the new file, and the line is taken to be the last one
+// of the previous file, so that the offset is behind rather than ahead of the
+// lines processed.
+func (r *readOffset) received(tailer *tail.Tail, size int64) {
+	r.Lock()
+	defer r.Unlock()
+
+	if pos, err := tailer.Tell(); err == nil && pos < r.read+size {
+		if fi, err := os.Stat(tailer.Filename); err == nil {
+			inode, _ := fileInode(fi)
+			if inode != r.offset.Inode || fi.Size() < r.read+size {
+				r.offset = fileOffset{Inode: inode}
+				r.read = 0
+				r.pending = nil
+				return
+			}
+		}
+	}
+
+	r.read += size
+	r.pending = append(r.pending, size)
+}
+
+// processed records that the lines received were processed, except for the
+// last buffered ones.
+func (r *readOffset) processed(buffered int) {
+	r.Lock()
+	defer r.Unlock()
+
+	n := len(r.pending) - buffered
+	if n <= 0 {
+		return
+	}
+	for _, size := range r.pending[:n] {
+		r.offset.Offset += size
+	}
+	r.pending = append(r.pending[:0], r.pending[n:]...)
+}
+
+// current returns the offset of the end of the last line processed.
+func (r *readOffset) current() fileOffset {
+	r.Lock()
+	defer r.Unlock()
+	return r.offset
+}
@@ -4,13 +4,16 @@ package tail
 
 import (
 	"fmt"
+	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/influxdata/tail"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal/globpath"
+	"github.com/influxdata/telegraf/internal/multiline"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
 )
@@ -24,11 +27,16 @@ type Tail struct {
 	FromBeginning bool
 	Pipe          bool
 	WatchMethod   string
+	OffsetsFile   string `toml:"offsets_file"`
 
-	tailers []*tail.Tail
-	parser  parsers.Parser
-	wg      sync.WaitGroup
-	acc     telegraf.Accumulator
+	Multiline *multiline.Config `toml:"multiline"`
+
+	tailers     []*tail.Tail
+	offsets     map[string]fileOffset
+	readOffsets map[string]*readOffset
+	parser      parsers.Parser
+	wg          sync.WaitGroup
+	acc         telegraf.Accumulator
 
 	sync.Mutex
 }
@@ -58,11 +66,33 @@ const sampleConfig = `
   ## Method used to watch for file updates.  Can be either "inotify" or "poll".
   # watch_method = "inotify"
 
+  ## File to save the read offsets of the tailed files in, on each interval
+  ## and when stopping.  When set, reading resumes from the saved offsets on
+  ## startup, or from the beginning of files that were rotated or truncated
+  ## in the meantime.  Each tail plugin requires its own file.
+  # offsets_file = "/var/lib/telegraf/tail_offsets.json"
+
   ## Data format to consume.
   ## Each data format has its own unique set of configuration options, read
   ## more about them here:
   ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_INPUT.md
   data_format = "influx"
+
+  ## Join multiple lines into one event before parsing, i.e. stack traces.
+  ## Exactly one of start_pattern or continuation_pattern must be set.
+  # [inputs.tail.multiline]
+  #   ## Regular expression matching the first line of each event, other
+  #   ## lines are appended to the current event.
+  #   start_pattern = '^\d{4}-\d{2}-\d{2}'
+  #   ## Regular expression matching the lines appended to the current event,
+  #   ## other lines start a new event.
+  #   # continuation_pattern = '^\s'
+  #   ## Invert the match of the pattern.
+  #   # invert_match = false
+  #   ## Time after which the last event is parsed if no new line is read.
+  #   # timeout = "5s"
+  #   ## Maximum number of lines of an event.
+  #   # max_lines = 500
 `
 
 func (t *Tail) SampleConfig() string {
@@ -74,7 +104,10 @@ func (t *Tail) Description() string {
 }
 
 func (t *Tail) Gather(acc telegraf.Accumulator) error {
-	return nil
+	t.Lock()
+	defer t.Unlock()
+
+	return t.storeOffsets()
 }
 
 func (t *Tail) Start(acc telegraf.Accumulator) error {
@@ -83,6 +116,12 @@ func (t *Tail) Start(acc telegraf.Accumulator) error {
 
 	t.acc = acc
 
+	if t.Multiline != nil {
+		if _, err := t.Multiline.New(); err != nil {
+			return err
+		}
+	}
+
 	var seek *tail.SeekInfo
 	if !t.Pipe && !t.FromBeginning {
 		seek = &tail.SeekInfo{
@@ -96,6 +135,17 @@ func (t *Tail) Start(acc telegraf.Accumulator) error {
 		poll = true
 	}
 
+	t.offsets = make(map[string]fileOffset)
+	t.readOffsets = make(map[string]*readOffset)
+	if t.OffsetsFile != "" && !t.Pipe {
+		offsets, err := loadOffsets(t.OffsetsFile)
+		if err != nil {
+			acc.AddError(fmt.Errorf("E! Error loading tail offsets from %s: %s", t.OffsetsFile, err))
+		} else {
+			t.offsets = offsets
+		}
+	}
+
 	// Create a "tailer" for each file
 	for _, filepath := range t.Files {
 		g, err := globpath.Compile(filepath)
@@ -103,11 +153,24 @@ func (t *Tail) Start(acc telegraf.Accumulator) error {
 			t.acc.AddError(fmt.Errorf("E! Error Glob %s failed to compile, %s", filepath, err))
 		}
 		for file, _ := range g.Match() {
+			location := seek
+			var ro *readOffset
+			if t.OffsetsFile != "" && !t.Pipe {
+				offset, ok := t.offsets[file]
+				if resume, ok := resumeOffset(file, offset, ok); ok {
+					location = resume
+				}
+				if location, ro, err = startOffset(file, location); err != nil {
+					acc.AddError(err)
+					continue
+				}
+			}
+
 			tailer, err := tail.TailFile(file,
 				tail.Config{
 					ReOpen:    true,
 					Follow:    true,
-					Location:  seek,
+					Location:  location,
 					MustExist: true,
 					Poll:      poll,
 					Pipe:      t.Pipe,
@@ -117,47 +180,129 @@ func (t *Tail) Start(acc telegraf.Accumulator) error {
 				acc.AddError(err)
 				continue
 			}
+			var ml *multiline.Multiline
+			if t.Multiline != nil {
+				ml, _ = t.Multiline.New()
+			}
+
 			// create a goroutine for each "tailer"
 			t.wg.Add(1)
-			go t.receiver(tailer)
+			go t.receiver(tailer, ml, ro)
 			t.tailers = append(t.tailers, tailer)
+			if ro != nil {
+				t.readOffsets[file] = ro
+			}
 		}
 	}
 
 	return nil
 }
 
+// startOffset returns the location to start tailing the file from, as an
+// offset from the beginning of the file, and the tracker of its read offset.
+func startOffset(file string, location *tail.SeekInfo) (*tail.SeekInfo, *readOffset, error) {
+	fi, err := os.Stat(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	inode, _ := fileInode(fi)
+
+	if location == nil {
+		location = &tail.SeekInfo{Whence: 0, Offset: 0}
+	} else if location.Whence == 2 {
+		location = &tail.SeekInfo{Whence: 0, Offset: fi.Size() + location.Offset}
+	}
+	return location, newReadOffset(fileOffset{Offset: location.Offset, Inode: inode}), nil
+}
+
 // this is launched as a goroutine to continuously watch a tailed logfile
-// for changes, parse any incoming msgs, and add to the accumulator.
-func (t *Tail) receiver(tailer *tail.Tail) {
+// for changes, parse any incoming msgs, and add to the accumulator.  The
+// read offset, if tracked, advances as the lines are processed.
+func (t *Tail) receiver(tailer *tail.Tail, ml *multiline.Multiline, ro *readOffset) {
 	defer t.wg.Done()
 
-	var m telegraf.Metric
-	var err error
-	var line *tail.Line
-	for line = range tailer.Lines {
+	// the timer flushes the last multiline event if no further line is read
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	var timeout <-chan time.Time
+
+	for {
+		var line *tail.Line
+		var ok bool
+		select {
+		case line, ok = <-tailer.Lines:
+		case <-timeout:
+			timeout = nil
+			if event, ok := ml.Flush(); ok {
+				t.parseLine(tailer, event)
+			}
+			if ro != nil {
+				ro.processed(0)
+			}
+			continue
+		}
+		if !ok {
+			break
+		}
+
 		if line.Err != nil {
 			t.acc.AddError(fmt.Errorf("E! Error tailing file %s, Error: %s\n",
-				tailer.Filename, err))
+				tailer.Filename, line.Err))
 			continue
 		}
+		if ro != nil {
+			ro.received(tailer, int64(len(line.Text))+1)
+		}
 		// Fix up files with Windows line endings.
 		text := strings.TrimRight(line.Text, "\r")
 
-		m, err = t.parser.ParseLine(text)
-		if err == nil {
-			t.acc.AddFields(m.Name(), m.Fields(), m.Tags(), m.Time())
-		} else {
-			t.acc.AddError(fmt.Errorf("E! Malformed log line in %s: [%s], Error: %s\n",
-				tailer.Filename, line.Text, err))
+		if ml == nil {
+			t.parseLine(tailer, text)
+			if ro != nil {
+				ro.processed(0)
+			}
+			continue
+		}
+
+		if event, ok := ml.AddLine(text); ok {
+			t.parseLine(tailer, event)
 		}
+		if ro != nil {
+			ro.processed(ml.Len())
+		}
+		if !timer.Stop() && timeout != nil {
+			<-timer.C
+		}
+		timer.Reset(ml.Timeout())
+		timeout = timer.C
 	}
+
+	timer.Stop()
+	if ml != nil {
+		if event, ok := ml.Flush(); ok {
+			t.parseLine(tailer, event)
+		}
+		if ro != nil {
+			ro.processed(0)
+		}
+	}
+
 	if err := tailer.Err(); err != nil {
 		t.acc.AddError(fmt.Errorf("E! Error tailing file %s, Error: %s\n",
 			tailer.Filename, err))
 	}
 }
 
+func (t *Tail) parseLine(tailer *tail.Tail, text string) {
+	m, err := t.parser.ParseLine(text)
+	if err == nil {
+		t.acc.AddFields(m.Name(), m.Fields(), m.Tags(), m.Time())
+	} else {
+		t.acc.AddError(fmt.Errorf("E! Malformed log line in %s: [%s], Error: %s\n",
+			tailer.Filename, text, err))
+	}
+}
+
 func (t *Tail) Stop() {
 	t.Lock()
 	defer t.Unlock()
@@ -170,6 +315,33 @@ func (t *Tail) Stop() {
 		tailer.Cleanup()
 	}
 	t.wg.Wait()
+
+	// the offsets are saved once the receivers processed the lines read
+	if err := t.storeOffsets(); err != nil {
+		t.acc.AddError(err)
+	}
+}
+
+// storeOffsets saves the current offsets of the tailed files, along with
+// the saved offsets of files not tailed anymore that still exist.
+func (t *Tail) storeOffsets() error {
+	if t.OffsetsFile == "" || t.Pipe {
+		return nil
+	}
+
+	for file := range t.offsets {
+		if _, err := os.Stat(file); os.IsNotExist(err) {
+			delete(t.offsets, file)
+		}
+	}
+	for file, ro := range t.readOffsets {
+		t.offsets[file] = ro.current()
+	}
+
+	if err := saveOffsets(t.OffsetsFile, t.offsets); err != nil {
+		return fmt.Errorf("E! Error saving tail offsets to %s: %s", t.OffsetsFile, err)
+	}
+	return nil
 }
 
 func (t *Tail) SetParser(parser parsers.Parser) {
@@ -3,9 +3,14 @@ package tail
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
+	"github.com/influxdata/tail"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/multiline"
 	"github.com/influxdata/telegraf/plugins/parsers"
 	"github.com/influxdata/telegraf/testutil"
 
@@ -141,3 +146,172 @@ func TestTailDosLineendings(t *testing.T) {
 			"usage_idle": float64(200),
 		})
 }
+
+func TestTailResumeFromOffsets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tail")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "metrics.out")
+	offsetsFile := filepath.Join(dir, "offsets.json")
+	require.NoError(t, ioutil.WriteFile(file, []byte("cpu usage_idle=100\ncpu usage_idle=90\n"), 0644))
+
+	tt := NewTail()
+	tt.FromBeginning = true
+	tt.OffsetsFile = offsetsFile
+	tt.Files = []string{file}
+	p, _ := parsers.NewInfluxParser()
+	tt.SetParser(p)
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, tt.Start(&acc))
+	acc.Wait(2)
+	tt.Stop()
+
+	offsets, err := loadOffsets(offsetsFile)
+	require.NoError(t, err)
+	require.Contains(t, offsets, file)
+	assert.Equal(t, int64(37), offsets[file].Offset)
+
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString("cpu usage_idle=80\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// only the line written after stopping is read again
+	tt = NewTail()
+	tt.FromBeginning = true
+	tt.OffsetsFile = offsetsFile
+	tt.Files = []string{file}
+	p, _ = parsers.NewInfluxParser()
+	tt.SetParser(p)
+
+	acc = testutil.Accumulator{}
+	require.NoError(t, tt.Start(&acc))
+	acc.Wait(1)
+	require.NoError(t, acc.GatherError(tt.Gather))
+	tt.Stop()
+
+	require.Len(t, acc.Metrics, 1)
+	acc.AssertContainsFields(t, "cpu",
+		map[string]interface{}{
+			"usage_idle": float64(80),
+		})
+
+	offsets, err = loadOffsets(offsetsFile)
+	require.NoError(t, err)
+	assert.Equal(t, int64(55), offsets[file].Offset)
+}
+
+func TestResumeOffset(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tail")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "metrics.out")
+	require.NoError(t, ioutil.WriteFile(file, []byte("cpu usage_idle=100\ncpu usage_idle=90\n"), 0644))
+	fi, err := os.Stat(file)
+	require.NoError(t, err)
+	inode, _ := fileInode(fi)
+
+	_, ok := resumeOffset(file, fileOffset{}, false)
+	assert.False(t, ok)
+
+	seek, ok := resumeOffset(file, fileOffset{Offset: 19, Inode: inode}, true)
+	require.True(t, ok)
+	assert.Equal(t, &tail.SeekInfo{Whence: 0, Offset: 19}, seek)
+
+	// within a line
+	seek, ok = resumeOffset(file, fileOffset{Offset: 25, Inode: inode}, true)
+	require.True(t, ok)
+	assert.Equal(t, &tail.SeekInfo{Whence: 0, Offset: 19}, seek)
+
+	// truncated
+	seek, ok = resumeOffset(file, fileOffset{Offset: 100, Inode: inode}, true)
+	require.True(t, ok)
+	assert.Equal(t, &tail.SeekInfo{Whence: 0, Offset: 0}, seek)
+
+	if runtime.GOOS != "windows" {
+		// rotated
+		seek, ok = resumeOffset(file, fileOffset{Offset: 10, Inode: inode + 1}, true)
+		require.True(t, ok)
+		assert.Equal(t, &tail.SeekInfo{Whence: 0, Offset: 0}, seek)
+	}
+}
+
+func TestTailMultiline(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+	_, err = tmpfile.WriteString("{\n  \"value\": 1,\n  \"other\": 2\n}\n{\n  \"value\": 3\n}\n")
+	require.NoError(t, err)
+
+	tt := NewTail()
+	tt.FromBeginning = true
+	tt.Files = []string{tmpfile.Name()}
+	tt.Multiline = &multiline.Config{
+		StartPattern: `^{`,
+		Timeout:      internal.Duration{Duration: 100 * time.Millisecond},
+	}
+	p, _ := parsers.NewJSONParser("json", nil, nil)
+	tt.SetParser(p)
+	defer tt.Stop()
+	defer tmpfile.Close()
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, tt.Start(&acc))
+
+	// the second document is only complete once the timeout expires
+	acc.Wait(2)
+	acc.Lock()
+	defer acc.Unlock()
+	require.Len(t, acc.Metrics, 2)
+	assert.Equal(t, map[string]interface{}{"value": float64(1), "other": float64(2)}, acc.Metrics[0].Fields)
+	assert.Equal(t, map[string]interface{}{"value": float64(3)}, acc.Metrics[1].Fields)
+}
+
+func TestTailMultilineOffsets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tail")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "metrics.json")
+	offsetsFile := filepath.Join(dir, "offsets.json")
+	first := "{\n  \"value\": 1\n}\n"
+	second := "{\n  \"value\": 3\n}\n"
+	require.NoError(t, ioutil.WriteFile(file, []byte(first+second), 0644))
+
+	tt := NewTail()
+	tt.FromBeginning = true
+	tt.OffsetsFile = offsetsFile
+	tt.Files = []string{file}
+	tt.Multiline = &multiline.Config{
+		StartPattern: `^{`,
+		Timeout:      internal.Duration{Duration: time.Hour},
+	}
+	p, _ := parsers.NewJSONParser("json", nil, nil)
+	tt.SetParser(p)
+
+	acc := testutil.Accumulator{}
+	require.NoError(t, tt.Start(&acc))
+	acc.Wait(1)
+
+	// the lines of the second document are not processed until it is
+	// complete, the offset is the end of the first one
+	require.NoError(t, acc.GatherError(tt.Gather))
+	offsets, err := loadOffsets(offsetsFile)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(first)), offsets[file].Offset)
+	tt.Stop()
+}
+
+func TestTailMultilineInvalidConfig(t *testing.T) {
+	tt := NewTail()
+	tt.Multiline = &multiline.Config{}
+	p, _ := parsers.NewInfluxParser()
+	tt.SetParser(p)
+
+	acc := testutil.Accumulator{}
+	require.Error(t, tt.Start(&acc))
+}
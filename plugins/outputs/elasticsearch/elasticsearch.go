@@ -2,9 +2,14 @@ package elasticsearch
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -32,9 +37,22 @@ type Elasticsearch struct {
 	SSLCert             string `toml:"ssl_cert"` // Path to host cert file
 	SSLKey              string `toml:"ssl_key"`  // Path to cert key file
 	InsecureSkipVerify  bool   // Use SSL but skip chain & host verification
+	Pipeline            string `toml:"pipeline"`
+	ForceDocumentID     bool   `toml:"force_document_id"`
+	MaxRetries          int    `toml:"max_retries"`
+	DataStream          bool   `toml:"data_stream"`
+	ILMPolicy           string `toml:"ilm_policy"`
 	Client              *elastic.Client
+
+	majorReleaseNumber int
 }
 
+const (
+	// retryBackoff is the delay before resending rejected documents, doubled
+	// on each retry.
+	retryBackoff = 100 * time.Millisecond
+)
+
 var sampleConfig = `
   ## The full HTTP endpoint URL for your Elasticsearch instance
   ## Multiple urls can be specified as part of the same cluster,
@@ -69,6 +87,25 @@ var sampleConfig = `
   # default_tag_value = "none"
   index_name = "telegraf-%Y.%m.%d" # required.
 
+  ## Ingest pipeline to process the documents with.
+  # pipeline = "my_pipeline"
+
+  ## Set the document id from a hash of the series and timestamp of the
+  ## metric, so that documents written again by a retried write are not
+  ## duplicated.
+  # force_document_id = false
+
+  ## Number of times documents rejected by Elasticsearch with a temporary
+  ## error, such as a full queue, are resent before the write fails.
+  ## Documents rejected with other errors, such as mapping errors, are
+  ## logged and dropped.
+  # max_retries = 3
+
+  ## Write to a data stream named after index_name instead of to an index,
+  ## requires Elasticsearch 7.9 or later.  Date specifiers are not supported
+  ## in index_name when enabled.
+  # data_stream = false
+
   ## Optional SSL Config
   # ssl_ca = "/etc/telegraf/ca.pem"
   # ssl_cert = "/etc/telegraf/cert.pem"
@@ -84,13 +121,38 @@ var sampleConfig = `
   template_name = "telegraf"
   ## Set to true if you want telegraf to overwrite an existing template
   overwrite_template = false
+  ## Index lifecycle management policy applied by the template to new
+  ## indexes, the policy must already exist.
+  # ilm_policy = "telegraf"
 `
 
+// supportsDataStreams reports if the Elasticsearch version, such as 7.9.1,
+// supports data streams, which were added in 7.9.
+func supportsDataStreams(esVersion string) bool {
+	parts := strings.SplitN(esVersion, ".", 3)
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	if major != 7 {
+		return major > 7
+	}
+	if len(parts) < 2 {
+		return false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	return err == nil && minor >= 9
+}
+
 func (a *Elasticsearch) Connect() error {
 	if a.URLs == nil || a.IndexName == "" {
 		return fmt.Errorf("Elasticsearch urls or index_name is not defined")
 	}
 
+	if a.DataStream && strings.Contains(a.IndexName, "%") {
+		return fmt.Errorf("Elasticsearch data stream name cannot contain date specifiers: %s", a.IndexName)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), a.Timeout.Duration)
 	defer cancel()
 
@@ -147,6 +209,10 @@ func (a *Elasticsearch) Connect() error {
 	if err != nil || i < 5 {
 		return fmt.Errorf("Elasticsearch version not supported: %s", esVersion)
 	}
+	if a.DataStream && !supportsDataStreams(esVersion) {
+		return fmt.Errorf("Elasticsearch version does not support data streams: %s", esVersion)
+	}
+	a.majorReleaseNumber = i
 
 	log.Println("I! Elasticsearch version: " + esVersion)
 
@@ -169,47 +235,133 @@ func (a *Elasticsearch) Write(metrics []telegraf.Metric) error {
 		return nil
 	}
 
-	bulkRequest := a.Client.Bulk()
-
+	requests := make([]*elastic.BulkIndexRequest, 0, len(metrics))
 	for _, metric := range metrics {
-		var name = metric.Name()
+		requests = append(requests, a.bulkIndexRequest(metric))
+	}
+
+	backoff := retryBackoff
+	for retry := 0; ; retry++ {
+		failed, err := a.bulk(requests)
+		if err != nil {
+			return err
+		}
+		if len(failed) == 0 {
+			return nil
+		}
+		if retry >= a.MaxRetries {
+			return fmt.Errorf("W! Elasticsearch failed to index %d metrics", len(failed))
+		}
+
+		log.Printf("D! Elasticsearch resending %d rejected metrics in %s", len(failed), backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+		requests = failed
+	}
+}
+
+func (a *Elasticsearch) bulkIndexRequest(metric telegraf.Metric) *elastic.BulkIndexRequest {
+	var name = metric.Name()
 
-		// index name has to be re-evaluated each time for telegraf
-		// to send the metric to the correct time-based index
-		indexName := a.GetIndexName(a.IndexName, metric.Time(), a.TagKeys, metric.Tags())
+	// index name has to be re-evaluated each time for telegraf
+	// to send the metric to the correct time-based index
+	indexName := a.GetIndexName(a.IndexName, metric.Time(), a.TagKeys, metric.Tags())
 
-		m := make(map[string]interface{})
+	m := make(map[string]interface{})
 
-		m["@timestamp"] = metric.Time()
-		m["measurement_name"] = name
-		m["tag"] = metric.Tags()
-		m[name] = metric.Fields()
+	m["@timestamp"] = metric.Time()
+	m["measurement_name"] = name
+	m["tag"] = metric.Tags()
+	m[name] = metric.Fields()
 
-		bulkRequest.Add(elastic.NewBulkIndexRequest().
-			Index(indexName).
-			Type("metrics").
-			Doc(m))
+	br := elastic.NewBulkIndexRequest().
+		Index(indexName).
+		Doc(m)
 
+	if a.majorReleaseNumber >= 7 {
+		br.Type("_doc")
+	} else {
+		br.Type("metrics")
+	}
+
+	if a.DataStream {
+		// data streams only accept new documents
+		br.OpType("create")
+	}
+
+	if a.Pipeline != "" {
+		br.Pipeline(a.Pipeline)
+	}
+
+	if a.ForceDocumentID {
+		br.Id(documentID(metric))
+	}
+
+	return br
+}
+
+// bulk sends the requests in a single bulk request.  It returns the requests
+// of the documents rejected with a temporary error that should be resent,
+// other rejected documents are logged and dropped.  An error is returned if
+// the bulk request itself failed.
+func (a *Elasticsearch) bulk(requests []*elastic.BulkIndexRequest) ([]*elastic.BulkIndexRequest, error) {
+	bulkRequest := a.Client.Bulk()
+	for _, r := range requests {
+		bulkRequest.Add(r)
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), a.Timeout.Duration)
 	defer cancel()
 
 	res, err := bulkRequest.Do(ctx)
-
 	if err != nil {
-		return fmt.Errorf("Error sending bulk request to Elasticsearch: %s", err)
+		return nil, fmt.Errorf("Error sending bulk request to Elasticsearch: %s", err)
+	}
+
+	if !res.Errors {
+		return nil, nil
 	}
 
-	if res.Errors {
-		for id, err := range res.Failed() {
-			log.Printf("E! Elasticsearch indexing failure, id: %d, error: %s, caused by: %s, %s", id, err.Error.Reason, err.Error.CausedBy["reason"], err.Error.CausedBy["type"])
+	// the items of the response are in the order of the requests
+	var failed []*elastic.BulkIndexRequest
+	for i, item := range res.Items {
+		if i >= len(requests) {
+			break
+		}
+		for _, result := range item {
+			if result.Error == nil {
+				continue
+			}
+			if a.DataStream && result.Status == http.StatusConflict {
+				// documents are only created in data streams, a conflict
+				// means the document was already written
+				continue
+			}
+			if isRetryable(result.Status) {
+				failed = append(failed, requests[i])
+				continue
+			}
+			log.Printf("E! Elasticsearch indexing failure, dropping metric, id: %s, status: %d, error: %s, caused by: %s, %s",
+				result.Id, result.Status, result.Error.Reason, result.Error.CausedBy["reason"], result.Error.CausedBy["type"])
 		}
-		return fmt.Errorf("W! Elasticsearch failed to index %d metrics", len(res.Failed()))
 	}
+	return failed, nil
+}
 
-	return nil
+// isRetryable returns whether a document rejected with the status may be
+// accepted when sent again.
+func isRetryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
 
+// documentID returns an id derived from the series and timestamp of the
+// metric, identical for every write of the same metric.
+func documentID(metric telegraf.Metric) string {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], metric.HashID())
+	binary.BigEndian.PutUint64(b[8:], uint64(metric.Time().UnixNano()))
+	h := sha256.Sum256(b[:])
+	return hex.EncodeToString(h[:])
 }
 
 func (a *Elasticsearch) manageTemplate(ctx context.Context) error {
@@ -217,24 +369,19 @@ func (a *Elasticsearch) manageTemplate(ctx context.Context) error {
 		return fmt.Errorf("Elasticsearch template_name configuration not defined")
 	}
 
-	templateExists, errExists := a.Client.IndexTemplateExists(a.TemplateName).Do(ctx)
-
-	if errExists != nil {
-		return fmt.Errorf("Elasticsearch template check failed, template name: %s, error: %s", a.TemplateName, errExists)
+	templatePattern := a.templatePattern()
+	if templatePattern == "" {
+		return fmt.Errorf("Template cannot be created for dynamic index names without an index prefix")
 	}
 
-	templatePattern := a.IndexName
-
-	if strings.Contains(templatePattern, "%") {
-		templatePattern = templatePattern[0:strings.Index(templatePattern, "%")]
+	if a.DataStream {
+		return a.manageIndexTemplate(ctx, templatePattern)
 	}
 
-	if strings.Contains(templatePattern, "{{") {
-		templatePattern = templatePattern[0:strings.Index(templatePattern, "{{")]
-	}
+	templateExists, errExists := a.Client.IndexTemplateExists(a.TemplateName).Do(ctx)
 
-	if templatePattern == "" {
-		return fmt.Errorf("Template cannot be created for dynamic index names without an index prefix")
+	if errExists != nil {
+		return fmt.Errorf("Elasticsearch template check failed, template name: %s, error: %s", a.TemplateName, errExists)
 	}
 
 	if (a.OverwriteTemplate) || (!templateExists) || (templatePattern != "") {
@@ -245,7 +392,7 @@ func (a *Elasticsearch) manageTemplate(ctx context.Context) error {
 				"settings": {
 					"index": {
 						"refresh_interval": "10s",
-						"mapping.total_fields.limit": 5000
+						"mapping.total_fields.limit": 5000%s
 					}
 				},
 				"mappings" : {
@@ -295,7 +442,7 @@ func (a *Elasticsearch) manageTemplate(ctx context.Context) error {
 						]
 					}
 				}
-			}`, templatePattern+"*")
+			}`, templatePattern+"*", a.lifecycleSetting())
 		_, errCreateTemplate := a.Client.IndexPutTemplate(a.TemplateName).BodyString(tmpl).Do(ctx)
 
 		if errCreateTemplate != nil {
@@ -312,6 +459,116 @@ func (a *Elasticsearch) manageTemplate(ctx context.Context) error {
 	return nil
 }
 
+// templatePattern returns the static prefix of the index name, which the
+// names of all indexes written to start with.
+func (a *Elasticsearch) templatePattern() string {
+	templatePattern := a.IndexName
+
+	if strings.Contains(templatePattern, "%") {
+		templatePattern = templatePattern[0:strings.Index(templatePattern, "%")]
+	}
+
+	if strings.Contains(templatePattern, "{{") {
+		templatePattern = templatePattern[0:strings.Index(templatePattern, "{{")]
+	}
+
+	return templatePattern
+}
+
+// lifecycleSetting returns the index setting applying the ILM policy, to
+// append to the other index settings of a template.
+func (a *Elasticsearch) lifecycleSetting() string {
+	if a.ILMPolicy == "" {
+		return ""
+	}
+	policy, _ := json.Marshal(a.ILMPolicy)
+	return fmt.Sprintf(`,
+						"lifecycle.name": %s`, policy)
+}
+
+// manageIndexTemplate creates the composable index template of the data
+// streams, unless it exists and should not be overwritten.
+func (a *Elasticsearch) manageIndexTemplate(ctx context.Context, templatePattern string) error {
+	path := "/_index_template/" + url.PathEscape(a.TemplateName)
+
+	res, err := a.Client.PerformRequest(ctx, "HEAD", path, nil, nil, http.StatusNotFound)
+	if err != nil {
+		return fmt.Errorf("Elasticsearch template check failed, template name: %s, error: %s", a.TemplateName, err)
+	}
+
+	if res.StatusCode != http.StatusNotFound && !a.OverwriteTemplate {
+		log.Println("D! Found existing Elasticsearch template. Skipping template management")
+		return nil
+	}
+
+	tmpl := fmt.Sprintf(`
+		{
+			"index_patterns": ["%s"],
+			"data_stream": {},
+			"priority": 200,
+			"template": {
+				"settings": {
+					"index": {
+						"refresh_interval": "10s",
+						"mapping.total_fields.limit": 5000%s
+					}
+				},
+				"mappings": {
+					"properties" : {
+						"@timestamp" : { "type" : "date" },
+						"measurement_name" : { "type" : "keyword" }
+					},
+					"dynamic_templates": [
+						{
+							"tags": {
+								"match_mapping_type": "string",
+								"path_match": "tag.*",
+								"mapping": {
+									"ignore_above": 512,
+									"type": "keyword"
+								}
+							}
+						},
+						{
+							"metrics_long": {
+								"match_mapping_type": "long",
+								"mapping": {
+									"type": "float",
+									"index": false
+								}
+							}
+						},
+						{
+							"metrics_double": {
+								"match_mapping_type": "double",
+								"mapping": {
+									"type": "float",
+									"index": false
+								}
+							}
+						},
+						{
+							"text_fields": {
+								"match": "*",
+								"mapping": {
+									"norms": false
+								}
+							}
+						}
+					]
+				}
+			}
+		}`, templatePattern+"*", a.lifecycleSetting())
+
+	_, err = a.Client.PerformRequest(ctx, "PUT", path, nil, tmpl)
+	if err != nil {
+		return fmt.Errorf("Elasticsearch failed to create index template %s : %s", a.TemplateName, err)
+	}
+
+	log.Printf("D! Elasticsearch index template %s created or updated\n", a.TemplateName)
+	return nil
+}
+
 func (a *Elasticsearch) GetTagKeys(indexName string) (string, []string) {
 
 	tagKeys := []string{}
@@ -392,6 +649,7 @@ func init() {
 		return &Elasticsearch{
 			Timeout:             internal.Duration{Duration: time.Second * 5},
 			HealthCheckInterval: internal.Duration{Duration: time.Second * 10},
+			MaxRetries:          3,
 		}
 	})
 }
@@ -1,13 +1,23 @@
 package elasticsearch
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -257,3 +267,235 @@ func TestGetIndexName(t *testing.T) {
 		}
 	}
 }
+
+// bulkAction is the action line of a document in a bulk request.
+type bulkAction map[string]map[string]string
+
+// fakeElasticsearch answers the version check, and bulk requests with the
+// statuses returned by bulkStatuses for the actions of each request.
+type fakeElasticsearch struct {
+	version      string
+	bulkStatuses func(call int, actions []bulkAction) []int
+	bulkRequests [][]bulkAction
+	templates    map[string]string
+}
+
+func (f *fakeElasticsearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/":
+		fmt.Fprintf(w, `{"version":{"number":%q}}`, f.version)
+	case r.URL.Path == "/_bulk":
+		var actions []bulkAction
+		scanner := bufio.NewScanner(r.Body)
+		for i := 0; scanner.Scan(); i++ {
+			if i%2 != 0 {
+				continue
+			}
+			var action bulkAction
+			if err := json.Unmarshal(scanner.Bytes(), &action); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			actions = append(actions, action)
+		}
+		statuses := f.bulkStatuses(len(f.bulkRequests), actions)
+		f.bulkRequests = append(f.bulkRequests, actions)
+
+		var items []string
+		hasErrors := false
+		for i, status := range statuses {
+			for op := range actions[i] {
+				if status >= 300 {
+					hasErrors = true
+					items = append(items, fmt.Sprintf(
+						`{%q:{"_id":"%d","status":%d,"error":{"type":"error","reason":"rejected"}}}`, op, i, status))
+				} else {
+					items = append(items, fmt.Sprintf(`{%q:{"_id":"%d","status":%d}}`, op, i, status))
+				}
+			}
+		}
+		fmt.Fprintf(w, `{"took":1,"errors":%t,"items":[%s]}`, hasErrors, strings.Join(items, ","))
+	case strings.HasPrefix(r.URL.Path, "/_index_template/"):
+		name := strings.TrimPrefix(r.URL.Path, "/_index_template/")
+		switch r.Method {
+		case "HEAD":
+			if _, ok := f.templates[name]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case "PUT":
+			body, _ := ioutil.ReadAll(r.Body)
+			f.templates[name] = string(body)
+			fmt.Fprint(w, `{"acknowledged":true}`)
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestElasticsearch(url string) *Elasticsearch {
+	return &Elasticsearch{
+		URLs:       []string{url},
+		IndexName:  "test-%Y.%m.%d",
+		Timeout:    internal.Duration{Duration: time.Second * 5},
+		MaxRetries: 3,
+	}
+}
+
+func testMetrics(t *testing.T, n int) []telegraf.Metric {
+	var metrics []telegraf.Metric
+	for i := 0; i < n; i++ {
+		m, err := metric.New("test",
+			map[string]string{"host": fmt.Sprintf("server%02d", i)},
+			map[string]interface{}{"value": int64(i)},
+			time.Date(2018, 3, 2, 14, 13, 20, 0, time.UTC))
+		require.NoError(t, err)
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+func TestWriteResendsRejectedDocuments(t *testing.T) {
+	es := &fakeElasticsearch{
+		version: "6.8.0",
+		bulkStatuses: func(call int, actions []bulkAction) []int {
+			if call == 0 {
+				// accepted, temporarily rejected, permanently rejected
+				return []int{201, 429, 400}
+			}
+			return []int{201}
+		},
+	}
+	ts := httptest.NewServer(es)
+	defer ts.Close()
+
+	e := newTestElasticsearch(ts.URL)
+	e.Pipeline = "my_pipeline"
+	e.ForceDocumentID = true
+	require.NoError(t, e.Connect())
+
+	metrics := testMetrics(t, 3)
+	require.NoError(t, e.Write(metrics))
+
+	require.Len(t, es.bulkRequests, 2)
+	require.Len(t, es.bulkRequests[0], 3)
+	require.Len(t, es.bulkRequests[1], 1)
+
+	action := es.bulkRequests[1][0]["index"]
+	assert.Equal(t, "test-2018.03.02", action["_index"])
+	assert.Equal(t, "metrics", action["_type"])
+	assert.Equal(t, "my_pipeline", action["pipeline"])
+	assert.Equal(t, documentID(metrics[1]), action["_id"])
+}
+
+func TestWriteFailsAfterMaxRetries(t *testing.T) {
+	es := &fakeElasticsearch{
+		version: "6.8.0",
+		bulkStatuses: func(call int, actions []bulkAction) []int {
+			statuses := make([]int, len(actions))
+			for i := range statuses {
+				statuses[i] = 503
+			}
+			return statuses
+		},
+	}
+	ts := httptest.NewServer(es)
+	defer ts.Close()
+
+	e := newTestElasticsearch(ts.URL)
+	e.MaxRetries = 1
+	require.NoError(t, e.Connect())
+
+	require.Error(t, e.Write(testMetrics(t, 2)))
+	require.Len(t, es.bulkRequests, 2)
+}
+
+func TestWriteDataStream(t *testing.T) {
+	es := &fakeElasticsearch{
+		version: "7.10.0",
+		bulkStatuses: func(call int, actions []bulkAction) []int {
+			return []int{201}
+		},
+		templates: make(map[string]string),
+	}
+	ts := httptest.NewServer(es)
+	defer ts.Close()
+
+	e := newTestElasticsearch(ts.URL)
+	e.IndexName = "metrics-telegraf-{{host}}"
+	e.DataStream = true
+	e.ManageTemplate = true
+	e.TemplateName = "telegraf"
+	e.ILMPolicy = "telegraf-policy"
+	require.NoError(t, e.Connect())
+
+	var tmpl map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(es.templates["telegraf"]), &tmpl))
+	assert.Equal(t, []interface{}{"metrics-telegraf-*"}, tmpl["index_patterns"])
+	assert.Contains(t, tmpl, "data_stream")
+	settings := tmpl["template"].(map[string]interface{})["settings"].(map[string]interface{})
+	assert.Equal(t, "telegraf-policy", settings["index"].(map[string]interface{})["lifecycle.name"])
+
+	require.NoError(t, e.Write(testMetrics(t, 1)))
+
+	require.Len(t, es.bulkRequests, 1)
+	action := es.bulkRequests[0][0]["create"]
+	require.NotNil(t, action)
+	assert.Equal(t, "metrics-telegraf-server00", action["_index"])
+	assert.Equal(t, "_doc", action["_type"])
+}
+
+func TestDataStreamInvalidConfig(t *testing.T) {
+	es := &fakeElasticsearch{version: "6.8.0"}
+	ts := httptest.NewServer(es)
+	defer ts.Close()
+
+	e := newTestElasticsearch(ts.URL)
+	e.DataStream = true
+	require.Error(t, e.Connect())
+
+	e = newTestElasticsearch(ts.URL)
+	e.IndexName = "metrics-telegraf"
+	e.DataStream = true
+	require.Error(t, e.Connect())
+}
+
+func TestDataStreamUnsupportedVersion(t *testing.T) {
+	es := &fakeElasticsearch{version: "7.8.1"}
+	ts := httptest.NewServer(es)
+	defer ts.Close()
+
+	e := newTestElasticsearch(ts.URL)
+	e.IndexName = "metrics-telegraf"
+	e.DataStream = true
+	require.Error(t, e.Connect())
+}
+
+func TestSupportsDataStreams(t *testing.T) {
+	for version, expected := range map[string]bool{
+		"6.8.0":  false,
+		"7.0.0":  false,
+		"7.8.1":  false,
+		"7.9.0":  true,
+		"7.10.2": true,
+		"8.0.0":  true,
+		"7":      false,
+		"x.y.z":  false,
+	} {
+		assert.Equal(t, expected, supportsDataStreams(version), version)
+	}
+}
+
+func TestDocumentID(t *testing.T) {
+	m1 := testMetrics(t, 2)
+	m2 := testMetrics(t, 1)
+
+	assert.Equal(t, documentID(m1[0]), documentID(m2[0]))
+	assert.NotEqual(t, documentID(m1[0]), documentID(m1[1]))
+
+	m3, err := metric.New("test",
+		map[string]string{"host": "server00"},
+		map[string]interface{}{"value": int64(0)},
+		time.Date(2018, 3, 2, 14, 13, 21, 0, time.UTC))
+	require.NoError(t, err)
+	assert.NotEqual(t, documentID(m1[0]), documentID(m3))
+}
@@ -4,66 +4,133 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/rotate"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers"
 )
 
 type File struct {
-	Files []string
-
-	writer  io.Writer
+	Files               []string
+	RotationInterval    internal.Duration `toml:"rotation_interval"`
+	RotationMaxSize     internal.Size     `toml:"rotation_max_size"`
+	RotationMaxArchives int               `toml:"rotation_max_archives"`
+	RotationCompress    bool              `toml:"rotation_compress"`
+	DataFormats         map[string]string `toml:"data_formats"`
+
+	writers []formatWriter
 	closers []io.Closer
 
+	serializer       serializers.Serializer
+	serializerConfig *serializers.Config
+}
+
+// formatWriter writes the metrics in a data format to the files of the format.
+type formatWriter struct {
 	serializer serializers.Serializer
+	writer     io.Writer
 }
 
 var sampleConfig = `
   ## Files to write to, "stdout" is a specially handled file.
   files = ["stdout", "/tmp/metrics.out"]
 
+  ## The file will be rotated after the time interval specified.  When set
+  ## to 0 no time based rotation is performed.
+  # rotation_interval = "0h"
+
+  ## The file will be rotated when it becomes larger than the specified
+  ## size.  When set to 0 no size based rotation is performed.
+  # rotation_max_size = "0MB"
+
+  ## Maximum number of rotated archives to keep, any older files are
+  ## removed.  If set to -1, no archives are removed.
+  # rotation_max_archives = 5
+
+  ## Compress rotated files using gzip.
+  # rotation_compress = false
+
   ## Data format to output.
   ## Each data format has its own unique set of configuration options, read
   ## more about them here:
   ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_OUTPUT.md
   data_format = "influx"
+
+  ## Data formats of some of the files, instead of data_format.  The other
+  ## serializer options are the ones of this output.
+  # [outputs.file.data_formats]
+  #   "/tmp/metrics.json" = "json"
 `
 
 func (f *File) SetSerializer(serializer serializers.Serializer) {
 	f.serializer = serializer
 }
 
+func (f *File) SetSerializerConfig(config *serializers.Config) {
+	f.serializerConfig = config
+}
+
 func (f *File) Connect() error {
-	writers := []io.Writer{}
+	// files of each data format, "" being the format of the output
+	writers := make(map[string][]io.Writer)
 
 	if len(f.Files) == 0 {
 		f.Files = []string{"stdout"}
 	}
 
 	for _, file := range f.Files {
+		format := f.DataFormats[file]
 		if file == "stdout" {
-			writers = append(writers, os.Stdout)
+			writers[format] = append(writers[format], os.Stdout)
 		} else {
-			var of *os.File
-			var err error
-			if _, err := os.Stat(file); os.IsNotExist(err) {
-				of, err = os.Create(file)
-			} else {
-				of, err = os.OpenFile(file, os.O_APPEND|os.O_WRONLY, os.ModeAppend)
-			}
-
+			of, err := rotate.NewFileWriter(
+				file, f.RotationInterval.Duration, f.RotationMaxSize.Size,
+				f.RotationMaxArchives, f.RotationCompress)
 			if err != nil {
 				return err
 			}
-			writers = append(writers, of)
+			writers[format] = append(writers[format], of)
 			f.closers = append(f.closers, of)
 		}
 	}
-	f.writer = io.MultiWriter(writers...)
+
+	f.writers = nil
+	formats := make([]string, 0, len(writers))
+	for format := range writers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+
+	for _, format := range formats {
+		serializer := f.serializer
+		if format != "" {
+			var err error
+			if serializer, err = f.newSerializer(format); err != nil {
+				return err
+			}
+		}
+		f.writers = append(f.writers, formatWriter{
+			serializer: serializer,
+			writer:     io.MultiWriter(writers[format]...),
+		})
+	}
 	return nil
 }
 
+// newSerializer returns a serializer of the data format, with the serializer
+// options of the output.
+func (f *File) newSerializer(format string) (serializers.Serializer, error) {
+	var config serializers.Config
+	if f.serializerConfig != nil {
+		config = *f.serializerConfig
+	}
+	config.DataFormat = format
+	return serializers.NewSerializer(&config)
+}
+
 func (f *File) Close() error {
 	var errS string
 	for _, c := range f.closers {
@@ -90,14 +157,14 @@ func (f *File) Write(metrics []telegraf.Metric) error {
 		return nil
 	}
 
-	for _, metric := range metrics {
-		b, err := f.serializer.Serialize(metric)
+	for _, w := range f.writers {
+		b, err := serializers.SerializeBatch(w.serializer, metrics)
 		if err != nil {
 			return fmt.Errorf("failed to serialize message: %s", err)
 		}
-		_, err = f.writer.Write(b)
+		_, err = w.writer.Write(b)
 		if err != nil {
-			return fmt.Errorf("failed to write message: %s, %s", metric.Serialize(), err)
+			return fmt.Errorf("failed to write message: %s", err)
 		}
 	}
 	return nil
@@ -105,6 +172,8 @@ func (f *File) Write(metrics []telegraf.Metric) error {
 
 func init() {
 	outputs.Add("file", func() telegraf.Output {
-		return &File{}
+		return &File{
+			RotationMaxArchives: rotate.DefaultMaxArchives,
+		}
 	})
 }
@@ -5,13 +5,17 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/serializers"
 	"github.com/influxdata/telegraf/testutil"
+	"github.com/influxdata/toml"
 )
 
 const (
@@ -170,6 +174,81 @@ func TestFileStdout(t *testing.T) {
 	assert.Equal(t, expNewFile, out)
 }
 
+func TestFileRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s, _ := serializers.NewInfluxSerializer()
+	f := File{
+		Files:               []string{filepath.Join(dir, "metrics.out")},
+		RotationMaxSize:     internal.Size{Size: 10},
+		RotationMaxArchives: -1,
+		serializer:          s,
+	}
+
+	err = f.Connect()
+	assert.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		err = f.Write(testutil.MockMetrics())
+		assert.NoError(t, err)
+	}
+
+	err = f.Close()
+	assert.NoError(t, err)
+
+	validateFile(filepath.Join(dir, "metrics.out"), expNewFile, t)
+	archives, err := filepath.Glob(filepath.Join(dir, "metrics.*.out"))
+	assert.NoError(t, err)
+	assert.Len(t, archives, 2)
+}
+
+func TestFileDataFormats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s, _ := serializers.NewInfluxSerializer()
+	f := File{
+		Files: []string{
+			filepath.Join(dir, "metrics.out"),
+			filepath.Join(dir, "metrics.json"),
+		},
+		DataFormats: map[string]string{
+			filepath.Join(dir, "metrics.json"): "json",
+		},
+		serializer:       s,
+		serializerConfig: &serializers.Config{DataFormat: "influx", TimestampUnits: time.Second},
+	}
+
+	err = f.Connect()
+	assert.NoError(t, err)
+
+	err = f.Write(testutil.MockMetrics())
+	assert.NoError(t, err)
+
+	err = f.Close()
+	assert.NoError(t, err)
+
+	validateFile(filepath.Join(dir, "metrics.out"), expNewFile, t)
+	validateFile(filepath.Join(dir, "metrics.json"),
+		`{"fields":{"value":1},"name":"test1","tags":{"tag1":"value1"},"timestamp":1257894000}`+"\n", t)
+}
+
+func TestFileSampleConfig(t *testing.T) {
+	conf := struct {
+		Outputs struct {
+			File []*File
+		}
+	}{}
+	sample := strings.Replace((*File)(nil).SampleConfig(), "# ", "", -1)
+	sample = strings.Replace(sample, `data_format = "influx"`, "", -1)
+	err := toml.Unmarshal([]byte("[[outputs.file]]\n"+sample), &conf)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"/tmp/metrics.json": "json"}, conf.Outputs.File[0].DataFormats)
+}
+
 func createFile() *os.File {
 	f, err := ioutil.TempFile("", "")
 	if err != nil {
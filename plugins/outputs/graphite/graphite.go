@@ -17,11 +17,12 @@ import (
 
 type Graphite struct {
 	// URL is only for backwards compatibility
-	Servers  []string
-	Prefix   string
-	Template string
-	Timeout  int
-	conns    []net.Conn
+	Servers            []string
+	Prefix             string
+	Template           string
+	GraphiteTagSupport bool
+	Timeout            int
+	conns              []net.Conn
 
 	// Path to CA file
 	SSLCA string `toml:"ssl_ca"`
@@ -46,6 +47,11 @@ var sampleConfig = `
   ## Graphite output template
   ## see https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_OUTPUT.md
   template = "host.tags.measurement.field"
+
+  ## Enable Graphite tags support, tags not used by the template are sent as
+  ## Graphite 1.1 tags instead of being inserted into the metric path.
+  # graphite_tag_support = false
+
   ## timeout in seconds for the write connection to graphite
   timeout = 2
 
@@ -142,7 +148,7 @@ func checkEOF(conn net.Conn) {
 func (g *Graphite) Write(metrics []telegraf.Metric) error {
 	// Prepare data
 	var batch []byte
-	s, err := serializers.NewGraphiteSerializer(g.Prefix, g.Template)
+	s, err := serializers.NewGraphiteSerializer(g.Prefix, g.Template, g.GraphiteTagSupport)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,183 @@
+package http
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/plugins/serializers/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getMetric() telegraf.Metric {
+	m, err := metric.New(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{
+			"value": 42.0,
+		},
+		time.Unix(0, 0),
+	)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func newHTTP(url string) *HTTP {
+	return &HTTP{
+		URL:        url,
+		Timeout:    internal.Duration{Duration: time.Second * 5},
+		serializer: &influx.InfluxSerializer{},
+	}
+}
+
+func TestMethod(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		expectedMethod string
+		connectError   bool
+	}{
+		{"default is POST", "", http.MethodPost, false},
+		{"put is okay", "put", http.MethodPut, false},
+		{"get is invalid", http.MethodGet, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var method string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				method = r.Method
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer ts.Close()
+
+			h := newHTTP(ts.URL)
+			h.Method = tt.method
+			err := h.Connect()
+			if tt.connectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			require.NoError(t, h.Write([]telegraf.Metric{getMetric()}))
+			assert.Equal(t, tt.expectedMethod, method)
+		})
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	tests := []struct {
+		name         string
+		statusCode   int
+		successCodes []int
+		errFunc      func(t *testing.T, err error)
+	}{
+		{"200 is success", http.StatusOK, nil, func(t *testing.T, err error) { require.NoError(t, err) }},
+		{"204 is success", http.StatusNoContent, nil, func(t *testing.T, err error) { require.NoError(t, err) }},
+		{"400 is error", http.StatusBadRequest, nil, func(t *testing.T, err error) { require.Error(t, err) }},
+		{"503 is error", http.StatusServiceUnavailable, nil, func(t *testing.T, err error) { require.Error(t, err) }},
+		{"202 not in success codes", http.StatusAccepted, []int{200}, func(t *testing.T, err error) { require.Error(t, err) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer ts.Close()
+
+			h := newHTTP(ts.URL)
+			h.SuccessStatusCodes = tt.successCodes
+			require.NoError(t, h.Connect())
+
+			tt.errFunc(t, h.Write([]telegraf.Metric{getMetric()}))
+		})
+	}
+}
+
+func TestHeadersAndAuth(t *testing.T) {
+	tokenFile, err := ioutil.TempFile("", "bearer")
+	require.NoError(t, err)
+	defer os.Remove(tokenFile.Name())
+	_, err = tokenFile.WriteString("secret-token\n")
+	require.NoError(t, err)
+	tokenFile.Close()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "telegraf", r.Header.Get("X-Source"))
+		assert.Equal(t, "Bearer secret-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "example.org", r.Host)
+
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, `{"metrics":[{"fields":{"value":42},"name":"cpu","tags":{},"timestamp":0}]}`+"\n", string(body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	h := newHTTP(ts.URL)
+	h.serializer = &json.JsonSerializer{Batch: true}
+	h.BearerToken = tokenFile.Name()
+	h.Headers = map[string]string{
+		"Content-Type": "application/json",
+		"X-Source":     "telegraf",
+		"Host":         "example.org",
+	}
+	require.NoError(t, h.Connect())
+	require.NoError(t, h.Write([]telegraf.Metric{getMetric()}))
+}
+
+func TestBasicAuth(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "telegraf", username)
+		assert.Equal(t, "secret", password)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	h := newHTTP(ts.URL)
+	h.Username = "telegraf"
+	h.Password = "secret"
+	require.NoError(t, h.Connect())
+	require.NoError(t, h.Write([]telegraf.Metric{getMetric()}))
+}
+
+func TestContentEncodingGzip(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+
+		gr, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		body, err := ioutil.ReadAll(gr)
+		require.NoError(t, err)
+		assert.Equal(t, "cpu value=42 0\n", string(body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	h := newHTTP(ts.URL)
+	h.ContentEncoding = "gzip"
+	require.NoError(t, h.Connect())
+	require.NoError(t, h.Write([]telegraf.Metric{getMetric()}))
+}
+
+func TestInvalidContentEncoding(t *testing.T) {
+	h := newHTTP("http://localhost")
+	h.ContentEncoding = "deflate"
+	require.Error(t, h.Connect())
+}
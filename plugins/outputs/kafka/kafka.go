@@ -3,6 +3,8 @@ package kafka
 import (
 	"crypto/tls"
 	"fmt"
+	"log"
+	"sort"
 	"strings"
 
 	"github.com/influxdata/telegraf"
@@ -29,12 +31,24 @@ type (
 		TopicSuffix TopicSuffix `toml:"topic_suffix"`
 		// Routing Key Tag
 		RoutingTag string `toml:"routing_tag"`
+		// Tags whose values are joined to build the message key
+		RoutingKeyTags []string `toml:"routing_key_tags"`
+		// Separator between the tag values of the message key
+		RoutingKeySeparator string `toml:"routing_key_separator"`
+		// Static headers added to every message
+		Headers map[string]string `toml:"headers"`
+		// Tags whose values are added as message headers
+		HeaderTags []string `toml:"header_tags"`
+		// Kafka protocol version, ie, "0.11.0.0"
+		Version string `toml:"version"`
 		// Compression Codec Tag
 		CompressionCodec int
 		// RequiredAcks Tag
 		RequiredAcks int
 		// MaxRetry Tag
 		MaxRetry int
+		// Maximum size of a message, larger messages are dropped
+		MaxMessageBytes int `toml:"max_message_bytes"`
 
 		// Legacy SSL config options
 		// TLS client certificate
@@ -108,13 +122,34 @@ var sampleConfig = `
   ##  ie, if this tag exists, its value will be used as the routing key
   routing_tag = "host"
 
+  ## Telegraf tags whose values are joined by the separator to build the
+  ## message key, replaces routing_tag when set.  Missing tags are treated
+  ## as empty strings.
+  # routing_key_tags = ["host", "service"]
+  # routing_key_separator = "."
+
+  ## Telegraf tags whose values are added to each message as headers of the
+  ## same name, along with any static headers set in the headers table below.
+  ## Headers require Kafka 0.11 or later, if version is not set it defaults
+  ## to "0.11.0.0" when headers are used.
+  # header_tags = ["host"]
+
+  ## Kafka protocol version used by the producer, required to use newer
+  ## features such as headers or lz4 compression.
+  # version = "0.11.0.0"
+
   ## CompressionCodec represents the various compression codecs recognized by
   ## Kafka in messages.
   ##  0 : No compression
   ##  1 : Gzip compression
   ##  2 : Snappy compression
+  ##  3 : LZ4 compression, requires version "0.10.0.0" or later
   compression_codec = 0
 
+  ## Maximum size of a message in bytes, should be no larger than the
+  ## message.max.bytes setting of the brokers.  Larger messages are dropped.
+  # max_message_bytes = 1000000
+
   ##  RequiredAcks is used in Produce Requests to tell the broker how many
   ##  replica acknowledgements it must see before responding
   ##   0 : the producer never waits for an acknowledgement from the broker.
@@ -134,6 +169,9 @@ var sampleConfig = `
   ##  The total number of times to retry sending a message
   max_retry = 3
 
+  ## The idempotent producer is not supported, retries may write a message
+  ## more than once.
+
   ## Optional SSL Config
   # ssl_ca = "/etc/telegraf/ca.pem"
   # ssl_cert = "/etc/telegraf/cert.pem"
@@ -150,6 +188,10 @@ var sampleConfig = `
   ## more about them here:
   ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_OUTPUT.md
   data_format = "influx"
+
+  ## Static headers added to each message.
+  # [outputs.kafka.headers]
+  #   source = "telegraf"
 `
 
 func ValidateTopicSuffixMethod(method string) error {
@@ -193,10 +235,28 @@ func (k *Kafka) Connect() error {
 	}
 	config := sarama.NewConfig()
 
+	if k.Version != "" {
+		version, err := sarama.ParseKafkaVersion(k.Version)
+		if err != nil {
+			return err
+		}
+		config.Version = version
+	} else if len(k.Headers) > 0 || len(k.HeaderTags) > 0 {
+		// headers were added in Kafka 0.11
+		config.Version = sarama.V0_11_0_0
+	}
+
+	if k.CompressionCodec < 0 || k.CompressionCodec > int(sarama.CompressionLZ4) {
+		return fmt.Errorf("Unknown compression codec: %d", k.CompressionCodec)
+	}
+
 	config.Producer.RequiredAcks = sarama.RequiredAcks(k.RequiredAcks)
 	config.Producer.Compression = sarama.CompressionCodec(k.CompressionCodec)
 	config.Producer.Retry.Max = k.MaxRetry
 	config.Producer.Return.Successes = true
+	if k.MaxMessageBytes > 0 {
+		config.Producer.MaxMessageBytes = k.MaxMessageBytes
+	}
 
 	// Legacy support ssl config
 	if k.Certificate != "" {
@@ -222,6 +282,10 @@ func (k *Kafka) Connect() error {
 		config.Net.SASL.Enable = true
 	}
 
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
 	producer, err := sarama.NewSyncProducer(k.Brokers, config)
 	if err != nil {
 		return err
@@ -247,36 +311,86 @@ func (k *Kafka) Write(metrics []telegraf.Metric) error {
 		return nil
 	}
 
+	msgs := make([]*sarama.ProducerMessage, 0, len(metrics))
 	for _, metric := range metrics {
-		buf, err := k.serializer.Serialize(metric)
+		m, err := k.buildMessage(metric)
 		if err != nil {
 			return err
 		}
+		msgs = append(msgs, m)
+	}
 
-		topicName := k.GetTopicName(metric)
-
-		m := &sarama.ProducerMessage{
-			Topic: topicName,
-			Value: sarama.ByteEncoder(buf),
-		}
-		if h, ok := metric.Tags()[k.RoutingTag]; ok {
-			m.Key = sarama.StringEncoder(h)
+	err := k.producer.SendMessages(msgs)
+	if errs, ok := err.(sarama.ProducerErrors); ok {
+		// Messages that are too large will never be accepted, drop them
+		// instead of retrying the whole batch forever.
+		for _, prodErr := range errs {
+			if prodErr.Err == sarama.ErrMessageSizeTooLarge {
+				log.Printf("E! Error dropping kafka message to topic %s: %s",
+					prodErr.Msg.Topic, prodErr.Err)
+				continue
+			}
+			return fmt.Errorf("FAILED to send kafka message: %s\n", prodErr.Err)
 		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("FAILED to send kafka message: %s\n", err)
+	}
+	return nil
+}
 
-		_, _, err = k.producer.SendMessage(m)
+func (k *Kafka) buildMessage(metric telegraf.Metric) (*sarama.ProducerMessage, error) {
+	buf, err := k.serializer.Serialize(metric)
+	if err != nil {
+		return nil, err
+	}
 
-		if err != nil {
-			return fmt.Errorf("FAILED to send kafka message: %s\n", err)
+	m := &sarama.ProducerMessage{
+		Topic: k.GetTopicName(metric),
+		Value: sarama.ByteEncoder(buf),
+	}
+
+	tags := metric.Tags()
+	if len(k.RoutingKeyTags) > 0 {
+		values := make([]string, len(k.RoutingKeyTags))
+		for i, tag := range k.RoutingKeyTags {
+			values[i] = tags[tag]
 		}
+		m.Key = sarama.StringEncoder(strings.Join(values, k.RoutingKeySeparator))
+	} else if h, ok := tags[k.RoutingTag]; ok {
+		m.Key = sarama.StringEncoder(h)
 	}
-	return nil
+
+	names := make([]string, 0, len(k.Headers))
+	for name := range k.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		m.Headers = append(m.Headers, sarama.RecordHeader{
+			Key:   []byte(name),
+			Value: []byte(k.Headers[name]),
+		})
+	}
+	for _, tag := range k.HeaderTags {
+		if value, ok := tags[tag]; ok {
+			m.Headers = append(m.Headers, sarama.RecordHeader{
+				Key:   []byte(tag),
+				Value: []byte(value),
+			})
+		}
+	}
+
+	return m, nil
 }
 
 func init() {
 	outputs.Add("kafka", func() telegraf.Output {
 		return &Kafka{
-			MaxRetry:     3,
-			RequiredAcks: -1,
+			MaxRetry:            3,
+			RequiredAcks:        -1,
+			RoutingKeySeparator: ".",
 		}
 	})
 }
@@ -1,8 +1,11 @@
 package kafka
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/Shopify/sarama"
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/serializers"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
@@ -96,3 +99,143 @@ func TestValidateTopicSuffixMethod(t *testing.T) {
 		require.NoError(t, err, "Topic suffix method used should be valid.")
 	}
 }
+
+func TestWriteMockBroker(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("Test", 0, broker.BrokerID()),
+		"ProduceRequest": sarama.NewMockProduceResponse(t),
+	})
+
+	s, _ := serializers.NewInfluxSerializer()
+	k := &Kafka{
+		Brokers:          []string{broker.Addr()},
+		Topic:            "Test",
+		RequiredAcks:     1,
+		CompressionCodec: int(sarama.CompressionGZIP),
+		RoutingKeyTags:   []string{"tag1"},
+		serializer:       s,
+	}
+
+	require.NoError(t, k.Connect())
+	require.NoError(t, k.Write(testutil.MockMetrics()))
+	require.NoError(t, k.Close())
+}
+
+func TestConnectInvalidConfig(t *testing.T) {
+	k := &Kafka{
+		Brokers:          []string{"localhost:9092"},
+		CompressionCodec: 4,
+	}
+	require.Error(t, k.Connect())
+
+	k = &Kafka{
+		Brokers:          []string{"localhost:9092"},
+		CompressionCodec: int(sarama.CompressionLZ4),
+		Version:          "0.9.0.0",
+	}
+	require.Error(t, k.Connect())
+
+	k = &Kafka{
+		Brokers: []string{"localhost:9092"},
+		Version: "invalid",
+	}
+	require.Error(t, k.Connect())
+}
+
+func TestBuildMessage(t *testing.T) {
+	s, _ := serializers.NewInfluxSerializer()
+	metric := testutil.TestMetric(1)
+	metric.AddTag("host", "server01")
+
+	tests := []struct {
+		name    string
+		kafka   *Kafka
+		key     sarama.Encoder
+		headers []sarama.RecordHeader
+	}{
+		{
+			name:  "routing tag",
+			kafka: &Kafka{RoutingTag: "host"},
+			key:   sarama.StringEncoder("server01"),
+		},
+		{
+			name: "routing key tags",
+			kafka: &Kafka{
+				RoutingTag:          "host",
+				RoutingKeyTags:      []string{"host", "missing", "tag1"},
+				RoutingKeySeparator: ".",
+			},
+			key: sarama.StringEncoder("server01..value1"),
+		},
+		{
+			name: "headers",
+			kafka: &Kafka{
+				Headers:    map[string]string{"source": "telegraf", "env": "prod"},
+				HeaderTags: []string{"host", "missing"},
+			},
+			headers: []sarama.RecordHeader{
+				{Key: []byte("env"), Value: []byte("prod")},
+				{Key: []byte("source"), Value: []byte("telegraf")},
+				{Key: []byte("host"), Value: []byte("server01")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.kafka.Topic = "Test"
+			tt.kafka.serializer = s
+
+			m, err := tt.kafka.buildMessage(metric)
+			require.NoError(t, err)
+			require.Equal(t, "Test", m.Topic)
+			require.Equal(t, tt.key, m.Key)
+			require.Equal(t, tt.headers, m.Headers)
+		})
+	}
+}
+
+// errorProducer fails to send messages with the given errors
+type errorProducer struct {
+	errs []error
+}
+
+func (p *errorProducer) SendMessage(msg *sarama.ProducerMessage) (int32, int64, error) {
+	return 0, 0, errors.New("not implemented")
+}
+
+func (p *errorProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
+	var errs sarama.ProducerErrors
+	for i, err := range p.errs {
+		errs = append(errs, &sarama.ProducerError{Msg: msgs[i], Err: err})
+	}
+	return errs
+}
+
+func (p *errorProducer) Close() error {
+	return nil
+}
+
+func TestWriteDropsMessagesTooLarge(t *testing.T) {
+	s, _ := serializers.NewInfluxSerializer()
+	metrics := []telegraf.Metric{testutil.TestMetric(1), testutil.TestMetric(2)}
+
+	k := &Kafka{
+		Topic:      "Test",
+		serializer: s,
+		producer: &errorProducer{
+			errs: []error{sarama.ErrMessageSizeTooLarge},
+		},
+	}
+	require.NoError(t, k.Write(metrics))
+
+	k.producer = &errorProducer{
+		errs: []error{sarama.ErrMessageSizeTooLarge, sarama.ErrNotLeaderForPartition},
+	}
+	require.Error(t, k.Write(metrics))
+}
@@ -0,0 +1,199 @@
+package loki
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+const defaultEndpoint = "/loki/api/v1/push"
+
+var sampleConfig = `
+  ## The domain of Loki
+  domain = "https://loki.domain.tld"
+
+  ## Endpoint to write to
+  # endpoint = "/loki/api/v1/push"
+
+  ## Connection timeout, defaults to "5s" if not set.
+  # timeout = "5s"
+
+  ## Basic auth credential
+  # username = "loki"
+  # password = "pass"
+
+  ## Compress the request body using gzip
+  # gzip_request = false
+
+  ## Optional SSL Config
+  # ssl_ca = "/etc/telegraf/ca.pem"
+  # ssl_cert = "/etc/telegraf/cert.pem"
+  # ssl_key = "/etc/telegraf/key.pem"
+  ## Use SSL but skip chain & host verification
+  # insecure_skip_verify = false
+
+  ## Additional HTTP headers, i.e. to set the tenant with X-Scope-OrgID
+  # [outputs.loki.http_headers]
+  #   X-Scope-OrgID = "telegraf"
+`
+
+type Loki struct {
+	Domain      string            `toml:"domain"`
+	Endpoint    string            `toml:"endpoint"`
+	Timeout     internal.Duration `toml:"timeout"`
+	Username    string            `toml:"username"`
+	Password    string            `toml:"password"`
+	Headers     map[string]string `toml:"http_headers"`
+	GZipRequest bool              `toml:"gzip_request"`
+
+	// Path to CA file
+	SSLCA string `toml:"ssl_ca"`
+	// Path to host cert file
+	SSLCert string `toml:"ssl_cert"`
+	// Path to cert key file
+	SSLKey string `toml:"ssl_key"`
+	// Use SSL but skip chain & host verification
+	InsecureSkipVerify bool
+
+	url    string
+	client *http.Client
+}
+
+func (l *Loki) Description() string {
+	return "Send logs to Loki"
+}
+
+func (l *Loki) SampleConfig() string {
+	return sampleConfig
+}
+
+func (l *Loki) Connect() error {
+	if l.Domain == "" {
+		return fmt.Errorf("domain is required")
+	}
+
+	if l.Endpoint == "" {
+		l.Endpoint = defaultEndpoint
+	}
+	l.url = strings.TrimSuffix(l.Domain, "/") + l.Endpoint
+
+	tlsCfg, err := internal.GetTLSConfig(
+		l.SSLCert, l.SSLKey, l.SSLCA, l.InsecureSkipVerify)
+	if err != nil {
+		return err
+	}
+
+	l.client = &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsCfg,
+			Proxy:           http.ProxyFromEnvironment,
+		},
+		Timeout: l.Timeout.Duration,
+	}
+
+	return nil
+}
+
+func (l *Loki) Close() error {
+	return nil
+}
+
+// Write sends the metrics in a single push request, with one stream per
+// distinct set of tags and measurement name.
+func (l *Loki) Write(metrics []telegraf.Metric) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	s := make(streams)
+	for _, metric := range metrics {
+		s.insert(metric)
+	}
+
+	reqBody, err := json.Marshal(s.request())
+	if err != nil {
+		return fmt.Errorf("failed to marshal push request: %s", err)
+	}
+
+	return l.write(reqBody)
+}
+
+func (l *Loki) write(reqBody []byte) error {
+	var body io.Reader = bytes.NewReader(reqBody)
+	if l.GZipRequest {
+		var err error
+		body, err = compressWithGzip(reqBody)
+		if err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, l.url, body)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Telegraf")
+	if l.GZipRequest {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for k, v := range l.Headers {
+		if strings.ToLower(k) == "host" {
+			req.Host = v
+		} else {
+			req.Header.Set(k, v)
+		}
+	}
+
+	if l.Username != "" || l.Password != "" {
+		req.SetBasicAuth(l.Username, l.Password)
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// include the start of the body, Loki explains rejected pushes there
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("when writing to [%s] received status code: %d: %s",
+			l.url, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	// drain the body so that the connection can be reused
+	ioutil.ReadAll(resp.Body)
+
+	return nil
+}
+
+func compressWithGzip(data []byte) (io.Reader, error) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	if _, err := gw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
+
+func init() {
+	outputs.Add("loki", func() telegraf.Output {
+		return &Loki{
+			Timeout: internal.Duration{Duration: time.Second * 5},
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package loki
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getMetric(t *testing.T) telegraf.Metric {
+	m, err := metric.New("log",
+		map[string]string{"host": "server01"},
+		map[string]interface{}{"message": "started"},
+		time.Unix(0, 10))
+	require.NoError(t, err)
+	return m
+}
+
+type pushRequest struct {
+	Streams []struct {
+		Stream map[string]string `json:"stream"`
+		Values [][]string        `json:"values"`
+	} `json:"streams"`
+}
+
+func TestWrite(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	u, err := url.Parse("http://" + ts.Listener.Addr().String())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		plugin  *Loki
+		handler func(t *testing.T, w http.ResponseWriter, r *http.Request)
+	}{
+		{
+			name:   "default",
+			plugin: &Loki{Domain: u.String()},
+			handler: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "/loki/api/v1/push", r.URL.Path)
+				require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+				require.Empty(t, r.Header.Get("Content-Encoding"))
+
+				var req pushRequest
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				require.Len(t, req.Streams, 1)
+				assert.Equal(t, map[string]string{"__name": "log", "host": "server01"}, req.Streams[0].Stream)
+				assert.Equal(t, [][]string{{"10", `message="started"`}}, req.Streams[0].Values)
+
+				w.WriteHeader(http.StatusNoContent)
+			},
+		},
+		{
+			name: "gzip, auth and headers",
+			plugin: &Loki{
+				Domain:      u.String() + "/",
+				Endpoint:    "/api/prom/push",
+				GZipRequest: true,
+				Username:    "loki",
+				Password:    "secret",
+				Headers:     map[string]string{"X-Scope-OrgID": "telegraf"},
+			},
+			handler: func(t *testing.T, w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "/api/prom/push", r.URL.Path)
+				require.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+				require.Equal(t, "telegraf", r.Header.Get("X-Scope-OrgID"))
+				username, password, ok := r.BasicAuth()
+				require.True(t, ok)
+				require.Equal(t, "loki", username)
+				require.Equal(t, "secret", password)
+
+				var body io.Reader
+				body, err := gzip.NewReader(r.Body)
+				require.NoError(t, err)
+
+				var req pushRequest
+				require.NoError(t, json.NewDecoder(body).Decode(&req))
+				require.Len(t, req.Streams, 1)
+
+				w.WriteHeader(http.StatusNoContent)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.handler(t, w, r)
+			})
+
+			require.NoError(t, tt.plugin.Connect())
+			require.NoError(t, tt.plugin.Write([]telegraf.Metric{getMetric(t)}))
+			require.NoError(t, tt.plugin.Close())
+		})
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("entry out of order\n"))
+	}))
+	defer ts.Close()
+
+	plugin := &Loki{Domain: ts.URL}
+	require.NoError(t, plugin.Connect())
+
+	err := plugin.Write([]telegraf.Metric{getMetric(t)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "400: entry out of order")
+}
+
+func TestConnectRequiresDomain(t *testing.T) {
+	plugin := &Loki{}
+	require.Error(t, plugin.Connect())
+}
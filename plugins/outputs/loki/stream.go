@@ -0,0 +1,146 @@
+package loki
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"github.com/influxdata/telegraf"
+)
+
+// nameLabel is the label holding the measurement name.
+const nameLabel = "__name"
+
+// Request is the body of a push request.
+type Request struct {
+	Streams []Stream `json:"streams"`
+}
+
+// Stream is a set of log lines sharing the same labels.
+type Stream struct {
+	Labels map[string]string `json:"stream"`
+	Logs   []Log             `json:"values"`
+}
+
+// Log is a single log line and its timestamp in nanoseconds.
+type Log struct {
+	Timestamp int64
+	Line      string
+}
+
+// MarshalJSON encodes a log as the [timestamp, line] pair expected by Loki,
+// with the timestamp as a string.
+func (l Log) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]string{strconv.FormatInt(l.Timestamp, 10), l.Line})
+}
+
+// streams groups the log lines of metrics into streams by their labels.
+type streams map[string]*Stream
+
+func (s streams) insert(metric telegraf.Metric) {
+	labels := make(map[string]string, len(metric.Tags())+1)
+	for k, v := range metric.Tags() {
+		labels[sanitizeLabelName(k)] = v
+	}
+	labels[nameLabel] = metric.Name()
+
+	key := streamKey(labels)
+	stream, ok := s[key]
+	if !ok {
+		stream = &Stream{Labels: labels}
+		s[key] = stream
+	}
+	stream.Logs = append(stream.Logs, Log{
+		Timestamp: metric.Time().UnixNano(),
+		Line:      logLine(metric.Fields()),
+	})
+}
+
+// request returns the push request for all streams, sorted by key.  Loki
+// requires the lines of a stream to be in chronological order, so they are
+// sorted by timestamp.
+func (s streams) request() Request {
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	r := Request{Streams: make([]Stream, 0, len(s))}
+	for _, k := range keys {
+		stream := s[k]
+		sort.SliceStable(stream.Logs, func(i, j int) bool {
+			return stream.Logs[i].Timestamp < stream.Logs[j].Timestamp
+		})
+		r.Streams = append(r.Streams, *stream)
+	}
+	return r
+}
+
+// streamKey returns a string identifying the label set.
+func streamKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b bytes.Buffer
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte(0)
+		b.WriteString(labels[k])
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+// logLine formats the fields, sorted by key, as key=value pairs separated by
+// spaces.  String values are quoted.
+func logLine(fields map[string]interface{}) string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b bytes.Buffer
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(k)
+		b.WriteByte('=')
+		switch v := fields[k].(type) {
+		case string:
+			b.WriteString(strconv.Quote(v))
+		case int64:
+			b.WriteString(strconv.FormatInt(v, 10))
+		case uint64:
+			b.WriteString(strconv.FormatUint(v, 10))
+		case float64:
+			b.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+		case bool:
+			b.WriteString(strconv.FormatBool(v))
+		}
+	}
+	return b.String()
+}
+
+// sanitizeLabelName replaces the characters not allowed in label names by
+// underscores, label names must match [a-zA-Z_][a-zA-Z0-9_]*.
+func sanitizeLabelName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		switch {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		default:
+			b[i] = '_'
+		}
+	}
+	if len(b) > 0 && b[0] >= '0' && b[0] <= '9' {
+		return "_" + string(b)
+	}
+	return string(b)
+}
@@ -0,0 +1,52 @@
+package loki
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreams(t *testing.T) {
+	m1, err := metric.New("log",
+		map[string]string{"host": "server01", "log.level": "info"},
+		map[string]interface{}{"message": `user "admin" logged in`, "code": int64(200)},
+		time.Unix(0, 20))
+	require.NoError(t, err)
+	m2, err := metric.New("log",
+		map[string]string{"host": "server01", "log.level": "info"},
+		map[string]interface{}{"message": "started", "ok": true},
+		time.Unix(0, 10))
+	require.NoError(t, err)
+	m3, err := metric.New("log",
+		map[string]string{"host": "server02"},
+		map[string]interface{}{"duration": float64(1.5)},
+		time.Unix(0, 30))
+	require.NoError(t, err)
+
+	s := make(streams)
+	s.insert(m1)
+	s.insert(m2)
+	s.insert(m3)
+
+	actual, err := json.Marshal(s.request())
+	require.NoError(t, err)
+
+	expected := `{"streams":[` +
+		`{"stream":{"__name":"log","host":"server01","log_level":"info"},"values":[` +
+		`["10","message=\"started\" ok=true"],` +
+		`["20","code=200 message=\"user \\\"admin\\\" logged in\""]]},` +
+		`{"stream":{"__name":"log","host":"server02"},"values":[` +
+		`["30","duration=1.5"]]}]}`
+	assert.Equal(t, expected, string(actual))
+}
+
+func TestSanitizeLabelName(t *testing.T) {
+	assert.Equal(t, "host", sanitizeLabelName("host"))
+	assert.Equal(t, "log_level", sanitizeLabelName("log.level"))
+	assert.Equal(t, "_1st", sanitizeLabelName("1st"))
+	assert.Equal(t, "a1_b", sanitizeLabelName("a1-b"))
+}
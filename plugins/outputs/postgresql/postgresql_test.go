@@ -0,0 +1,105 @@
+package postgresql
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newPostgresql(t *testing.T, schema string) *Postgresql {
+	p := &Postgresql{
+		Address: fmt.Sprintf("host=%s user=postgres sslmode=disable",
+			testutil.GetLocalHost()),
+		Schema:         schema,
+		TableTemplate:  defaultTableTemplate,
+		TagTableSuffix: defaultTagTableSuffix,
+	}
+	require.NoError(t, p.Connect())
+
+	_, err := p.db.Exec("DROP SCHEMA IF EXISTS " + quoteIdent(schema) + " CASCADE")
+	require.NoError(t, err)
+	_, err = p.db.Exec("CREATE SCHEMA " + quoteIdent(schema))
+	require.NoError(t, err)
+	return p
+}
+
+func newMetric(tags map[string]string, fields map[string]interface{}) telegraf.Metric {
+	m, err := metric.New("cpu", tags, fields, time.Unix(1500000000, 0))
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func TestWriteCreatesAndAltersTable(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	p := newPostgresql(t, "telegraf_test")
+	defer p.Close()
+
+	require.NoError(t, p.Write([]telegraf.Metric{
+		newMetric(map[string]string{"host": "a"}, map[string]interface{}{"usage_idle": 90.5}),
+	}))
+	require.NoError(t, p.Write([]telegraf.Metric{
+		newMetric(map[string]string{"host": "b", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": int64(80), "state": "ok"}),
+	}))
+
+	columns, err := p.loadColumns("cpu")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"time":       pgTimestamp,
+		"host":       pgText,
+		"cpu":        pgText,
+		"usage_idle": pgDouble,
+		"state":      pgText,
+	}, columns)
+
+	var count int
+	err = p.db.QueryRow(`SELECT count(*) FROM "telegraf_test"."cpu" WHERE "usage_idle" = 80 AND "cpu" = 'cpu0'`).Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestWriteTagsAsForeignKeys(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	p := newPostgresql(t, "telegraf_test_fk")
+	p.TagsAsForeignKeys = true
+	defer p.Close()
+
+	metrics := []telegraf.Metric{
+		newMetric(map[string]string{"host": "a"}, map[string]interface{}{"value": 1.0}),
+		newMetric(map[string]string{"host": "a"}, map[string]interface{}{"value": 2.0}),
+		newMetric(map[string]string{"host": "b"}, map[string]interface{}{"value": 3.0}),
+	}
+	require.NoError(t, p.Write(metrics))
+
+	var count int
+	err := p.db.QueryRow(`SELECT count(*) FROM "telegraf_test_fk"."cpu_tag"`).Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	err = p.db.QueryRow(`SELECT count(*) FROM information_schema.table_constraints
+		WHERE table_schema = $1 AND table_name = $2 AND constraint_type = 'FOREIGN KEY'`,
+		"telegraf_test_fk", "cpu").Scan(&count)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	var sum float64
+	err = p.db.QueryRow(`SELECT sum(value) FROM "telegraf_test_fk"."cpu" c
+		JOIN "telegraf_test_fk"."cpu_tag" t ON c.tag_id = t.tag_id WHERE t.host = $1`, "a").Scan(&sum)
+	require.NoError(t, err)
+	assert.Equal(t, 3.0, sum)
+}
@@ -0,0 +1,180 @@
+package postgresql
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx"
+)
+
+const (
+	timeColumnName  = "time"
+	tagIDColumnName = "tag_id"
+
+	pgTimestamp = "timestamp with time zone"
+	pgBigInt    = "bigint"
+	pgNumeric   = "numeric"
+	pgDouble    = "double precision"
+	pgText      = "text"
+	pgBool      = "boolean"
+)
+
+// column is a column of a metrics or tags table.
+type column struct {
+	Name string
+	Type string
+	// References is the quoted table and column referenced by the column,
+	// such as "public"."cpu_tag"("tag_id"), if it is a foreign key.
+	References string
+}
+
+// quoteIdent quotes a single PostgreSQL identifier.
+func quoteIdent(name string) string {
+	return pgx.Identifier{name}.Sanitize()
+}
+
+// quoteLiteral quotes a string as a PostgreSQL literal.
+func quoteLiteral(str string) string {
+	return "'" + strings.Replace(str, "'", "''", -1) + "'"
+}
+
+// fullTableName returns the quoted, schema qualified name of a table.
+func fullTableName(schema, table string) string {
+	return pgx.Identifier{schema, table}.Sanitize()
+}
+
+// columnType returns the type of the column used to store the value, or the
+// empty string if the value cannot be stored.
+func columnType(value interface{}) string {
+	switch value.(type) {
+	case int64:
+		return pgBigInt
+	case uint64:
+		return pgNumeric
+	case float64:
+		return pgDouble
+	case string:
+		return pgText
+	case bool:
+		return pgBool
+	case time.Time:
+		return pgTimestamp
+	}
+	return ""
+}
+
+// compatibleTypes reports if a value that would be stored in a column of type
+// valueType can be written to a column of type colType.  Any number can be
+// written to a floating point or numeric column and integers to integer
+// columns, all other types must match.
+func compatibleTypes(colType, valueType string) bool {
+	if colType == valueType {
+		return true
+	}
+	switch colType {
+	case pgDouble, pgNumeric, "real":
+		return valueType == pgBigInt || valueType == pgNumeric || valueType == pgDouble
+	case pgBigInt, "integer", "smallint":
+		return valueType == pgBigInt
+	}
+	return false
+}
+
+func columnDefinition(col column) string {
+	def := quoteIdent(col.Name) + " " + col.Type
+	if col.References != "" {
+		def += " REFERENCES " + col.References
+	}
+	return def
+}
+
+func columnDefinitions(columns []column) string {
+	defs := make([]string, len(columns))
+	for i, col := range columns {
+		defs[i] = columnDefinition(col)
+	}
+	return strings.Join(defs, ", ")
+}
+
+// createTableSQL generates the statement creating a metrics table from the
+// template, replacing the {TABLE}, {TABLELITERAL} and {COLUMNS} keywords.
+func createTableSQL(template, schema, table string, columns []column) string {
+	fullName := fullTableName(schema, table)
+	r := strings.NewReplacer(
+		"{TABLE}", fullName,
+		"{TABLELITERAL}", quoteLiteral(fullName),
+		"{COLUMNS}", columnDefinitions(columns),
+	)
+	return r.Replace(template)
+}
+
+// tagIDReferences returns the reference of the tag_id column of a metrics
+// table to the tag_id of its tags table.
+func tagIDReferences(schema, tagTable string) string {
+	return fullTableName(schema, tagTable) + "(" + quoteIdent(tagIDColumnName) + ")"
+}
+
+// createTagTableSQL generates the statement creating a tags table.
+func createTagTableSQL(schema, table string, columns []column) string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s(%s, PRIMARY KEY (%s))",
+		fullTableName(schema, table),
+		columnDefinitions(columns),
+		quoteIdent(tagIDColumnName))
+}
+
+// addColumnsSQL generates the statement adding the columns to a table.
+func addColumnsSQL(schema, table string, columns []column) string {
+	adds := make([]string, len(columns))
+	for i, col := range columns {
+		adds[i] = "ADD COLUMN IF NOT EXISTS " + columnDefinition(col)
+	}
+	return fmt.Sprintf("ALTER TABLE %s %s",
+		fullTableName(schema, table), strings.Join(adds, ", "))
+}
+
+// insertTagsSQL generates the statement inserting rows rows of tags, skipping
+// tag sets that already exist.
+func insertTagsSQL(schema, table string, columns []string, rows int) string {
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = quoteIdent(col)
+	}
+
+	values := make([]string, rows)
+	placeholders := make([]string, len(columns))
+	for i := 0; i < rows; i++ {
+		for j := range columns {
+			placeholders[j] = fmt.Sprintf("$%d", i*len(columns)+j+1)
+		}
+		values[i] = "(" + strings.Join(placeholders, ",") + ")"
+	}
+
+	return fmt.Sprintf("INSERT INTO %s(%s) VALUES %s ON CONFLICT (%s) DO NOTHING",
+		fullTableName(schema, table),
+		strings.Join(quoted, ","),
+		strings.Join(values, ","),
+		quoteIdent(tagIDColumnName))
+}
+
+// tagID returns an identifier for a tag set.
+func tagID(tags map[string]string) int64 {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha512.New()
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write([]byte("\n"))
+		h.Write([]byte(tags[k]))
+		h.Write([]byte("\n"))
+	}
+	sum := h.Sum(nil)
+	return int64(binary.LittleEndian.Uint64(sum))
+}
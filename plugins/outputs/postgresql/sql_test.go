@@ -0,0 +1,83 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateTableSQL(t *testing.T) {
+	columns := []column{
+		{Name: "time", Type: pgTimestamp},
+		{Name: "host", Type: pgText},
+		{Name: "usage \"idle\"", Type: pgDouble},
+	}
+
+	assert.Equal(t,
+		`CREATE TABLE IF NOT EXISTS "public"."cpu"("time" timestamp with time zone, "host" text, "usage ""idle""" double precision)`,
+		createTableSQL(defaultTableTemplate, "public", "cpu", columns))
+
+	assert.Equal(t,
+		`SELECT create_hypertable('"metrics"."cpu"', 'time')`,
+		createTableSQL("SELECT create_hypertable({TABLELITERAL}, 'time')", "metrics", "cpu", columns))
+}
+
+func TestCreateTableSQL_foreignKey(t *testing.T) {
+	columns := []column{
+		{Name: "time", Type: pgTimestamp},
+		{Name: "tag_id", Type: pgBigInt, References: tagIDReferences("public", "cpu_tag")},
+	}
+
+	assert.Equal(t,
+		`CREATE TABLE IF NOT EXISTS "public"."cpu"("time" timestamp with time zone, "tag_id" bigint REFERENCES "public"."cpu_tag"("tag_id"))`,
+		createTableSQL(defaultTableTemplate, "public", "cpu", columns))
+
+	assert.Equal(t,
+		`ALTER TABLE "public"."cpu" ADD COLUMN IF NOT EXISTS "tag_id" bigint REFERENCES "public"."cpu_tag"("tag_id")`,
+		addColumnsSQL("public", "cpu", columns[1:]))
+}
+
+func TestCreateTagTableSQL(t *testing.T) {
+	columns := []column{
+		{Name: "tag_id", Type: pgBigInt},
+		{Name: "host", Type: pgText},
+	}
+
+	assert.Equal(t,
+		`CREATE TABLE IF NOT EXISTS "public"."cpu_tag"("tag_id" bigint, "host" text, PRIMARY KEY ("tag_id"))`,
+		createTagTableSQL("public", "cpu_tag", columns))
+}
+
+func TestAddColumnsSQL(t *testing.T) {
+	columns := []column{
+		{Name: "usage_user", Type: pgDouble},
+		{Name: "online", Type: pgBool},
+	}
+
+	assert.Equal(t,
+		`ALTER TABLE "public"."cpu" ADD COLUMN IF NOT EXISTS "usage_user" double precision, ADD COLUMN IF NOT EXISTS "online" boolean`,
+		addColumnsSQL("public", "cpu", columns))
+}
+
+func TestInsertTagsSQL(t *testing.T) {
+	assert.Equal(t,
+		`INSERT INTO "public"."cpu_tag"("tag_id","host") VALUES ($1,$2),($3,$4) ON CONFLICT ("tag_id") DO NOTHING`,
+		insertTagsSQL("public", "cpu_tag", []string{"tag_id", "host"}, 2))
+}
+
+func TestCompatibleTypes(t *testing.T) {
+	assert.True(t, compatibleTypes(pgText, pgText))
+	assert.True(t, compatibleTypes(pgDouble, pgBigInt))
+	assert.True(t, compatibleTypes(pgNumeric, pgDouble))
+	assert.False(t, compatibleTypes(pgBigInt, pgDouble))
+	assert.False(t, compatibleTypes(pgDouble, pgText))
+	assert.False(t, compatibleTypes(pgBool, pgBigInt))
+}
+
+func TestTagID(t *testing.T) {
+	a := tagID(map[string]string{"host": "a", "cpu": "cpu0"})
+	b := tagID(map[string]string{"cpu": "cpu0", "host": "a"})
+	c := tagID(map[string]string{"host": "acpu", "": "0"})
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+}
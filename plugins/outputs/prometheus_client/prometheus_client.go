@@ -5,6 +5,7 @@ import (
 	"crypto/subtle"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"regexp"
@@ -17,12 +18,28 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/outputs"
+	serializer "github.com/influxdata/telegraf/plugins/serializers/prometheus"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 var invalidNameCharRE = regexp.MustCompile(`[^a-zA-Z0-9_]`)
 
+const (
+	// bucketTag is the tag holding the upper bound of a histogram bucket,
+	// as added by the histogram aggregator.
+	bucketTag = "le"
+	// bucketSuffix is the suffix of the fields holding a bucket count.
+	bucketSuffix = "_bucket"
+	// quantileTag is the tag holding the quantile of a summary sample with
+	// metric_version 2.
+	quantileTag = "quantile"
+	// sumSuffix and countSuffix are the suffixes of the fields holding the
+	// sum and count of a histogram or summary with metric_version 2.
+	sumSuffix   = "_sum"
+	countSuffix = "_count"
+)
+
 // SampleID uniquely identifies a Sample
 type SampleID string
 
@@ -62,6 +79,7 @@ type PrometheusClient struct {
 	Path               string            `toml:"path"`
 	CollectorsExclude  []string          `toml:"collectors_exclude"`
 	StringAsLabel      bool              `toml:"string_as_label"`
+	MetricVersion      int               `toml:"metric_version"`
 
 	server *http.Server
 
@@ -94,6 +112,13 @@ var sampleConfig = `
   # Send string metrics as Prometheus labels.
   # Unless set to false all string metrics will be sent as labels.
   string_as_label = true
+
+  ## Version of the metrics.  With version 2 the measurement name is ignored
+  ## and each field is exposed under its name, with the "le" and "quantile"
+  ## tags and "_bucket", "_sum" and "_count" fields of histograms and
+  ## summaries combined, as added by the prometheus input with
+  ## metric_version = 2.
+  # metric_version = 1
 `
 
 func (p *PrometheusClient) basicAuth(h http.Handler) http.Handler {
@@ -259,6 +284,7 @@ func (p *PrometheusClient) Collect(ch chan<- prometheus.Metric) {
 				log.Printf("E! Error creating prometheus metric, "+
 					"key: %s, labels: %v,\nerr: %s\n",
 					name, labels, err.Error())
+				continue
 			}
 
 			ch <- metric
@@ -301,18 +327,151 @@ func addSample(fam *MetricFamily, sample *Sample, sampleID SampleID) {
 }
 
 func (p *PrometheusClient) addMetricFamily(point telegraf.Metric, sample *Sample, mname string, sampleID SampleID) {
-	var fam *MetricFamily
-	var ok bool
-	if fam, ok = p.fam[mname]; !ok {
+	fam := p.getMetricFamily(mname, point.Type())
+	addSample(fam, sample, sampleID)
+}
+
+// getMetricFamily returns the family with the name, creating it if needed.
+// Histograms and summaries cannot share a family with samples of another
+// type, so an existing family is replaced when either type is a histogram or
+// summary and the types differ.
+func (p *PrometheusClient) getMetricFamily(mname string, valueType telegraf.ValueType) *MetricFamily {
+	fam, ok := p.fam[mname]
+	if ok && fam.TelegrafValueType != valueType &&
+		(isDistribution(fam.TelegrafValueType) || isDistribution(valueType)) {
+		ok = false
+	}
+	if !ok {
 		fam = &MetricFamily{
 			Samples:           make(map[SampleID]*Sample),
-			TelegrafValueType: point.Type(),
+			TelegrafValueType: valueType,
 			LabelSet:          make(map[string]int),
 		}
 		p.fam[mname] = fam
 	}
+	return fam
+}
 
-	addSample(fam, sample, sampleID)
+// addBuckets adds the buckets of a metric with the bucket upper bound in the
+// "le" tag and the cumulative counts in fields with the "_bucket" suffix, as
+// created by the histogram aggregator, to histogram families named after the
+// measurement and field.  The count of a histogram is the count of its +Inf
+// bucket.
+func (p *PrometheusClient) addBuckets(point telegraf.Metric, labels map[string]string, bound float64, expiration time.Time) {
+	tags := point.Tags()
+	delete(tags, bucketTag)
+	sampleID := CreateSampleID(tags)
+
+	delete(labels, bucketTag)
+
+	for fn, fv := range point.Fields() {
+		if !strings.HasSuffix(fn, bucketSuffix) {
+			continue
+		}
+		value, ok := serializer.SampleValue(fv)
+		if !ok {
+			continue
+		}
+
+		mname := sanitize(fmt.Sprintf("%s_%s", point.Name(), strings.TrimSuffix(fn, bucketSuffix)))
+		fam := p.getMetricFamily(mname, telegraf.Histogram)
+
+		sample, ok := fam.Samples[sampleID]
+		if !ok {
+			sample = &Sample{
+				Labels:         labels,
+				HistogramValue: make(map[float64]uint64),
+			}
+			addSample(fam, sample, sampleID)
+		}
+		sample.HistogramValue[bound] = uint64(value)
+		if math.IsInf(bound, 1) {
+			sample.Count = uint64(value)
+		}
+		sample.Expiration = expiration
+	}
+}
+
+// addMetricV2 adds the fields of a metric to the families named after the
+// fields.  The samples of a histogram or summary, told apart by the "le" or
+// "quantile" tag and the "_bucket", "_sum" or "_count" suffix of the field,
+// are combined into a single sample of the family.
+func (p *PrometheusClient) addMetricV2(point telegraf.Metric, labels map[string]string, expiration time.Time) {
+	tags := point.Tags()
+	le, isBucket := tags[bucketTag]
+	quantile, isQuantile := tags[quantileTag]
+	delete(tags, bucketTag)
+	delete(tags, quantileTag)
+	sampleID := CreateSampleID(tags)
+
+	delete(labels, bucketTag)
+	delete(labels, quantileTag)
+
+	for fn, fv := range point.Fields() {
+		value, ok := serializer.SampleValue(fv)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case isBucket && strings.HasSuffix(fn, bucketSuffix):
+			bound, err := strconv.ParseFloat(le, 64)
+			if err != nil {
+				continue
+			}
+			mname := sanitize(strings.TrimSuffix(fn, bucketSuffix))
+			sample := p.getDistributionSample(mname, telegraf.Histogram, labels, sampleID)
+			sample.HistogramValue[bound] = uint64(value)
+			sample.Expiration = expiration
+		case isQuantile && point.Type() != telegraf.Histogram:
+			q, err := strconv.ParseFloat(quantile, 64)
+			if err != nil {
+				continue
+			}
+			sample := p.getDistributionSample(sanitize(fn), telegraf.Summary, labels, sampleID)
+			sample.SummaryValue[q] = value
+			sample.Expiration = expiration
+		case isDistribution(point.Type()) && strings.HasSuffix(fn, sumSuffix):
+			sample := p.getDistributionSample(sanitize(strings.TrimSuffix(fn, sumSuffix)), point.Type(), labels, sampleID)
+			sample.Sum = value
+			sample.Expiration = expiration
+		case isDistribution(point.Type()) && strings.HasSuffix(fn, countSuffix):
+			sample := p.getDistributionSample(sanitize(strings.TrimSuffix(fn, countSuffix)), point.Type(), labels, sampleID)
+			sample.Count = uint64(value)
+			sample.Expiration = expiration
+		default:
+			valueType := point.Type()
+			if isDistribution(valueType) {
+				valueType = telegraf.Untyped
+			}
+			sample := &Sample{
+				Labels:     labels,
+				Value:      value,
+				Expiration: expiration,
+			}
+			addSample(p.getMetricFamily(sanitize(fn), valueType), sample, sampleID)
+		}
+	}
+}
+
+// getDistributionSample returns the sample of the histogram or summary family,
+// creating both if needed.
+func (p *PrometheusClient) getDistributionSample(mname string, valueType telegraf.ValueType, labels map[string]string, sampleID SampleID) *Sample {
+	fam := p.getMetricFamily(mname, valueType)
+	sample, ok := fam.Samples[sampleID]
+	if !ok {
+		sample = &Sample{
+			Labels:         labels,
+			HistogramValue: make(map[float64]uint64),
+			SummaryValue:   make(map[float64]float64),
+		}
+		addSample(fam, sample, sampleID)
+	}
+	return sample
+}
+
+func isDistribution(valueType telegraf.ValueType) bool {
+	return valueType == telegraf.Histogram || valueType == telegraf.Summary
 }
 
 func (p *PrometheusClient) Write(metrics []telegraf.Metric) error {
@@ -341,6 +500,20 @@ func (p *PrometheusClient) Write(metrics []telegraf.Metric) error {
 			}
 		}
 
+		if p.MetricVersion == 2 {
+			p.addMetricV2(point, labels, now.Add(p.ExpirationInterval.Duration))
+			continue
+		}
+
+		// Histogram buckets split over several metrics by the bucket tag
+		if le, ok := tags[bucketTag]; ok && (point.Type() == telegraf.Histogram || point.Type() == telegraf.Untyped) {
+			bound, err := strconv.ParseFloat(le, 64)
+			if err == nil {
+				p.addBuckets(point, labels, bound, now.Add(p.ExpirationInterval.Duration))
+				continue
+			}
+		}
+
 		switch point.Type() {
 		case telegraf.Summary:
 			var mname string
@@ -348,13 +521,8 @@ func (p *PrometheusClient) Write(metrics []telegraf.Metric) error {
 			var count uint64
 			summaryvalue := make(map[float64]float64)
 			for fn, fv := range point.Fields() {
-				var value float64
-				switch fv := fv.(type) {
-				case int64:
-					value = float64(fv)
-				case float64:
-					value = fv
-				default:
+				value, ok := serializer.SampleValue(fv)
+				if !ok {
 					continue
 				}
 
@@ -387,13 +555,8 @@ func (p *PrometheusClient) Write(metrics []telegraf.Metric) error {
 			var count uint64
 			histogramvalue := make(map[float64]uint64)
 			for fn, fv := range point.Fields() {
-				var value float64
-				switch fv := fv.(type) {
-				case int64:
-					value = float64(fv)
-				case float64:
-					value = fv
-				default:
+				value, ok := serializer.SampleValue(fv)
+				if !ok {
 					continue
 				}
 
@@ -423,13 +586,8 @@ func (p *PrometheusClient) Write(metrics []telegraf.Metric) error {
 		default:
 			for fn, fv := range point.Fields() {
 				// Ignore string and bool fields.
-				var value float64
-				switch fv := fv.(type) {
-				case int64:
-					value = float64(fv)
-				case float64:
-					value = fv
-				default:
+				value, ok := serializer.SampleValue(fv)
+				if !ok {
 					continue
 				}
 
@@ -1,6 +1,8 @@
 package prometheus_client
 
 import (
+	"math"
+	"net/http"
 	"testing"
 	"time"
 
@@ -311,6 +313,68 @@ func TestWrite_Histogram(t *testing.T) {
 	require.Equal(t, 3, len(sample1.HistogramValue))
 }
 
+func TestWrite_HistogramBucketTags(t *testing.T) {
+	client := NewClient()
+
+	var metrics []telegraf.Metric
+	for le, count := range map[string]int64{"10": 1, "50": 3, "+Inf": 4} {
+		m, err := metric.New(
+			"http",
+			map[string]string{"host": "a", "le": le},
+			map[string]interface{}{"latency_bucket": count, "other": 1.0},
+			time.Now())
+		require.NoError(t, err)
+		metrics = append(metrics, m)
+	}
+
+	err := client.Write(metrics)
+	require.NoError(t, err)
+
+	fam, ok := client.fam["http_latency"]
+	require.True(t, ok)
+	require.Equal(t, telegraf.Histogram, fam.TelegrafValueType)
+	require.Equal(t, map[string]int{"host": 1}, fam.LabelSet)
+	require.Equal(t, 1, len(fam.Samples))
+
+	sample, ok := fam.Samples[CreateSampleID(map[string]string{"host": "a"})]
+	require.True(t, ok)
+	require.Equal(t, uint64(4), sample.Count)
+	require.Equal(t, map[float64]uint64{10: 1, 50: 3, math.Inf(1): 4}, sample.HistogramValue)
+
+	_, ok = client.fam["http_other"]
+	require.False(t, ok)
+}
+
+func TestWrite_HistogramReplacesUntyped(t *testing.T) {
+	client := NewClient()
+
+	p1, err := metric.New(
+		"foo",
+		make(map[string]string),
+		map[string]interface{}{"value": 1.0},
+		time.Now())
+	require.NoError(t, err)
+	p2, err := metric.New(
+		"foo",
+		make(map[string]string),
+		map[string]interface{}{"sum": 84, "count": 42, "0.5": 3, "1": uint64(4)},
+		time.Now(),
+		telegraf.Histogram)
+	require.NoError(t, err)
+
+	err = client.Write([]telegraf.Metric{p1, p2})
+	require.NoError(t, err)
+
+	fam, ok := client.fam["foo"]
+	require.True(t, ok)
+	require.Equal(t, telegraf.Histogram, fam.TelegrafValueType)
+	require.Equal(t, 1, len(fam.Samples))
+
+	sample, ok := fam.Samples[CreateSampleID(p2.Tags())]
+	require.True(t, ok)
+	require.Equal(t, map[float64]uint64{0.5: 3, 1: 4}, sample.HistogramValue)
+}
+
 func TestWrite_MixedValueType(t *testing.T) {
 	now := time.Now()
 	p1, err := metric.New(
@@ -386,6 +450,76 @@ func TestWrite_MixedValueTypeDowngrade(t *testing.T) {
 	require.Equal(t, 2, len(fam.Samples))
 }
 
+func TestWrite_MetricVersion2(t *testing.T) {
+	client := NewClient()
+	client.MetricVersion = 2
+
+	var metrics []telegraf.Metric
+	add := func(tags map[string]string, fields map[string]interface{}, vt telegraf.ValueType) {
+		m, err := metric.New("prometheus", tags, fields, time.Now(), vt)
+		require.NoError(t, err)
+		metrics = append(metrics, m)
+	}
+	add(map[string]string{"host": "a"}, map[string]interface{}{"go_goroutines": 15.0}, telegraf.Gauge)
+	add(map[string]string{"host": "a", "le": "0.5"}, map[string]interface{}{"latency_bucket": 2.0}, telegraf.Histogram)
+	add(map[string]string{"host": "a", "le": "+Inf"}, map[string]interface{}{"latency_bucket": 3.0}, telegraf.Histogram)
+	add(map[string]string{"host": "a"}, map[string]interface{}{"latency_sum": 1.5}, telegraf.Histogram)
+	add(map[string]string{"host": "a"}, map[string]interface{}{"latency_count": 3.0}, telegraf.Histogram)
+	add(map[string]string{"host": "a", "quantile": "0.5"}, map[string]interface{}{"gc": 0.1}, telegraf.Summary)
+	add(map[string]string{"host": "a"}, map[string]interface{}{"gc_sum": 0.4}, telegraf.Summary)
+	add(map[string]string{"host": "a"}, map[string]interface{}{"gc_count": 4.0}, telegraf.Summary)
+
+	require.NoError(t, client.Write(metrics))
+	require.Len(t, client.fam, 3)
+	sampleID := CreateSampleID(map[string]string{"host": "a"})
+
+	fam, ok := client.fam["go_goroutines"]
+	require.True(t, ok)
+	require.Equal(t, telegraf.Gauge, fam.TelegrafValueType)
+	require.Equal(t, 15.0, fam.Samples[sampleID].Value)
+
+	fam, ok = client.fam["latency"]
+	require.True(t, ok)
+	require.Equal(t, telegraf.Histogram, fam.TelegrafValueType)
+	require.Equal(t, map[string]int{"host": 1}, fam.LabelSet)
+	sample := fam.Samples[sampleID]
+	require.Equal(t, map[float64]uint64{0.5: 2, math.Inf(1): 3}, sample.HistogramValue)
+	require.Equal(t, 1.5, sample.Sum)
+	require.Equal(t, uint64(3), sample.Count)
+
+	fam, ok = client.fam["gc"]
+	require.True(t, ok)
+	require.Equal(t, telegraf.Summary, fam.TelegrafValueType)
+	sample = fam.Samples[sampleID]
+	require.Equal(t, map[float64]float64{0.5: 0.1}, sample.SummaryValue)
+	require.Equal(t, 0.4, sample.Sum)
+	require.Equal(t, uint64(4), sample.Count)
+}
+
+func TestWrite_MetricVersion2RoundTrip(t *testing.T) {
+	client := NewClient()
+	client.MetricVersion = 2
+
+	input := `# TYPE apiserver_request_latencies histogram
+apiserver_request_latencies_bucket{verb="POST",le="125000"} 1994
+apiserver_request_latencies_bucket{verb="POST",le="+Inf"} 2025
+apiserver_request_latencies_sum{verb="POST"} 1.02726334e+08
+apiserver_request_latencies_count{verb="POST"} 2025
+`
+	metrics, err := prometheus_input.ParseV2([]byte(input), http.Header{})
+	require.NoError(t, err)
+	require.NoError(t, client.Write(metrics))
+
+	require.Len(t, client.fam, 1)
+	fam, ok := client.fam["apiserver_request_latencies"]
+	require.True(t, ok)
+	require.Equal(t, telegraf.Histogram, fam.TelegrafValueType)
+	sample := fam.Samples[CreateSampleID(map[string]string{"verb": "POST"})]
+	require.Equal(t, map[float64]uint64{125000: 1994, math.Inf(1): 2025}, sample.HistogramValue)
+	require.Equal(t, 1.02726334e+08, sample.Sum)
+	require.Equal(t, uint64(2025), sample.Count)
+}
+
 func TestWrite_Tags(t *testing.T) {
 	now := time.Now()
 	p1, err := metric.New(
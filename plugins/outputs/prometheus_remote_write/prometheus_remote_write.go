@@ -0,0 +1,344 @@
+package prometheus_remote_write
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/snappy"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/plugins/serializers/prometheus"
+)
+
+const (
+	remoteWriteVersion = "0.1.0"
+	userAgent          = "Telegraf"
+)
+
+var sampleConfig = `
+  ## URL of the remote write endpoint
+  url = "http://localhost:9201/write"
+
+  ## Timeout for HTTP requests
+  # timeout = "5s"
+
+  ## Additional HTTP headers
+  # headers = {"X-Scope-OrgID" = "telegraf"}
+
+  ## Optional HTTP Basic Auth Credentials
+  # username = "username"
+  # password = "pa$$word"
+
+  ## Use bearer token for authorization, read from the given file on
+  ## each request
+  # bearer_token = "/path/to/bearer/token"
+
+  ## Optional SSL Config
+  # ssl_ca = "/etc/telegraf/ca.pem"
+  # ssl_cert = "/etc/telegraf/cert.pem"
+  # ssl_key = "/etc/telegraf/key.pem"
+  ## Use SSL but skip chain & host verification
+  # insecure_skip_verify = false
+
+  ## Send string metrics as Prometheus labels.
+  ## Unless set to false all string metrics will be sent as labels.
+  # string_as_label = true
+
+  ## Number of times a write failing with a recoverable error, a network
+  ## error, a 5xx or a 429 response, is retried before the metrics are left
+  ## in the buffer for the next flush.  Writes failing with any other status
+  ## code are dropped.
+  # max_retries = 3
+
+  ## Backoff between retries, doubled on each retry up to max_backoff
+  # min_backoff = "100ms"
+  # max_backoff = "1s"
+`
+
+type PrometheusRemoteWrite struct {
+	URL     string            `toml:"url"`
+	Timeout internal.Duration `toml:"timeout"`
+	Headers map[string]string `toml:"headers"`
+
+	// HTTP Basic Auth Credentials
+	Username string `toml:"username"`
+	Password string `toml:"password"`
+
+	// Bearer Token authorization file path
+	BearerToken string `toml:"bearer_token"`
+
+	// Path to CA file
+	SSLCA string `toml:"ssl_ca"`
+	// Path to host cert file
+	SSLCert string `toml:"ssl_cert"`
+	// Path to cert key file
+	SSLKey string `toml:"ssl_key"`
+	// Use SSL but skip chain & host verification
+	InsecureSkipVerify bool
+
+	StringAsLabel bool `toml:"string_as_label"`
+
+	MaxRetries int               `toml:"max_retries"`
+	MinBackoff internal.Duration `toml:"min_backoff"`
+	MaxBackoff internal.Duration `toml:"max_backoff"`
+
+	client *http.Client
+}
+
+// recoverableError is an error after which the write may succeed when it is
+// retried.
+type recoverableError struct {
+	error
+}
+
+func (p *PrometheusRemoteWrite) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *PrometheusRemoteWrite) Description() string {
+	return "Send metrics to a Prometheus remote write endpoint"
+}
+
+func (p *PrometheusRemoteWrite) Connect() error {
+	tlsCfg, err := internal.GetTLSConfig(
+		p.SSLCert, p.SSLKey, p.SSLCA, p.InsecureSkipVerify)
+	if err != nil {
+		return err
+	}
+
+	p.client = &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsCfg,
+			Proxy:           http.ProxyFromEnvironment,
+		},
+		Timeout: p.Timeout.Duration,
+	}
+	return nil
+}
+
+func (p *PrometheusRemoteWrite) Close() error {
+	return nil
+}
+
+func (p *PrometheusRemoteWrite) Write(metrics []telegraf.Metric) error {
+	req := WriteRequest{Timeseries: p.convert(metrics)}
+	if len(req.Timeseries) == 0 {
+		return nil
+	}
+	body := snappy.Encode(nil, req.Marshal())
+
+	backoff := p.MinBackoff.Duration
+	for attempt := 0; ; attempt++ {
+		err := p.send(body)
+		if err == nil {
+			return nil
+		}
+
+		if _, ok := err.(recoverableError); !ok {
+			log.Printf("E! [outputs.prometheus_remote_write] dropping %d metrics: %s",
+				len(metrics), err)
+			return nil
+		}
+		if attempt >= p.MaxRetries {
+			return err
+		}
+
+		log.Printf("D! [outputs.prometheus_remote_write] retrying in %s: %s", backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > p.MaxBackoff.Duration {
+			backoff = p.MaxBackoff.Duration
+		}
+	}
+}
+
+func (p *PrometheusRemoteWrite) send(body []byte) error {
+	req, err := http.NewRequest("POST", p.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("X-Prometheus-Remote-Write-Version", remoteWriteVersion)
+	for k, v := range p.Headers {
+		if strings.ToLower(k) == "host" {
+			req.Host = v
+		} else {
+			req.Header.Set(k, v)
+		}
+	}
+
+	if p.Username != "" || p.Password != "" {
+		req.SetBasicAuth(p.Username, p.Password)
+	}
+
+	if p.BearerToken != "" {
+		token, err := ioutil.ReadFile(p.BearerToken)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return recoverableError{err}
+	}
+	defer resp.Body.Close()
+	msg, _ := ioutil.ReadAll(resp.Body)
+
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+
+	err = fmt.Errorf("received status code %d from %s: %s",
+		resp.StatusCode, p.URL, strings.TrimSpace(string(msg)))
+	if resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests {
+		return recoverableError{err}
+	}
+	return err
+}
+
+// convert converts metrics into time series, named the same way as by the
+// prometheus_client output.  Samples of the same series are grouped into one
+// time series.
+func (p *PrometheusRemoteWrite) convert(metrics []telegraf.Metric) []TimeSeries {
+	var series []TimeSeries
+	index := make(map[string]int)
+
+	add := func(labels []Label, value float64, timestamp int64) {
+		key := seriesKey(labels)
+		i, ok := index[key]
+		if !ok {
+			i = len(series)
+			index[key] = i
+			series = append(series, TimeSeries{Labels: labels})
+		}
+		series[i].Samples = append(series[i].Samples, Sample{Value: value, Timestamp: timestamp})
+	}
+
+	for _, m := range metrics {
+		timestamp := m.UnixNano() / int64(time.Millisecond)
+		labels := p.labels(m)
+
+		switch m.Type() {
+		case telegraf.Histogram, telegraf.Summary:
+			name := prometheus.Sanitize(m.Name())
+			boundLabel := "quantile"
+			bucketName := name
+			if m.Type() == telegraf.Histogram {
+				boundLabel = "le"
+				bucketName = name + "_bucket"
+			}
+
+			for fn, fv := range m.Fields() {
+				value, ok := prometheus.SampleValue(fv)
+				if !ok {
+					continue
+				}
+				switch fn {
+				case "sum", "count":
+					add(withName(labels, name+"_"+fn), value, timestamp)
+				default:
+					bound, err := strconv.ParseFloat(fn, 64)
+					if err != nil {
+						continue
+					}
+					l := append(withName(labels, bucketName), Label{
+						Name:  boundLabel,
+						Value: strconv.FormatFloat(bound, 'g', -1, 64),
+					})
+					sortLabels(l)
+					add(l, value, timestamp)
+				}
+			}
+		default:
+			for fn, fv := range m.Fields() {
+				value, ok := prometheus.SampleValue(fv)
+				if !ok {
+					continue
+				}
+				add(withName(labels, prometheus.MetricName(m.Name(), fn, m.Type())), value, timestamp)
+			}
+		}
+	}
+
+	for i := range series {
+		samples := series[i].Samples
+		sort.SliceStable(samples, func(a, b int) bool {
+			return samples[a].Timestamp < samples[b].Timestamp
+		})
+	}
+	return series
+}
+
+// labels returns the sanitized labels of a metric, without the metric name.
+func (p *PrometheusRemoteWrite) labels(m telegraf.Metric) map[string]string {
+	labels := make(map[string]string)
+	for k, v := range m.Tags() {
+		labels[prometheus.Sanitize(k)] = v
+	}
+
+	// Prometheus doesn't have a string value type, so convert string
+	// fields to labels if enabled.
+	if p.StringAsLabel {
+		for fn, fv := range m.Fields() {
+			if v, ok := fv.(string); ok {
+				labels[prometheus.Sanitize(fn)] = v
+			}
+		}
+	}
+	return labels
+}
+
+// withName returns the labels sorted by name, including the metric name.
+func withName(labels map[string]string, name string) []Label {
+	l := make([]Label, 0, len(labels)+1)
+	l = append(l, Label{Name: "__name__", Value: name})
+	for k, v := range labels {
+		if k == "__name__" || v == "" {
+			continue
+		}
+		l = append(l, Label{Name: k, Value: v})
+	}
+	sortLabels(l)
+	return l
+}
+
+func sortLabels(labels []Label) {
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+}
+
+func seriesKey(labels []Label) string {
+	var buf bytes.Buffer
+	for _, l := range labels {
+		buf.WriteString(l.Name)
+		buf.WriteByte(0)
+		buf.WriteString(l.Value)
+		buf.WriteByte(0)
+	}
+	return buf.String()
+}
+
+func init() {
+	outputs.Add("prometheus_remote_write", func() telegraf.Output {
+		return &PrometheusRemoteWrite{
+			Timeout:       internal.Duration{Duration: time.Second * 5},
+			StringAsLabel: true,
+			MaxRetries:    3,
+			MinBackoff:    internal.Duration{Duration: time.Millisecond * 100},
+			MaxBackoff:    internal.Duration{Duration: time.Second},
+		}
+	})
+}
@@ -0,0 +1,230 @@
+package prometheus_remote_write
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// decodeWriteRequest decodes the protobuf encoding of a WriteRequest.
+func decodeWriteRequest(t *testing.T, b []byte) WriteRequest {
+	var req WriteRequest
+	for _, raw := range decodeMessage(t, b)[1] {
+		var ts TimeSeries
+		fields := decodeMessage(t, raw.([]byte))
+		for _, raw := range fields[1] {
+			l := decodeMessage(t, raw.([]byte))
+			ts.Labels = append(ts.Labels, Label{
+				Name:  string(l[1][0].([]byte)),
+				Value: string(l[2][0].([]byte)),
+			})
+		}
+		for _, raw := range fields[2] {
+			s := decodeMessage(t, raw.([]byte))
+			ts.Samples = append(ts.Samples, Sample{
+				Value:     math.Float64frombits(s[1][0].(uint64)),
+				Timestamp: int64(s[2][0].(uint64)),
+			})
+		}
+		req.Timeseries = append(req.Timeseries, ts)
+	}
+	return req
+}
+
+// decodeMessage returns the values of each field of a message, as uint64 for
+// varint and fixed64 fields and as []byte for length delimited fields.
+func decodeMessage(t *testing.T, b []byte) map[int][]interface{} {
+	fields := make(map[int][]interface{})
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		require.True(t, n > 0)
+		b = b[n:]
+
+		field := int(key >> 3)
+		switch key & 7 {
+		case wireVarint:
+			v, n := binary.Uvarint(b)
+			require.True(t, n > 0)
+			b = b[n:]
+			fields[field] = append(fields[field], v)
+		case wireFixed64:
+			require.True(t, len(b) >= 8)
+			fields[field] = append(fields[field], binary.LittleEndian.Uint64(b))
+			b = b[8:]
+		case wireBytes:
+			l, n := binary.Uvarint(b)
+			require.True(t, n > 0 && len(b) >= n+int(l))
+			fields[field] = append(fields[field], b[n:n+int(l)])
+			b = b[n+int(l):]
+		default:
+			t.Fatalf("unexpected wire type %d", key&7)
+		}
+	}
+	return fields
+}
+
+func newMetric(
+	name string,
+	tags map[string]string,
+	fields map[string]interface{},
+	tp telegraf.ValueType,
+) telegraf.Metric {
+	m, err := metric.New(name, tags, fields, time.Unix(1500000000, 0), tp)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func labelMap(labels []Label) map[string]string {
+	m := make(map[string]string)
+	for _, l := range labels {
+		m[l.Name] = l.Value
+	}
+	return m
+}
+
+func TestConvert(t *testing.T) {
+	p := &PrometheusRemoteWrite{StringAsLabel: true}
+
+	series := p.convert([]telegraf.Metric{
+		newMetric("cpu", map[string]string{"host": "a"},
+			map[string]interface{}{"usage_idle": 90.5, "state": "ok"}, telegraf.Untyped),
+		newMetric("http_requests", map[string]string{"code": "200"},
+			map[string]interface{}{"counter": int64(10)}, telegraf.Counter),
+	})
+	require.Len(t, series, 2)
+
+	byName := make(map[string]TimeSeries)
+	for _, ts := range series {
+		byName[labelMap(ts.Labels)["__name__"]] = ts
+	}
+
+	cpu := byName["cpu_usage_idle"]
+	assert.Equal(t, []Label{
+		{Name: "__name__", Value: "cpu_usage_idle"},
+		{Name: "host", Value: "a"},
+		{Name: "state", Value: "ok"},
+	}, cpu.Labels)
+	assert.Equal(t, []Sample{{Value: 90.5, Timestamp: 1500000000000}}, cpu.Samples)
+
+	requests := byName["http_requests"]
+	assert.Equal(t, []Sample{{Value: 10, Timestamp: 1500000000000}}, requests.Samples)
+}
+
+func TestConvertHistogram(t *testing.T) {
+	p := &PrometheusRemoteWrite{}
+
+	series := p.convert([]telegraf.Metric{
+		newMetric("latency", map[string]string{},
+			map[string]interface{}{
+				"0.5":   float64(2),
+				"+Inf":  float64(4),
+				"sum":   float64(3.5),
+				"count": float64(4),
+			}, telegraf.Histogram),
+	})
+
+	got := make(map[string]float64)
+	for _, ts := range series {
+		labels := labelMap(ts.Labels)
+		got[labels["__name__"]+"{"+labels["le"]+"}"] = ts.Samples[0].Value
+	}
+	assert.Equal(t, map[string]float64{
+		"latency_bucket{0.5}":  2,
+		"latency_bucket{+Inf}": 4,
+		"latency_sum{}":        3.5,
+		"latency_count{}":      4,
+	}, got)
+}
+
+func TestWrite(t *testing.T) {
+	var req WriteRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		assert.Equal(t, remoteWriteVersion, r.Header.Get("X-Prometheus-Remote-Write-Version"))
+
+		compressed, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		body, err := snappy.Decode(nil, compressed)
+		require.NoError(t, err)
+		req = decodeWriteRequest(t, body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	p := &PrometheusRemoteWrite{URL: ts.URL}
+	require.NoError(t, p.Connect())
+
+	m1 := newMetric("cpu", map[string]string{"host": "a"},
+		map[string]interface{}{"value": 1.0}, telegraf.Gauge)
+	m2, err := metric.New("cpu", map[string]string{"host": "a"},
+		map[string]interface{}{"value": 2.0}, time.Unix(1500000010, 0), telegraf.Gauge)
+	require.NoError(t, err)
+	require.NoError(t, p.Write([]telegraf.Metric{m2, m1}))
+
+	require.Len(t, req.Timeseries, 1)
+	assert.Equal(t, []Label{
+		{Name: "__name__", Value: "cpu"},
+		{Name: "host", Value: "a"},
+	}, req.Timeseries[0].Labels)
+	assert.Equal(t, []Sample{
+		{Value: 1, Timestamp: 1500000000000},
+		{Value: 2, Timestamp: 1500000010000},
+	}, req.Timeseries[0].Samples)
+}
+
+func TestWriteRetries(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCode  int
+		requests    int
+		expectError bool
+	}{
+		{"server error is retried", http.StatusInternalServerError, 3, true},
+		{"too many requests is retried", http.StatusTooManyRequests, 3, true},
+		{"bad request is dropped", http.StatusBadRequest, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer ts.Close()
+
+			p := &PrometheusRemoteWrite{
+				URL:        ts.URL,
+				MaxRetries: 2,
+				MinBackoff: internal.Duration{Duration: time.Millisecond},
+				MaxBackoff: internal.Duration{Duration: time.Millisecond},
+			}
+			require.NoError(t, p.Connect())
+
+			err := p.Write([]telegraf.Metric{
+				newMetric("cpu", nil, map[string]interface{}{"value": 1.0}, telegraf.Untyped),
+			})
+			if tt.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.requests, requests)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package prometheus_remote_write
+
+import (
+	"math"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// The types below mirror the messages of the Prometheus remote write
+// protocol, defined in prometheus/prompb/remote.proto and types.proto:
+//
+//	message WriteRequest { repeated TimeSeries timeseries = 1; }
+//	message TimeSeries { repeated Label labels = 1; repeated Sample samples = 2; }
+//	message Label { string name = 1; string value = 2; }
+//	message Sample { double value = 1; int64 timestamp = 2; }
+
+// WriteRequest is the body of a remote write request.
+type WriteRequest struct {
+	Timeseries []TimeSeries
+}
+
+// TimeSeries is a series identified by its labels, which must be sorted by
+// name, along with its samples.
+type TimeSeries struct {
+	Labels  []Label
+	Samples []Sample
+}
+
+// Label is a label of a series, the metric name is the __name__ label.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is a value with its timestamp in milliseconds.
+type Sample struct {
+	Value     float64
+	Timestamp int64
+}
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+func encodeKey(buf *proto.Buffer, field, wireType int) {
+	buf.EncodeVarint(uint64(field<<3 | wireType))
+}
+
+// Marshal encodes the request in the protobuf wire format.
+func (r *WriteRequest) Marshal() []byte {
+	buf := proto.NewBuffer(nil)
+	for i := range r.Timeseries {
+		encodeKey(buf, 1, wireBytes)
+		buf.EncodeRawBytes(r.Timeseries[i].marshal())
+	}
+	return buf.Bytes()
+}
+
+func (ts *TimeSeries) marshal() []byte {
+	buf := proto.NewBuffer(nil)
+	for i := range ts.Labels {
+		encodeKey(buf, 1, wireBytes)
+		buf.EncodeRawBytes(ts.Labels[i].marshal())
+	}
+	for i := range ts.Samples {
+		encodeKey(buf, 2, wireBytes)
+		buf.EncodeRawBytes(ts.Samples[i].marshal())
+	}
+	return buf.Bytes()
+}
+
+func (l *Label) marshal() []byte {
+	buf := proto.NewBuffer(nil)
+	if l.Name != "" {
+		encodeKey(buf, 1, wireBytes)
+		buf.EncodeStringBytes(l.Name)
+	}
+	if l.Value != "" {
+		encodeKey(buf, 2, wireBytes)
+		buf.EncodeStringBytes(l.Value)
+	}
+	return buf.Bytes()
+}
+
+func (s *Sample) marshal() []byte {
+	buf := proto.NewBuffer(nil)
+	encodeKey(buf, 1, wireFixed64)
+	buf.EncodeFixed64(math.Float64bits(s.Value))
+	encodeKey(buf, 2, wireVarint)
+	buf.EncodeVarint(uint64(s.Timestamp))
+	return buf.Bytes()
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"net"
 	"strings"
+	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
@@ -12,13 +13,30 @@ import (
 	"github.com/influxdata/telegraf/plugins/serializers"
 )
 
+const (
+	// defaultPayloadSize is a reasonable default size for datagrams that could
+	// be travelling over the internet.
+	defaultPayloadSize = 512
+
+	defaultMinReconnectBackoff = time.Second
+	defaultMaxReconnectBackoff = time.Minute
+)
+
 type SocketWriter struct {
-	Address         string
-	KeepAlivePeriod *internal.Duration
+	Address             string
+	KeepAlivePeriod     *internal.Duration
+	PayloadSize         int               `toml:"payload_size"`
+	MinReconnectBackoff internal.Duration `toml:"min_reconnect_backoff"`
+	MaxReconnectBackoff internal.Duration `toml:"max_reconnect_backoff"`
 
 	serializers.Serializer
 
 	net.Conn
+
+	// reconnectBackoff is the delay before the next reconnect attempt after
+	// a failed one, and nextReconnect the earliest time of that attempt.
+	reconnectBackoff time.Duration
+	nextReconnect    time.Time
 }
 
 func (sw *SocketWriter) Description() string {
@@ -45,6 +63,17 @@ func (sw *SocketWriter) SampleConfig() string {
   ## Defaults to the OS configuration.
   # keep_alive_period = "5m"
 
+  ## Maximum size of a datagram in bytes.
+  ## Only applies to UDP and unixgram sockets.
+  ## Metrics are packed into datagrams up to this size, a metric that does not
+  ## fit on its own is sent in a datagram by itself.
+  # payload_size = 512
+
+  ## Delay before reconnecting after the connection failed, doubled after
+  ## each failed attempt up to max_reconnect_backoff.
+  # min_reconnect_backoff = "1s"
+  # max_reconnect_backoff = "1m"
+
   ## Data format to generate.
   ## Each data format has its own unique set of configuration options, read
   ## more about them here:
@@ -82,7 +111,7 @@ func (sw *SocketWriter) setKeepAlive(c net.Conn) error {
 	}
 	tcpc, ok := c.(*net.TCPConn)
 	if !ok {
-		return fmt.Errorf("cannot set keep alive on a %s socket", strings.SplitN(sw.Address, "://", 2)[0])
+		return fmt.Errorf("cannot set keep alive on a %s socket", sw.network())
 	}
 	if sw.KeepAlivePeriod.Duration == 0 {
 		return tcpc.SetKeepAlive(false)
@@ -99,31 +128,113 @@ func (sw *SocketWriter) setKeepAlive(c net.Conn) error {
 func (sw *SocketWriter) Write(metrics []telegraf.Metric) error {
 	if sw.Conn == nil {
 		// previous write failed with permanent error and socket was closed.
-		if err := sw.Connect(); err != nil {
+		if err := sw.reconnect(); err != nil {
 			return err
 		}
 	}
 
+	if isDatagram(sw.network()) {
+		return sw.writeDatagrams(metrics)
+	}
+
+	bs, err := serializers.SerializeBatch(sw.Serializer, metrics)
+	if err != nil {
+		return err
+	}
+	return sw.write(bs)
+}
+
+// writeDatagrams packs the serialized metrics into datagrams of at most
+// PayloadSize bytes.
+func (sw *SocketWriter) writeDatagrams(metrics []telegraf.Metric) error {
+	size := sw.PayloadSize
+	if size <= 0 {
+		size = defaultPayloadSize
+	}
+
+	buf := make([]byte, 0, size)
 	for _, m := range metrics {
 		bs, err := sw.Serialize(m)
 		if err != nil {
 			//TODO log & keep going with remaining metrics
 			return err
 		}
-		if _, err := sw.Conn.Write(bs); err != nil {
-			//TODO log & keep going with remaining strings
-			if err, ok := err.(net.Error); !ok || !err.Temporary() {
-				// permanent error. close the connection
-				sw.Close()
-				sw.Conn = nil
+		if len(buf) > 0 && len(buf)+len(bs) > size {
+			if err := sw.write(buf); err != nil {
+				return err
 			}
-			return err
+			buf = buf[:0]
+		}
+		buf = append(buf, bs...)
+	}
+
+	if len(buf) > 0 {
+		return sw.write(buf)
+	}
+	return nil
+}
+
+func (sw *SocketWriter) write(bs []byte) error {
+	if _, err := sw.Conn.Write(bs); err != nil {
+		//TODO log & keep going with remaining strings
+		if err, ok := err.(net.Error); !ok || !err.Temporary() {
+			// permanent error. close the connection
+			sw.Close()
+			sw.Conn = nil
+		}
+		return err
+	}
+	return nil
+}
+
+// reconnect connects to the destination unless a previous attempt failed
+// within the current backoff period.  The backoff is doubled on each failed
+// attempt and reset once connected.
+func (sw *SocketWriter) reconnect() error {
+	if wait := sw.nextReconnect.Sub(time.Now()); wait > 0 {
+		return fmt.Errorf("not reconnecting to %s for another %s", sw.Address, wait)
+	}
+
+	if err := sw.Connect(); err != nil {
+		min, max := sw.MinReconnectBackoff.Duration, sw.MaxReconnectBackoff.Duration
+		if min <= 0 {
+			min = defaultMinReconnectBackoff
+		}
+		if max < min {
+			max = min
 		}
+
+		switch {
+		case sw.reconnectBackoff < min:
+			sw.reconnectBackoff = min
+		case sw.reconnectBackoff*2 > max:
+			sw.reconnectBackoff = max
+		default:
+			sw.reconnectBackoff *= 2
+		}
+		sw.nextReconnect = time.Now().Add(sw.reconnectBackoff)
+		log.Printf("E! [outputs.socket_writer] unable to reconnect to %s, retrying in %s: %s",
+			sw.Address, sw.reconnectBackoff, err)
+		return err
 	}
 
+	sw.reconnectBackoff = 0
+	sw.nextReconnect = time.Time{}
 	return nil
 }
 
+func (sw *SocketWriter) network() string {
+	return strings.SplitN(sw.Address, "://", 2)[0]
+}
+
+func isDatagram(network string) bool {
+	switch network {
+	case "udp", "udp4", "udp6", "unixgram":
+		return true
+	}
+	return false
+}
+
 // Close closes the connection. Noop if already closed.
 func (sw *SocketWriter) Close() error {
 	if sw.Conn == nil {
@@ -137,7 +248,10 @@ func (sw *SocketWriter) Close() error {
 func newSocketWriter() *SocketWriter {
 	s, _ := serializers.NewInfluxSerializer()
 	return &SocketWriter{
-		Serializer: s,
+		PayloadSize:         defaultPayloadSize,
+		MinReconnectBackoff: internal.Duration{Duration: defaultMinReconnectBackoff},
+		MaxReconnectBackoff: internal.Duration{Duration: defaultMaxReconnectBackoff},
+		Serializer:          s,
 	}
 }
 
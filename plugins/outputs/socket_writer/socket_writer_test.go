@@ -7,8 +7,10 @@ import (
 	"os"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -187,3 +189,109 @@ func TestSocketWriter_Write_reconnect(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, string(mbsout), string(buf[:n]))
 }
+
+func TestSocketWriter_udp_payloadSize(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	sw := newSocketWriter()
+	sw.Address = "udp://" + listener.LocalAddr().String()
+
+	metrics := []telegraf.Metric{
+		testutil.TestMetric(1, "test"),
+		testutil.TestMetric(2, "test"),
+		testutil.TestMetric(3, "test"),
+	}
+	var mbsout []byte
+	for _, m := range metrics {
+		bs, _ := sw.Serialize(m)
+		mbsout = append(mbsout, bs...)
+	}
+	size := len(mbsout) / len(metrics)
+
+	err = sw.Connect()
+	require.NoError(t, err)
+	defer sw.Close()
+
+	// two metrics fit into the first datagram, the third goes to a second one
+	sw.PayloadSize = 2 * size
+	err = sw.Write(metrics)
+	require.NoError(t, err)
+
+	buf := make([]byte, 1024)
+	n, _, err := listener.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, string(mbsout[:2*size]), string(buf[:n]))
+	n, _, err = listener.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, string(mbsout[2*size:]), string(buf[:n]))
+
+	// a metric larger than the payload size is sent on its own
+	sw.PayloadSize = size / 2
+	err = sw.Write(metrics[:1])
+	require.NoError(t, err)
+
+	n, _, err = listener.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, string(mbsout[:size]), string(buf[:n]))
+}
+
+func TestSocketWriter_keepAlive(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	sw := newSocketWriter()
+	sw.Address = "tcp://" + listener.Addr().String()
+	sw.KeepAlivePeriod = &internal.Duration{Duration: time.Minute}
+
+	err = sw.Connect()
+	require.NoError(t, err)
+	defer sw.Close()
+
+	lconn, err := listener.Accept()
+	require.NoError(t, err)
+	defer lconn.Close()
+
+	testSocketWriter_stream(t, sw, lconn)
+}
+
+func TestSocketWriter_Write_reconnectBackoff(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	sw := newSocketWriter()
+	sw.Address = "tcp://" + addr
+	sw.MinReconnectBackoff.Duration = time.Second
+	sw.MaxReconnectBackoff.Duration = 3 * time.Second
+
+	metrics := []telegraf.Metric{testutil.TestMetric(1, "testerr")}
+
+	// the backoff doubles on each failed attempt, up to the maximum
+	for _, backoff := range []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second} {
+		err = sw.Write(metrics)
+		require.Error(t, err)
+		assert.Equal(t, backoff, sw.reconnectBackoff)
+		assert.True(t, sw.nextReconnect.After(time.Now()))
+
+		// no attempt is made within the backoff period
+		err = sw.Write(metrics)
+		require.Error(t, err)
+		assert.Equal(t, backoff, sw.reconnectBackoff)
+
+		sw.nextReconnect = time.Now()
+	}
+
+	listener, err = net.Listen("tcp", addr)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	err = sw.Write(metrics)
+	require.NoError(t, err)
+	defer sw.Close()
+	assert.Zero(t, sw.reconnectBackoff)
+	assert.True(t, sw.nextReconnect.IsZero())
+}
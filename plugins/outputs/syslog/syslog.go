@@ -0,0 +1,266 @@
+package syslog
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+const (
+	framingOctetCounting  = "octet-counting"
+	framingNonTransparent = "non-transparent"
+)
+
+var sampleConfig = `
+  ## URL to connect to
+  ## ex: address = "tcp://127.0.0.1:8094"
+  ## ex: address = "tcp4://127.0.0.1:8094"
+  ## ex: address = "tcp6://127.0.0.1:8094"
+  ## ex: address = "tcp6://[2001:db8::1]:8094"
+  ## ex: address = "udp://127.0.0.1:8094"
+  ## ex: address = "udp4://127.0.0.1:8094"
+  ## ex: address = "udp6://127.0.0.1:8094"
+  ## ex: address = "tls://127.0.0.1:6514"
+  address = "tcp://127.0.0.1:8094"
+
+  ## Optional SSL Config, used by the tls scheme
+  # ssl_ca = "/etc/telegraf/ca.pem"
+  # ssl_cert = "/etc/telegraf/cert.pem"
+  # ssl_key = "/etc/telegraf/key.pem"
+  ## Use SSL but skip chain & host verification
+  # insecure_skip_verify = false
+
+  ## Period between keep alive probes.
+  ## Only applies to TCP sockets.
+  ## 0 disables keep alive probes.
+  ## Defaults to the OS configuration.
+  # keep_alive_period = "5m"
+
+  ## The framing technique with which messages are transported over stream
+  ## sockets, either "octet-counting" (RFC5425) or "non-transparent" (RFC6587).
+  ## Messages sent over UDP are not framed.
+  # framing = "octet-counting"
+
+  ## The trailer used to end messages with non-transparent framing, either
+  ## "LF" or "NUL".
+  # trailer = "LF"
+
+  ## Structured data ids whose params are taken from the tags and fields
+  ## prefixed by the id followed by the separator, i.e. the "foo_bar" tag
+  ## becomes the "bar" param of the "foo" element.
+  # sdids = ["foo", "bar"]
+
+  ## Separator between the structured data id and the param name.
+  # sdparam_separator = "_"
+
+  ## Structured data id for all other tags and fields, they are dropped
+  ## if empty.
+  # default_sdid = "default"
+
+  ## Default severity, used unless the severity_code field is set.
+  ## 0 (emergency) to 7 (debug), defaults to 5 (notice).
+  # default_severity_code = 5
+
+  ## Default facility, used unless the facility_code field is set.
+  ## 0 (kernel) to 23 (local7), defaults to 1 (user-level).
+  # default_facility_code = 1
+
+  ## Default appname, used unless the appname tag is set.  Defaults to the
+  ## measurement name.
+  # default_appname = "Telegraf"
+`
+
+type Syslog struct {
+	Address         string
+	KeepAlivePeriod *internal.Duration
+
+	// Path to CA file
+	SSLCA string `toml:"ssl_ca"`
+	// Path to host cert file
+	SSLCert string `toml:"ssl_cert"`
+	// Path to cert key file
+	SSLKey string `toml:"ssl_key"`
+	// Use SSL but skip chain & host verification
+	InsecureSkipVerify bool
+
+	Framing             string
+	Trailer             string
+	Sdids               []string
+	Separator           string `toml:"sdparam_separator"`
+	DefaultSdid         string `toml:"default_sdid"`
+	DefaultSeverityCode int    `toml:"default_severity_code"`
+	DefaultFacilityCode int    `toml:"default_facility_code"`
+	DefaultAppname      string `toml:"default_appname"`
+
+	mapper *syslogMapper
+	net.Conn
+}
+
+func (s *Syslog) Description() string {
+	return "Configuration for Syslog server to send metrics to"
+}
+
+func (s *Syslog) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Syslog) Connect() error {
+	if s.DefaultSeverityCode < 0 || s.DefaultSeverityCode > 7 {
+		return fmt.Errorf("invalid default_severity_code %d", s.DefaultSeverityCode)
+	}
+	if s.DefaultFacilityCode < 0 || s.DefaultFacilityCode > 23 {
+		return fmt.Errorf("invalid default_facility_code %d", s.DefaultFacilityCode)
+	}
+	switch s.Framing {
+	case framingOctetCounting, framingNonTransparent:
+	default:
+		return fmt.Errorf("invalid framing %q", s.Framing)
+	}
+	switch strings.ToUpper(s.Trailer) {
+	case "LF", "NUL":
+	default:
+		return fmt.Errorf("invalid trailer %q", s.Trailer)
+	}
+
+	s.mapper = &syslogMapper{
+		DefaultSdid:     s.DefaultSdid,
+		Sdids:           s.Sdids,
+		Separator:       s.Separator,
+		DefaultSeverity: uint8(s.DefaultSeverityCode),
+		DefaultFacility: uint8(s.DefaultFacilityCode),
+		DefaultAppname:  s.DefaultAppname,
+	}
+
+	spl := strings.SplitN(s.Address, "://", 2)
+	if len(spl) != 2 {
+		return fmt.Errorf("invalid address: %s", s.Address)
+	}
+
+	var c net.Conn
+	var err error
+	switch spl[0] {
+	case "tls":
+		var tlsCfg *tls.Config
+		tlsCfg, err = internal.GetTLSConfig(
+			s.SSLCert, s.SSLKey, s.SSLCA, s.InsecureSkipVerify)
+		if err != nil {
+			return err
+		}
+		if tlsCfg == nil {
+			tlsCfg = &tls.Config{}
+		}
+		c, err = tls.Dial("tcp", spl[1], tlsCfg)
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6":
+		c, err = net.Dial(spl[0], spl[1])
+	default:
+		return fmt.Errorf("unsupported scheme %q in address: %s", spl[0], s.Address)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.setKeepAlive(c); err != nil {
+		log.Printf("W! [outputs.syslog] unable to configure keep alive (%s): %s", s.Address, err)
+	}
+
+	s.Conn = c
+	return nil
+}
+
+func (s *Syslog) setKeepAlive(c net.Conn) error {
+	if s.KeepAlivePeriod == nil {
+		return nil
+	}
+	tcpc, ok := c.(*net.TCPConn)
+	if !ok {
+		return fmt.Errorf("cannot set keep alive on a %s socket", strings.SplitN(s.Address, "://", 2)[0])
+	}
+	if s.KeepAlivePeriod.Duration == 0 {
+		return tcpc.SetKeepAlive(false)
+	}
+	if err := tcpc.SetKeepAlive(true); err != nil {
+		return err
+	}
+	return tcpc.SetKeepAlivePeriod(s.KeepAlivePeriod.Duration)
+}
+
+// Write sends each metric as a syslog message.  Metrics that cannot be
+// mapped to a message are logged and dropped.
+func (s *Syslog) Write(metrics []telegraf.Metric) error {
+	if s.Conn == nil {
+		// previous write failed with permanent error and socket was closed.
+		if err := s.Connect(); err != nil {
+			return err
+		}
+	}
+
+	_, datagram := s.Conn.(*net.UDPConn)
+
+	var buf bytes.Buffer
+	for _, metric := range metrics {
+		msg, err := s.mapper.mapMetric(metric)
+		if err != nil {
+			log.Printf("E! [outputs.syslog] dropping metric %s: %s", metric.Name(), err)
+			continue
+		}
+
+		buf.Reset()
+		msg.writeTo(&buf)
+		if _, err := s.Conn.Write(s.frame(buf.Bytes(), datagram)); err != nil {
+			if err, ok := err.(net.Error); !ok || !err.Temporary() {
+				// permanent error. close the connection
+				s.Close()
+			}
+			return fmt.Errorf("error writing to syslog server %s: %s", s.Address, err)
+		}
+	}
+	return nil
+}
+
+// frame returns the message framed for transport, datagrams carry a single
+// message and are not framed.
+func (s *Syslog) frame(msg []byte, datagram bool) []byte {
+	if datagram {
+		return msg
+	}
+	if s.Framing == framingNonTransparent {
+		if strings.ToUpper(s.Trailer) == "NUL" {
+			return append(msg, 0)
+		}
+		return append(msg, '\n')
+	}
+	return append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+}
+
+// Close closes the connection. Noop if already closed.
+func (s *Syslog) Close() error {
+	if s.Conn == nil {
+		return nil
+	}
+	err := s.Conn.Close()
+	s.Conn = nil
+	return err
+}
+
+func newSyslog() *Syslog {
+	return &Syslog{
+		Framing:             framingOctetCounting,
+		Trailer:             "LF",
+		Separator:           "_",
+		DefaultSdid:         "default",
+		DefaultSeverityCode: 5,
+		DefaultFacilityCode: 1,
+	}
+}
+
+func init() {
+	outputs.Add("syslog", func() telegraf.Output { return newSyslog() })
+}
@@ -0,0 +1,302 @@
+package syslog
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Maximum lengths of the header fields and structured data names as defined
+// by RFC5424.
+const (
+	maxHostnameLen = 255
+	maxAppNameLen  = 48
+	maxProcIDLen   = 128
+	maxMsgIDLen    = 32
+	maxSDNameLen   = 32
+
+	nilValue = "-"
+)
+
+// syslogMessage is a RFC5424 syslog message.
+type syslogMessage struct {
+	facility  uint8
+	severity  uint8
+	timestamp time.Time
+	hostname  string
+	appname   string
+	procID    string
+	msgID     string
+	sd        []sdElement
+	msg       string
+}
+
+// sdElement is a structured data element, its params are sorted by name.
+type sdElement struct {
+	id     string
+	params []sdParam
+}
+
+type sdParam struct {
+	name  string
+	value string
+}
+
+// syslogMapper maps metrics to syslog messages.
+type syslogMapper struct {
+	DefaultSdid     string
+	Sdids           []string
+	Separator       string
+	DefaultSeverity uint8
+	DefaultFacility uint8
+	DefaultAppname  string
+}
+
+// mapMetric maps a metric to a syslog message:
+//
+//   - the appname, hostname, procid and msgid header fields are taken from the
+//     tags of the same name, or the fields for procid and msgid.  The hostname
+//     falls back to the source and host tags and the appname to the default
+//     appname or else the measurement name.
+//   - the severity_code and facility_code fields set the priority.
+//   - the msg field is the free form message.
+//   - all other tags and fields are added as params to the structured data
+//     element whose id, followed by the separator, prefixes their name, or else
+//     to the default structured data element.
+func (sm *syslogMapper) mapMetric(metric telegraf.Metric) (*syslogMessage, error) {
+	tags := metric.Tags()
+	fields := metric.Fields()
+
+	msg := &syslogMessage{
+		facility:  sm.DefaultFacility,
+		severity:  sm.DefaultSeverity,
+		timestamp: metric.Time(),
+		appname:   sm.DefaultAppname,
+	}
+
+	if v, ok := fields["severity_code"]; ok {
+		code, err := priorityCode(v)
+		if err != nil || code > 7 {
+			return nil, fmt.Errorf("invalid severity_code %v", v)
+		}
+		msg.severity = code
+		delete(fields, "severity_code")
+	}
+	if v, ok := fields["facility_code"]; ok {
+		code, err := priorityCode(v)
+		if err != nil || code > 23 {
+			return nil, fmt.Errorf("invalid facility_code %v", v)
+		}
+		msg.facility = code
+		delete(fields, "facility_code")
+	}
+
+	if v, ok := fields["msg"]; ok {
+		msg.msg = formatValue(v)
+		delete(fields, "msg")
+	}
+
+	msg.procID = takeValue(tags, fields, "procid")
+	msg.msgID = takeValue(tags, fields, "msgid")
+
+	if v, ok := tags["appname"]; ok {
+		msg.appname = v
+		delete(tags, "appname")
+	}
+	if msg.appname == "" {
+		msg.appname = metric.Name()
+	}
+
+	for _, name := range []string{"hostname", "source", "host"} {
+		if v, ok := tags[name]; ok {
+			msg.hostname = v
+			delete(tags, name)
+			break
+		}
+	}
+
+	params := make(map[string][]sdParam)
+	for k, v := range tags {
+		sm.addParam(params, k, v)
+	}
+	for k, v := range fields {
+		sm.addParam(params, k, formatValue(v))
+	}
+
+	ids := make([]string, 0, len(sm.Sdids)+1)
+	ids = append(ids, sm.Sdids...)
+	ids = append(ids, sm.DefaultSdid)
+	for _, id := range ids {
+		p, ok := params[id]
+		if !ok {
+			continue
+		}
+		sort.Slice(p, func(i, j int) bool { return p[i].name < p[j].name })
+		msg.sd = append(msg.sd, sdElement{id: id, params: p})
+		delete(params, id)
+	}
+
+	return msg, nil
+}
+
+// takeValue removes and returns the value of the tag, or else of the field,
+// with the given name.
+func takeValue(tags map[string]string, fields map[string]interface{}, name string) string {
+	if v, ok := tags[name]; ok {
+		delete(tags, name)
+		return v
+	}
+	if v, ok := fields[name]; ok {
+		delete(fields, name)
+		return formatValue(v)
+	}
+	return ""
+}
+
+// addParam adds the tag or field to the structured data element it belongs
+// to, it is dropped if there is none.
+func (sm *syslogMapper) addParam(params map[string][]sdParam, key, value string) {
+	id := sm.DefaultSdid
+	for _, sdid := range sm.Sdids {
+		if strings.HasPrefix(key, sdid+sm.Separator) {
+			id = sdid
+			key = strings.TrimPrefix(key, sdid+sm.Separator)
+			break
+		}
+	}
+	if id == "" || key == "" {
+		return
+	}
+	params[id] = append(params[id], sdParam{name: sdName(key), value: value})
+}
+
+// writeTo writes the message in the RFC5424 format:
+//
+//	<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]
+func (m *syslogMessage) writeTo(buf *bytes.Buffer) {
+	buf.WriteByte('<')
+	buf.WriteString(strconv.Itoa(int(m.facility)*8 + int(m.severity)))
+	buf.WriteString(">1 ")
+	if m.timestamp.IsZero() {
+		buf.WriteString(nilValue)
+	} else {
+		buf.WriteString(m.timestamp.Format("2006-01-02T15:04:05.999999Z07:00"))
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(headerField(m.hostname, maxHostnameLen))
+	buf.WriteByte(' ')
+	buf.WriteString(headerField(m.appname, maxAppNameLen))
+	buf.WriteByte(' ')
+	buf.WriteString(headerField(m.procID, maxProcIDLen))
+	buf.WriteByte(' ')
+	buf.WriteString(headerField(m.msgID, maxMsgIDLen))
+	buf.WriteByte(' ')
+
+	if len(m.sd) == 0 {
+		buf.WriteString(nilValue)
+	}
+	for _, e := range m.sd {
+		buf.WriteByte('[')
+		buf.WriteString(sdName(e.id))
+		for _, p := range e.params {
+			buf.WriteByte(' ')
+			buf.WriteString(p.name)
+			buf.WriteString(`="`)
+			buf.WriteString(sdValueReplacer.Replace(p.value))
+			buf.WriteByte('"')
+		}
+		buf.WriteByte(']')
+	}
+
+	if m.msg != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(m.msg)
+	}
+}
+
+var sdValueReplacer = strings.NewReplacer(`"`, `\"`, `\`, `\\`, `]`, `\]`)
+
+// headerField returns the value restricted to printable US-ASCII characters
+// and the maximum length, or the nil value if it is empty.
+func headerField(value string, maxLen int) string {
+	value = printUSASCII(value, nil)
+	if value == "" {
+		return nilValue
+	}
+	if len(value) > maxLen {
+		value = value[:maxLen]
+	}
+	return value
+}
+
+// sdName returns a valid structured data id or param name, which may not
+// contain '=', ' ', ']' or '"'.
+func sdName(name string) string {
+	name = printUSASCII(name, func(c byte) bool {
+		return c == '=' || c == ']' || c == '"'
+	})
+	if len(name) > maxSDNameLen {
+		name = name[:maxSDNameLen]
+	}
+	return name
+}
+
+// printUSASCII replaces characters that are not printable US-ASCII, as well
+// as those matching invalid, by an underscore.
+func printUSASCII(s string, invalid func(c byte) bool) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c < 33 || c > 126 || (invalid != nil && invalid(c)) {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+func priorityCode(v interface{}) (uint8, error) {
+	var code int64
+	switch v := v.(type) {
+	case int64:
+		code = v
+	case uint64:
+		if v > 255 {
+			return 0, fmt.Errorf("out of range")
+		}
+		code = int64(v)
+	case float64:
+		code = int64(v)
+	case string:
+		var err error
+		code, err = strconv.ParseInt(v, 10, 8)
+		if err != nil {
+			return 0, err
+		}
+	default:
+		return 0, fmt.Errorf("unsupported type %T", v)
+	}
+	if code < 0 || code > 255 {
+		return 0, fmt.Errorf("out of range")
+	}
+	return uint8(code), nil
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,123 @@
+package syslog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapMetric(t *testing.T) {
+	mapper := &syslogMapper{
+		DefaultSdid:     "default@32473",
+		Sdids:           []string{"foo@123", "bar"},
+		Separator:       "_",
+		DefaultSeverity: 5,
+		DefaultFacility: 1,
+	}
+
+	tests := []struct {
+		name     string
+		tags     map[string]string
+		fields   map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "defaults",
+			tags:     map[string]string{},
+			fields:   map[string]interface{}{"value": int64(1)},
+			expected: `<13>1 2010-11-10T23:30:00.000123Z - testmetric - - [default@32473 value="1"]`,
+		},
+		{
+			name: "header fields",
+			tags: map[string]string{
+				"host":     "tars",
+				"hostname": "testhost",
+				"appname":  "testapp",
+				"msgid":    "ID47",
+			},
+			fields: map[string]interface{}{
+				"severity_code": int64(2),
+				"facility_code": int64(3),
+				"procid":        int64(25),
+				"msg":           "Test message",
+			},
+			expected: `<26>1 2010-11-10T23:30:00.000123Z testhost testapp 25 ID47 [default@32473 host="tars"] Test message`,
+		},
+		{
+			name: "structured data",
+			tags: map[string]string{
+				"foo@123_tag1": "value1",
+				"bar_tag2":     `a "quoted" value]`,
+				"tag3":         "value3",
+			},
+			fields: map[string]interface{}{
+				"foo@123_value": float64(1.5),
+				"bar_ok":        true,
+				"bar_":          "dropped",
+			},
+			expected: `<13>1 2010-11-10T23:30:00.000123Z - testmetric - - ` +
+				`[foo@123 tag1="value1" value="1.5"]` +
+				`[bar ok="true" tag2="a \"quoted\" value\]"]` +
+				`[default@32473 tag3="value3"]`,
+		},
+		{
+			name:     "sanitized names",
+			tags:     map[string]string{"app name": "my app", "appname": "my app"},
+			fields:   map[string]interface{}{"a=b": int64(1)},
+			expected: `<13>1 2010-11-10T23:30:00.000123Z - my_app - - [default@32473 a_b="1" app_name="my app"]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := metric.New("testmetric", tt.tags, tt.fields,
+				time.Date(2010, time.November, 10, 23, 30, 0, 123456, time.UTC))
+			require.NoError(t, err)
+
+			msg, err := mapper.mapMetric(m)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			msg.writeTo(&buf)
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestMapMetricNoDefaultSdid(t *testing.T) {
+	mapper := &syslogMapper{Sdids: []string{"foo"}, Separator: "_", DefaultAppname: "Telegraf"}
+
+	m, err := metric.New("testmetric",
+		map[string]string{"foo_tag1": "value1", "tag2": "value2"},
+		map[string]interface{}{"value": int64(1)},
+		time.Date(2010, time.November, 10, 23, 30, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	msg, err := mapper.mapMetric(m)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	msg.writeTo(&buf)
+	assert.Equal(t, `<0>1 2010-11-10T23:30:00Z - Telegraf - - [foo tag1="value1"]`, buf.String())
+}
+
+func TestMapMetricInvalidPriority(t *testing.T) {
+	mapper := &syslogMapper{DefaultSdid: "default"}
+
+	for _, fields := range []map[string]interface{}{
+		{"severity_code": int64(8)},
+		{"severity_code": "warning"},
+		{"facility_code": int64(24)},
+		{"facility_code": int64(-1)},
+	} {
+		m, err := metric.New("testmetric", nil, fields, time.Now())
+		require.NoError(t, err)
+
+		_, err = mapper.mapMetric(m)
+		assert.Error(t, err, "%v", fields)
+	}
+}
@@ -0,0 +1,128 @@
+package syslog
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMetrics(t *testing.T) []telegraf.Metric {
+	m1, err := metric.New("testmetric",
+		map[string]string{"hostname": "testhost"},
+		map[string]interface{}{"msg": "first"},
+		time.Date(2010, time.November, 10, 23, 30, 0, 0, time.UTC))
+	require.NoError(t, err)
+	m2, err := metric.New("testmetric",
+		map[string]string{"hostname": "testhost"},
+		map[string]interface{}{"msg": "second", "severity_code": int64(3)},
+		time.Date(2010, time.November, 10, 23, 30, 1, 0, time.UTC))
+	require.NoError(t, err)
+	return []telegraf.Metric{m1, m2}
+}
+
+var expectedMessages = []string{
+	"<13>1 2010-11-10T23:30:00Z testhost testmetric - - - first",
+	"<11>1 2010-11-10T23:30:01Z testhost testmetric - - - second",
+}
+
+func TestWriteTCPOctetCounting(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	s := newSyslog()
+	s.Address = "tcp://" + listener.Addr().String()
+	require.NoError(t, s.Connect())
+	defer s.Close()
+
+	lconn, err := listener.Accept()
+	require.NoError(t, err)
+	defer lconn.Close()
+
+	require.NoError(t, s.Write(testMetrics(t)))
+
+	r := bufio.NewReader(lconn)
+	for _, expected := range expectedMessages {
+		length, err := r.ReadString(' ')
+		require.NoError(t, err)
+		n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+		require.NoError(t, err)
+		require.Equal(t, len(expected), n)
+
+		buf := make([]byte, n)
+		_, err = io.ReadFull(r, buf)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(buf))
+	}
+}
+
+func TestWriteTCPNonTransparent(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	s := newSyslog()
+	s.Address = "tcp://" + listener.Addr().String()
+	s.Framing = framingNonTransparent
+	s.Trailer = "NUL"
+	require.NoError(t, s.Connect())
+	defer s.Close()
+
+	lconn, err := listener.Accept()
+	require.NoError(t, err)
+	defer lconn.Close()
+
+	require.NoError(t, s.Write(testMetrics(t)))
+
+	r := bufio.NewReader(lconn)
+	for _, expected := range expectedMessages {
+		msg, err := r.ReadString(0)
+		require.NoError(t, err)
+		assert.Equal(t, expected+"\x00", msg)
+	}
+}
+
+func TestWriteUDP(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	s := newSyslog()
+	s.Address = "udp://" + listener.LocalAddr().String()
+	require.NoError(t, s.Connect())
+	defer s.Close()
+
+	require.NoError(t, s.Write(testMetrics(t)))
+
+	buf := make([]byte, 1024)
+	for _, expected := range expectedMessages {
+		n, _, err := listener.ReadFrom(buf)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(buf[:n]))
+	}
+}
+
+func TestConnectInvalidConfig(t *testing.T) {
+	s := newSyslog()
+	s.Address = "tcp://127.0.0.1:6514"
+	s.Framing = "invalid"
+	require.Error(t, s.Connect())
+
+	s = newSyslog()
+	s.Address = "tcp://127.0.0.1:6514"
+	s.DefaultSeverityCode = 8
+	require.Error(t, s.Connect())
+
+	s = newSyslog()
+	s.Address = "unix:///tmp/syslog.sock"
+	require.Error(t, s.Connect())
+}
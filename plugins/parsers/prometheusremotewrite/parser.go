@@ -0,0 +1,78 @@
+package prometheusremotewrite
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+const (
+	measurementName = "prometheus_remote_write"
+	nameLabel       = "__name__"
+)
+
+// Parser parses the uncompressed body of Prometheus remote write requests.
+// Each sample is converted to a metric named "prometheus_remote_write", with
+// the value in a field named after the metric and the other labels as tags.
+type Parser struct {
+	DefaultTags map[string]string
+}
+
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	var req WriteRequest
+	if err := proto.Unmarshal(buf, &req); err != nil {
+		return nil, fmt.Errorf("unable to decode write request: %s", err)
+	}
+
+	var metrics []telegraf.Metric
+	now := time.Now()
+	for _, ts := range req.Timeseries {
+		tags := make(map[string]string, len(p.DefaultTags)+len(ts.Labels))
+		for k, v := range p.DefaultTags {
+			tags[k] = v
+		}
+
+		var name string
+		for _, l := range ts.Labels {
+			if l.Name == nameLabel {
+				name = l.Value
+			} else {
+				tags[l.Name] = l.Value
+			}
+		}
+		if name == "" {
+			return nil, fmt.Errorf("series without %s label", nameLabel)
+		}
+
+		for _, s := range ts.Samples {
+			// NaN values, including the stale markers, cannot be stored
+			if math.IsNaN(s.Value) {
+				continue
+			}
+			t := now
+			if s.Timestamp > 0 {
+				t = time.Unix(0, s.Timestamp*int64(time.Millisecond))
+			}
+			fields := map[string]interface{}{name: s.Value}
+			m, err := metric.New(measurementName, tags, fields, t)
+			if err != nil {
+				return nil, err
+			}
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics, nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	return nil, fmt.Errorf("parsing a line is not supported by the prometheusremotewrite format")
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
@@ -0,0 +1,60 @@
+package prometheusremotewrite
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	req := &WriteRequest{
+		Timeseries: []*TimeSeries{
+			{
+				Labels: []*Label{
+					{Name: "__name__", Value: "go_goroutines"},
+					{Name: "instance", Value: "localhost:9090"},
+				},
+				Samples: []*Sample{
+					{Value: 15, Timestamp: 1500000000000},
+					{Value: math.NaN(), Timestamp: 1500000001000},
+					{Value: 16, Timestamp: 1500000002000},
+				},
+			},
+		},
+	}
+	buf, err := proto.Marshal(req)
+	require.NoError(t, err)
+
+	p := &Parser{}
+	p.SetDefaultTags(map[string]string{"source": "prometheus"})
+	metrics, err := p.Parse(buf)
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+
+	assert.Equal(t, "prometheus_remote_write", metrics[0].Name())
+	assert.Equal(t, map[string]string{"instance": "localhost:9090", "source": "prometheus"}, metrics[0].Tags())
+	assert.Equal(t, map[string]interface{}{"go_goroutines": float64(15)}, metrics[0].Fields())
+	assert.Equal(t, time.Unix(1500000000, 0), metrics[0].Time())
+	assert.Equal(t, map[string]interface{}{"go_goroutines": float64(16)}, metrics[1].Fields())
+}
+
+func TestParseInvalid(t *testing.T) {
+	p := &Parser{}
+
+	_, err := p.Parse([]byte("not protobuf"))
+	assert.Error(t, err)
+
+	buf, err := proto.Marshal(&WriteRequest{
+		Timeseries: []*TimeSeries{{Samples: []*Sample{{Value: 1}}}},
+	})
+	require.NoError(t, err)
+	_, err = p.Parse(buf)
+	assert.Error(t, err)
+
+	_, err = p.ParseLine("go_goroutines 15")
+	assert.Error(t, err)
+}
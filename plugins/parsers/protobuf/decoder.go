@@ -0,0 +1,263 @@
+package protobuf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+// Protocol buffer wire types.
+const (
+	wireVarint     = 0
+	wireFixed64    = 1
+	wireBytes      = 2
+	wireStartGroup = 3
+	wireEndGroup   = 4
+	wireFixed32    = 5
+)
+
+// decoder decodes protobuf encoded messages using the type information of a
+// descriptor set, without requiring generated code.
+//
+// Decoded values are flattened into a map keyed by their field path: nested
+// message fields are joined with a dot (`meta.host`) and elements of repeated
+// fields are suffixed with their index (`values.0`).  Fields of type
+// google.protobuf.Timestamp are decoded into a time.Time.
+type decoder struct {
+	registry *registry
+	fields   map[*descriptor.DescriptorProto]map[int32]*descriptor.FieldDescriptorProto
+}
+
+func newDecoder(r *registry) *decoder {
+	return &decoder{
+		registry: r,
+		fields:   make(map[*descriptor.DescriptorProto]map[int32]*descriptor.FieldDescriptorProto),
+	}
+}
+
+func (d *decoder) decode(msg *descriptor.DescriptorProto, buf []byte) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	if err := d.decodeMessage("", msg, buf, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func (d *decoder) fieldsOf(msg *descriptor.DescriptorProto) map[int32]*descriptor.FieldDescriptorProto {
+	fields, ok := d.fields[msg]
+	if !ok {
+		fields = make(map[int32]*descriptor.FieldDescriptorProto, len(msg.GetField()))
+		for _, f := range msg.GetField() {
+			fields[f.GetNumber()] = f
+		}
+		d.fields[msg] = fields
+	}
+	return fields
+}
+
+func (d *decoder) decodeMessage(
+	prefix string,
+	msg *descriptor.DescriptorProto,
+	buf []byte,
+	values map[string]interface{},
+) error {
+	fields := d.fieldsOf(msg)
+	counts := make(map[int32]int)
+
+	for len(buf) > 0 {
+		key, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return fmt.Errorf("invalid field key in message %s", msg.GetName())
+		}
+		buf = buf[n:]
+
+		number := int32(key >> 3)
+		wireType := int(key & 0x7)
+
+		raw, data, rest, err := readValue(wireType, buf)
+		if err != nil {
+			return fmt.Errorf("field %d of message %s: %s", number, msg.GetName(), err)
+		}
+		buf = rest
+
+		field, ok := fields[number]
+		if !ok {
+			// Unknown fields are skipped, as they would be by generated code.
+			continue
+		}
+
+		name := prefix + field.GetName()
+		repeated := field.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED
+		path := func() string {
+			if !repeated {
+				return name
+			}
+			p := name + "." + strconv.Itoa(counts[number])
+			counts[number]++
+			return p
+		}
+
+		switch field.GetType() {
+		case descriptor.FieldDescriptorProto_TYPE_MESSAGE:
+			if wireType != wireBytes {
+				return fmt.Errorf("field %s: unexpected wire type %d", name, wireType)
+			}
+			if field.GetTypeName() == timestampTypeName {
+				ts, err := decodeTimestamp(data)
+				if err != nil {
+					return fmt.Errorf("field %s: %s", name, err)
+				}
+				values[path()] = ts
+				continue
+			}
+			nested, ok := d.registry.messages[field.GetTypeName()]
+			if !ok {
+				return fmt.Errorf("field %s: unknown message type %s", name, field.GetTypeName())
+			}
+			if err := d.decodeMessage(path()+".", nested, data, values); err != nil {
+				return err
+			}
+		case descriptor.FieldDescriptorProto_TYPE_STRING:
+			if wireType != wireBytes {
+				return fmt.Errorf("field %s: unexpected wire type %d", name, wireType)
+			}
+			values[path()] = string(data)
+		case descriptor.FieldDescriptorProto_TYPE_BYTES:
+			// Opaque bytes have no sensible representation as a field value.
+			continue
+		case descriptor.FieldDescriptorProto_TYPE_GROUP:
+			return fmt.Errorf("field %s: groups are not supported", name)
+		default:
+			if wireType == wireBytes {
+				// Packed repeated scalars.
+				elemWireType := scalarWireType(field.GetType())
+				for len(data) > 0 {
+					var elem uint64
+					elem, _, data, err = readValue(elemWireType, data)
+					if err != nil {
+						return fmt.Errorf("field %s: %s", name, err)
+					}
+					values[path()] = d.scalar(field, elem)
+				}
+				continue
+			}
+			if wireType != scalarWireType(field.GetType()) {
+				return fmt.Errorf("field %s: unexpected wire type %d", name, wireType)
+			}
+			values[path()] = d.scalar(field, raw)
+		}
+	}
+	return nil
+}
+
+// scalar converts the raw wire value of a numeric, boolean or enum field into
+// a value suitable for a telegraf field.
+func (d *decoder) scalar(field *descriptor.FieldDescriptorProto, raw uint64) interface{} {
+	switch field.GetType() {
+	case descriptor.FieldDescriptorProto_TYPE_DOUBLE:
+		return math.Float64frombits(raw)
+	case descriptor.FieldDescriptorProto_TYPE_FLOAT:
+		return float64(math.Float32frombits(uint32(raw)))
+	case descriptor.FieldDescriptorProto_TYPE_INT64,
+		descriptor.FieldDescriptorProto_TYPE_SFIXED64:
+		return int64(raw)
+	case descriptor.FieldDescriptorProto_TYPE_INT32:
+		return int64(int32(raw))
+	case descriptor.FieldDescriptorProto_TYPE_SFIXED32:
+		return int64(int32(uint32(raw)))
+	case descriptor.FieldDescriptorProto_TYPE_UINT32,
+		descriptor.FieldDescriptorProto_TYPE_FIXED32:
+		return int64(uint32(raw))
+	case descriptor.FieldDescriptorProto_TYPE_UINT64,
+		descriptor.FieldDescriptorProto_TYPE_FIXED64:
+		return raw
+	case descriptor.FieldDescriptorProto_TYPE_SINT32:
+		return int64(int32(uint32(raw)>>1) ^ -int32(raw&1))
+	case descriptor.FieldDescriptorProto_TYPE_SINT64:
+		return int64(raw>>1) ^ -int64(raw&1)
+	case descriptor.FieldDescriptorProto_TYPE_BOOL:
+		return raw != 0
+	case descriptor.FieldDescriptorProto_TYPE_ENUM:
+		if name, ok := d.registry.enumName(field.GetTypeName(), int32(raw)); ok {
+			return name
+		}
+		return int64(int32(raw))
+	}
+	return nil
+}
+
+func scalarWireType(t descriptor.FieldDescriptorProto_Type) int {
+	switch t {
+	case descriptor.FieldDescriptorProto_TYPE_DOUBLE,
+		descriptor.FieldDescriptorProto_TYPE_FIXED64,
+		descriptor.FieldDescriptorProto_TYPE_SFIXED64:
+		return wireFixed64
+	case descriptor.FieldDescriptorProto_TYPE_FLOAT,
+		descriptor.FieldDescriptorProto_TYPE_FIXED32,
+		descriptor.FieldDescriptorProto_TYPE_SFIXED32:
+		return wireFixed32
+	}
+	return wireVarint
+}
+
+// readValue reads a single value of the given wire type from the start of buf.
+// Numeric values are returned in raw, length delimited values in data; the
+// remainder of the buffer is returned in rest.
+func readValue(wireType int, buf []byte) (raw uint64, data []byte, rest []byte, err error) {
+	switch wireType {
+	case wireVarint:
+		v, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return 0, nil, nil, fmt.Errorf("invalid varint")
+		}
+		return v, nil, buf[n:], nil
+	case wireFixed64:
+		if len(buf) < 8 {
+			return 0, nil, nil, fmt.Errorf("unexpected end of buffer")
+		}
+		return binary.LittleEndian.Uint64(buf), nil, buf[8:], nil
+	case wireFixed32:
+		if len(buf) < 4 {
+			return 0, nil, nil, fmt.Errorf("unexpected end of buffer")
+		}
+		return uint64(binary.LittleEndian.Uint32(buf)), nil, buf[4:], nil
+	case wireBytes:
+		l, n := binary.Uvarint(buf)
+		if n <= 0 || uint64(len(buf)-n) < l {
+			return 0, nil, nil, fmt.Errorf("invalid length delimited value")
+		}
+		end := n + int(l)
+		return 0, buf[n:end], buf[end:], nil
+	case wireStartGroup, wireEndGroup:
+		return 0, nil, nil, fmt.Errorf("groups are not supported")
+	}
+	return 0, nil, nil, fmt.Errorf("unknown wire type %d", wireType)
+}
+
+// decodeTimestamp decodes a google.protobuf.Timestamp message.
+func decodeTimestamp(buf []byte) (time.Time, error) {
+	var seconds, nanos int64
+	for len(buf) > 0 {
+		key, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return time.Time{}, fmt.Errorf("invalid timestamp")
+		}
+		raw, _, rest, err := readValue(int(key&0x7), buf[n:])
+		if err != nil {
+			return time.Time{}, err
+		}
+		buf = rest
+
+		switch key >> 3 {
+		case 1:
+			seconds = int64(raw)
+		case 2:
+			nanos = int64(int32(raw))
+		}
+	}
+	return time.Unix(seconds, nanos).UTC(), nil
+}
@@ -0,0 +1,94 @@
+package protobuf
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+const timestampTypeName = ".google.protobuf.Timestamp"
+
+// registry holds every message and enum type found in a descriptor set,
+// keyed by their fully qualified name (with a leading dot, as used by
+// type_name references inside descriptors).
+type registry struct {
+	messages map[string]*descriptor.DescriptorProto
+	enums    map[string]*descriptor.EnumDescriptorProto
+}
+
+// loadDescriptorSet reads a serialized FileDescriptorSet, as produced by
+// `protoc --include_imports --descriptor_set_out=<file>`.
+func loadDescriptorSet(path string) (*registry, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read descriptor file %s: %s", path, err)
+	}
+
+	set := &descriptor.FileDescriptorSet{}
+	if err := proto.Unmarshal(buf, set); err != nil {
+		return nil, fmt.Errorf("unable to parse descriptor file %s: %s", path, err)
+	}
+
+	return newRegistry(set), nil
+}
+
+func newRegistry(set *descriptor.FileDescriptorSet) *registry {
+	r := &registry{
+		messages: make(map[string]*descriptor.DescriptorProto),
+		enums:    make(map[string]*descriptor.EnumDescriptorProto),
+	}
+	for _, file := range set.GetFile() {
+		prefix := ""
+		if file.GetPackage() != "" {
+			prefix = "." + file.GetPackage()
+		}
+		for _, msg := range file.GetMessageType() {
+			r.addMessage(prefix, msg)
+		}
+		for _, enum := range file.GetEnumType() {
+			r.enums[prefix+"."+enum.GetName()] = enum
+		}
+	}
+	return r
+}
+
+func (r *registry) addMessage(prefix string, msg *descriptor.DescriptorProto) {
+	name := prefix + "." + msg.GetName()
+	r.messages[name] = msg
+	for _, nested := range msg.GetNestedType() {
+		r.addMessage(name, nested)
+	}
+	for _, enum := range msg.GetEnumType() {
+		r.enums[name+"."+enum.GetName()] = enum
+	}
+}
+
+// message looks up a message type by name. The leading dot is optional.
+func (r *registry) message(name string) (*descriptor.DescriptorProto, error) {
+	if !strings.HasPrefix(name, ".") {
+		name = "." + name
+	}
+	msg, ok := r.messages[name]
+	if !ok {
+		return nil, fmt.Errorf("message type %s not found in descriptor set", name[1:])
+	}
+	return msg, nil
+}
+
+// enumName returns the symbolic name of an enum value, or false if the value
+// is not declared by the enum type.
+func (r *registry) enumName(typeName string, value int32) (string, bool) {
+	enum, ok := r.enums[typeName]
+	if !ok {
+		return "", false
+	}
+	for _, v := range enum.GetValue() {
+		if v.GetNumber() == value {
+			return v.GetName(), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,236 @@
+package protobuf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// Parser parses protocol buffer encoded messages into metrics.  The message
+// layout is taken from a compiled descriptor set, so no generated code is
+// required for the message types being consumed.
+type Parser struct {
+	// MetricName is the measurement name used when MeasurementField is unset
+	// or not present in a message.
+	MetricName string
+
+	// DescriptorFile is the path to a FileDescriptorSet containing
+	// MessageType and all of its dependencies.
+	DescriptorFile string
+	// MessageType is the fully qualified name of the message to decode,
+	// eg. "mycompany.metrics.Sample".
+	MessageType string
+
+	// MeasurementField is an optional field path whose value is used as the
+	// measurement name.
+	MeasurementField string
+	// TagFields are the field paths converted to tags.
+	TagFields []string
+	// Fields are the field paths converted to fields.  If empty, every
+	// remaining decoded value becomes a field.
+	Fields []string
+	// TimestampField is an optional field path holding the metric time,
+	// either as a google.protobuf.Timestamp or a number of TimestampUnits
+	// since the epoch.
+	TimestampField string
+	// TimestampUnits is the precision of numeric timestamps, defaults to
+	// seconds.
+	TimestampUnits time.Duration
+
+	// LengthDelimited indicates that each message is prefixed by its size
+	// as a varint, allowing multiple messages per buffer.
+	LengthDelimited bool
+
+	DefaultTags map[string]string
+
+	message *descriptor.DescriptorProto
+	decoder *decoder
+}
+
+// InitDescriptors loads the message type from the descriptor file, it must be
+// called before parsing.
+func (p *Parser) InitDescriptors() error {
+	r, err := loadDescriptorSet(p.DescriptorFile)
+	if err != nil {
+		return err
+	}
+	return p.setRegistry(r)
+}
+
+func (p *Parser) setRegistry(r *registry) error {
+	if p.MessageType == "" {
+		return fmt.Errorf("protobuf message type is required")
+	}
+	msg, err := r.message(p.MessageType)
+	if err != nil {
+		return err
+	}
+	p.message = msg
+	p.decoder = newDecoder(r)
+	return nil
+}
+
+// Parse decodes one message, or a stream of messages when LengthDelimited is
+// set, into metrics.
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	metrics := make([]telegraf.Metric, 0)
+	if len(buf) == 0 {
+		return metrics, nil
+	}
+
+	if !p.LengthDelimited {
+		m, err := p.parseMessage(buf)
+		if err != nil {
+			return nil, err
+		}
+		return append(metrics, m), nil
+	}
+
+	for len(buf) > 0 {
+		size, n := binary.Uvarint(buf)
+		if n <= 0 || uint64(len(buf)-n) < size {
+			return nil, fmt.Errorf("invalid message length prefix")
+		}
+		end := n + int(size)
+		m, err := p.parseMessage(buf[n:end])
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, m)
+		buf = buf[end:]
+	}
+	return metrics, nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) < 1 {
+		return nil, fmt.Errorf("Can not parse the line: %s, for data format: protobuf", line)
+	}
+
+	return metrics[0], nil
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
+func (p *Parser) parseMessage(buf []byte) (telegraf.Metric, error) {
+	if p.decoder == nil {
+		return nil, fmt.Errorf("protobuf parser has not been initialized")
+	}
+
+	values, err := p.decoder.decode(p.message, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	name := p.MetricName
+	if p.MeasurementField != "" {
+		if v, ok := values[p.MeasurementField]; ok {
+			if s := toString(v); s != "" {
+				name = s
+			}
+			delete(values, p.MeasurementField)
+		}
+	}
+
+	tags := make(map[string]string)
+	for k, v := range p.DefaultTags {
+		tags[k] = v
+	}
+	for _, path := range p.TagFields {
+		if v, ok := values[path]; ok {
+			tags[fieldName(path)] = toString(v)
+			delete(values, path)
+		}
+	}
+
+	tm := time.Now().UTC()
+	if p.TimestampField != "" {
+		if v, ok := values[p.TimestampField]; ok {
+			tm, err = p.toTime(v)
+			if err != nil {
+				return nil, err
+			}
+			delete(values, p.TimestampField)
+		}
+	}
+
+	fields := make(map[string]interface{})
+	if len(p.Fields) == 0 {
+		for k, v := range values {
+			fields[fieldName(k)] = toFieldValue(v)
+		}
+	} else {
+		for _, path := range p.Fields {
+			for k, v := range values {
+				if k == path || strings.HasPrefix(k, path+".") {
+					fields[fieldName(k)] = toFieldValue(v)
+				}
+			}
+		}
+	}
+
+	return metric.New(name, tags, fields, tm)
+}
+
+func (p *Parser) toTime(v interface{}) (time.Time, error) {
+	units := p.TimestampUnits
+	if units <= 0 {
+		units = time.Second
+	}
+
+	switch t := v.(type) {
+	case time.Time:
+		return t, nil
+	case int64:
+		return time.Unix(0, t*int64(units)).UTC(), nil
+	case uint64:
+		return time.Unix(0, int64(t)*int64(units)).UTC(), nil
+	case float64:
+		return time.Unix(0, int64(t*float64(units))).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("timestamp field %s has unsupported type %T",
+		p.TimestampField, v)
+}
+
+// fieldName converts a field path into a field or tag key.
+func fieldName(path string) string {
+	return strings.Replace(path, ".", "_", -1)
+}
+
+func toFieldValue(v interface{}) interface{} {
+	if t, ok := v.(time.Time); ok {
+		return t.UnixNano()
+	}
+	return v
+}
+
+func toString(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case bool:
+		return strconv.FormatBool(t)
+	case int64:
+		return strconv.FormatInt(t, 10)
+	case uint64:
+		return strconv.FormatUint(t, 10)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case time.Time:
+		return t.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprintf("%v", v)
+}
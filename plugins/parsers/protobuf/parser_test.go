@@ -0,0 +1,290 @@
+package protobuf
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func field(
+	name string,
+	number int32,
+	typ descriptor.FieldDescriptorProto_Type,
+	typeName string,
+	repeated bool,
+) *descriptor.FieldDescriptorProto {
+	label := descriptor.FieldDescriptorProto_LABEL_OPTIONAL
+	if repeated {
+		label = descriptor.FieldDescriptorProto_LABEL_REPEATED
+	}
+	f := &descriptor.FieldDescriptorProto{
+		Name:   proto.String(name),
+		Number: proto.Int32(number),
+		Type:   typ.Enum(),
+		Label:  label.Enum(),
+	}
+	if typeName != "" {
+		f.TypeName = proto.String(typeName)
+	}
+	return f
+}
+
+// testDescriptorSet describes:
+//
+//	package test.metrics;
+//
+//	message Sample {
+//	  enum Status { UNKNOWN = 0; OK = 1; FAILED = 2; }
+//	  message Meta { string region = 1; uint32 cpu = 2; }
+//
+//	  string name = 1;
+//	  string host = 2;
+//	  Status status = 3;
+//	  double value = 4;
+//	  sint64 delta = 5;
+//	  repeated int32 counts = 6 [packed = true];
+//	  Meta meta = 7;
+//	  google.protobuf.Timestamp time = 8;
+//	  int64 time_ms = 9;
+//	  bytes payload = 10;
+//	}
+func testDescriptorSet() *descriptor.FileDescriptorSet {
+	timestamp := &descriptor.FileDescriptorProto{
+		Name:    proto.String("google/protobuf/timestamp.proto"),
+		Package: proto.String("google.protobuf"),
+		MessageType: []*descriptor.DescriptorProto{{
+			Name: proto.String("Timestamp"),
+			Field: []*descriptor.FieldDescriptorProto{
+				field("seconds", 1, descriptor.FieldDescriptorProto_TYPE_INT64, "", false),
+				field("nanos", 2, descriptor.FieldDescriptorProto_TYPE_INT32, "", false),
+			},
+		}},
+	}
+
+	sample := &descriptor.FileDescriptorProto{
+		Name:       proto.String("sample.proto"),
+		Package:    proto.String("test.metrics"),
+		Dependency: []string{"google/protobuf/timestamp.proto"},
+		MessageType: []*descriptor.DescriptorProto{{
+			Name: proto.String("Sample"),
+			Field: []*descriptor.FieldDescriptorProto{
+				field("name", 1, descriptor.FieldDescriptorProto_TYPE_STRING, "", false),
+				field("host", 2, descriptor.FieldDescriptorProto_TYPE_STRING, "", false),
+				field("status", 3, descriptor.FieldDescriptorProto_TYPE_ENUM, ".test.metrics.Sample.Status", false),
+				field("value", 4, descriptor.FieldDescriptorProto_TYPE_DOUBLE, "", false),
+				field("delta", 5, descriptor.FieldDescriptorProto_TYPE_SINT64, "", false),
+				field("counts", 6, descriptor.FieldDescriptorProto_TYPE_INT32, "", true),
+				field("meta", 7, descriptor.FieldDescriptorProto_TYPE_MESSAGE, ".test.metrics.Sample.Meta", false),
+				field("time", 8, descriptor.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.Timestamp", false),
+				field("time_ms", 9, descriptor.FieldDescriptorProto_TYPE_INT64, "", false),
+				field("payload", 10, descriptor.FieldDescriptorProto_TYPE_BYTES, "", false),
+			},
+			NestedType: []*descriptor.DescriptorProto{{
+				Name: proto.String("Meta"),
+				Field: []*descriptor.FieldDescriptorProto{
+					field("region", 1, descriptor.FieldDescriptorProto_TYPE_STRING, "", false),
+					field("cpu", 2, descriptor.FieldDescriptorProto_TYPE_UINT32, "", false),
+				},
+			}},
+			EnumType: []*descriptor.EnumDescriptorProto{{
+				Name: proto.String("Status"),
+				Value: []*descriptor.EnumValueDescriptorProto{
+					{Name: proto.String("UNKNOWN"), Number: proto.Int32(0)},
+					{Name: proto.String("OK"), Number: proto.Int32(1)},
+					{Name: proto.String("FAILED"), Number: proto.Int32(2)},
+				},
+			}},
+		}},
+	}
+
+	return &descriptor.FileDescriptorSet{
+		File: []*descriptor.FileDescriptorProto{timestamp, sample},
+	}
+}
+
+func writeDescriptorSet(t *testing.T) string {
+	buf, err := proto.Marshal(testDescriptorSet())
+	require.NoError(t, err)
+
+	f, err := ioutil.TempFile("", "descriptor")
+	require.NoError(t, err)
+	defer f.Close()
+
+	_, err = f.Write(buf)
+	require.NoError(t, err)
+	return f.Name()
+}
+
+func key(b *proto.Buffer, number int, wireType int) {
+	b.EncodeVarint(uint64(number<<3 | wireType))
+}
+
+func encodeSample() []byte {
+	meta := proto.NewBuffer(nil)
+	key(meta, 1, wireBytes)
+	meta.EncodeStringBytes("eu-west")
+	key(meta, 2, wireVarint)
+	meta.EncodeVarint(3)
+
+	ts := proto.NewBuffer(nil)
+	key(ts, 1, wireVarint)
+	ts.EncodeVarint(1500000000)
+	key(ts, 2, wireVarint)
+	ts.EncodeVarint(500)
+
+	counts := proto.NewBuffer(nil)
+	counts.EncodeVarint(1)
+	counts.EncodeVarint(2)
+	counts.EncodeVarint(3)
+
+	var delta int64 = -7
+
+	b := proto.NewBuffer(nil)
+	key(b, 1, wireBytes)
+	b.EncodeStringBytes("cpu")
+	key(b, 2, wireBytes)
+	b.EncodeStringBytes("server01")
+	key(b, 3, wireVarint)
+	b.EncodeVarint(2)
+	key(b, 4, wireFixed64)
+	b.EncodeFixed64(math.Float64bits(42.5))
+	key(b, 5, wireVarint)
+	b.EncodeZigzag64(uint64(delta))
+	key(b, 6, wireBytes)
+	b.EncodeRawBytes(counts.Bytes())
+	key(b, 7, wireBytes)
+	b.EncodeRawBytes(meta.Bytes())
+	key(b, 8, wireBytes)
+	b.EncodeRawBytes(ts.Bytes())
+	key(b, 10, wireBytes)
+	b.EncodeRawBytes([]byte{0xde, 0xad})
+	// unknown field, must be skipped
+	key(b, 99, wireVarint)
+	b.EncodeVarint(1)
+	return b.Bytes()
+}
+
+func newTestParser(t *testing.T) *Parser {
+	path := writeDescriptorSet(t)
+	defer os.Remove(path)
+
+	p := &Parser{
+		MetricName:       "protobuf",
+		DescriptorFile:   path,
+		MessageType:      "test.metrics.Sample",
+		MeasurementField: "name",
+		TagFields:        []string{"host", "status", "meta.region"},
+		TimestampField:   "time",
+	}
+	require.NoError(t, p.InitDescriptors())
+	return p
+}
+
+func TestParse(t *testing.T) {
+	p := newTestParser(t)
+
+	metrics, err := p.Parse(encodeSample())
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+
+	m := metrics[0]
+	assert.Equal(t, "cpu", m.Name())
+	assert.Equal(t, map[string]string{
+		"host":        "server01",
+		"status":      "FAILED",
+		"meta_region": "eu-west",
+	}, m.Tags())
+	assert.Equal(t, map[string]interface{}{
+		"value":    42.5,
+		"delta":    int64(-7),
+		"counts_0": int64(1),
+		"counts_1": int64(2),
+		"counts_2": int64(3),
+		"meta_cpu": int64(3),
+	}, m.Fields())
+	assert.Equal(t, time.Unix(1500000000, 500).UTC(), m.Time().UTC())
+}
+
+func TestParseSelectedFields(t *testing.T) {
+	p := newTestParser(t)
+	p.Fields = []string{"value", "counts"}
+	p.SetDefaultTags(map[string]string{"source": "kafka"})
+
+	metrics, err := p.Parse(encodeSample())
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+
+	assert.Equal(t, map[string]interface{}{
+		"value":    42.5,
+		"counts_0": int64(1),
+		"counts_1": int64(2),
+		"counts_2": int64(3),
+	}, metrics[0].Fields())
+	assert.Equal(t, "kafka", metrics[0].Tags()["source"])
+}
+
+func TestParseNumericTimestamp(t *testing.T) {
+	p := newTestParser(t)
+	p.TimestampField = "time_ms"
+	p.TimestampUnits = time.Millisecond
+
+	b := proto.NewBuffer(nil)
+	key(b, 4, wireFixed64)
+	b.EncodeFixed64(math.Float64bits(1))
+	key(b, 9, wireVarint)
+	b.EncodeVarint(1500000000123)
+
+	m, err := p.ParseLine(string(b.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, "protobuf", m.Name())
+	assert.Equal(t, map[string]interface{}{"value": float64(1)}, m.Fields())
+	assert.Equal(t, int64(1500000000123000000), m.UnixNano())
+}
+
+func TestParseLengthDelimited(t *testing.T) {
+	p := newTestParser(t)
+	p.LengthDelimited = true
+
+	msg := encodeSample()
+	b := proto.NewBuffer(nil)
+	b.EncodeRawBytes(msg)
+	b.EncodeRawBytes(msg)
+
+	metrics, err := p.Parse(b.Bytes())
+	require.NoError(t, err)
+	assert.Len(t, metrics, 2)
+}
+
+func TestParseEmpty(t *testing.T) {
+	p := newTestParser(t)
+
+	metrics, err := p.Parse(nil)
+	require.NoError(t, err)
+	assert.Len(t, metrics, 0)
+}
+
+func TestParseTruncated(t *testing.T) {
+	p := newTestParser(t)
+
+	msg := encodeSample()
+	_, err := p.Parse(msg[:len(msg)-5])
+	assert.Error(t, err)
+}
+
+func TestUnknownMessageType(t *testing.T) {
+	path := writeDescriptorSet(t)
+	defer os.Remove(path)
+
+	p := &Parser{
+		DescriptorFile: path,
+		MessageType:    "test.metrics.Missing",
+	}
+	assert.Error(t, p.InitDescriptors())
+}
@@ -2,6 +2,7 @@ package parsers
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/influxdata/telegraf"
 
@@ -11,6 +12,8 @@ import (
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/parsers/json"
 	"github.com/influxdata/telegraf/plugins/parsers/nagios"
+	"github.com/influxdata/telegraf/plugins/parsers/prometheusremotewrite"
+	"github.com/influxdata/telegraf/plugins/parsers/protobuf"
 	"github.com/influxdata/telegraf/plugins/parsers/value"
 )
 
@@ -42,7 +45,8 @@ type Parser interface {
 // Config is a struct that covers the data types needed for all parser types,
 // and can be used to instantiate _any_ of the parsers.
 type Config struct {
-	// Dataformat can be one of: json, influx, graphite, value, nagios
+	// Dataformat can be one of: json, influx, graphite, value, nagios,
+	// collectd, dropwizard, protobuf, prometheusremotewrite
 	DataFormat string
 
 	// Separator only applied to Graphite data.
@@ -83,6 +87,23 @@ type Config struct {
 	// an optional map containing tag names as keys and json paths to retrieve the tag values from as values
 	// used if TagsPath is empty or doesn't return any tags
 	DropwizardTagPathsMap map[string]string
+
+	// path to a compiled FileDescriptorSet describing the protobuf messages
+	ProtobufDescriptorFile string
+	// fully qualified name of the protobuf message type to decode
+	ProtobufMessageType string
+	// an optional field path to use as the measurement name
+	ProtobufMeasurementField string
+	// field paths to convert to tags
+	ProtobufTagFields []string
+	// field paths to convert to fields, all other values are used if empty
+	ProtobufFields []string
+	// an optional field path containing the time of the metric
+	ProtobufTimestampField string
+	// precision of numeric timestamps, defaults to seconds
+	ProtobufTimestampUnits time.Duration
+	// messages are prefixed by their varint encoded length
+	ProtobufLengthDelimited bool
 }
 
 // NewParser returns a Parser interface based on the given config.
@@ -110,6 +131,15 @@ func NewParser(config *Config) (Parser, error) {
 		parser, err = NewDropwizardParser(config.DropwizardMetricRegistryPath,
 			config.DropwizardTimePath, config.DropwizardTimeFormat, config.DropwizardTagsPath, config.DropwizardTagPathsMap, config.DefaultTags,
 			config.Separator, config.Templates)
+	case "protobuf":
+		parser, err = NewProtobufParser(config.MetricName,
+			config.ProtobufDescriptorFile, config.ProtobufMessageType,
+			config.ProtobufMeasurementField, config.ProtobufTagFields,
+			config.ProtobufFields, config.ProtobufTimestampField,
+			config.ProtobufTimestampUnits, config.ProtobufLengthDelimited,
+			config.DefaultTags)
+	case "prometheusremotewrite":
+		parser, err = NewPrometheusRemoteWriteParser(config.DefaultTags)
 	default:
 		err = fmt.Errorf("Invalid data format: %s", config.DataFormat)
 	}
@@ -190,3 +220,38 @@ func NewDropwizardParser(
 
 	return parser, err
 }
+
+func NewProtobufParser(
+	metricName string,
+	descriptorFile string,
+	messageType string,
+	measurementField string,
+	tagFields []string,
+	fields []string,
+	timestampField string,
+	timestampUnits time.Duration,
+	lengthDelimited bool,
+	defaultTags map[string]string,
+) (Parser, error) {
+	parser := &protobuf.Parser{
+		MetricName:       metricName,
+		DescriptorFile:   descriptorFile,
+		MessageType:      messageType,
+		MeasurementField: measurementField,
+		TagFields:        tagFields,
+		Fields:           fields,
+		TimestampField:   timestampField,
+		TimestampUnits:   timestampUnits,
+		LengthDelimited:  lengthDelimited,
+		DefaultTags:      defaultTags,
+	}
+	err := parser.InitDescriptors()
+
+	return parser, err
+}
+
+func NewPrometheusRemoteWriteParser(defaultTags map[string]string) (Parser, error) {
+	return &prometheusremotewrite.Parser{
+		DefaultTags: defaultTags,
+	}, nil
+}
@@ -0,0 +1,112 @@
+package carbon2
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+var (
+	keyReplacer   = strings.NewReplacer(" ", "_", "=", "_")
+	valueReplacer = strings.NewReplacer(" ", "_")
+)
+
+// Carbon2Serializer serializes metrics into the Carbon 2.0 format, one line
+// per field:
+//
+//	metric=<measurement> field=<field> <intrinsic tags>  <meta tags> <value> <timestamp>
+//
+// Intrinsic tags identify the series, while meta tags, separated from them by
+// two spaces, carry additional information that is not part of the identity.
+type Carbon2Serializer struct {
+	// MetaTags are the tag keys sent as meta tags, all other tags are sent as
+	// intrinsic tags.
+	MetaTags []string
+}
+
+func (s *Carbon2Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	var buf bytes.Buffer
+
+	intrinsic, meta := s.splitTags(metric.Tags())
+	timestamp := strconv.FormatInt(metric.UnixNano()/1000000000, 10)
+
+	fields := metric.Fields()
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, fieldName := range keys {
+		value, ok := formatValue(fields[fieldName])
+		if !ok {
+			continue
+		}
+
+		buf.WriteString("metric=")
+		buf.WriteString(valueReplacer.Replace(metric.Name()))
+		buf.WriteString(" field=")
+		buf.WriteString(valueReplacer.Replace(fieldName))
+		for _, tag := range intrinsic {
+			buf.WriteByte(' ')
+			buf.WriteString(tag)
+		}
+		buf.WriteString("  ")
+		for _, tag := range meta {
+			buf.WriteString(tag)
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(value)
+		buf.WriteByte(' ')
+		buf.WriteString(timestamp)
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+}
+
+// splitTags returns the sorted, formatted intrinsic and meta tags.
+func (s *Carbon2Serializer) splitTags(tags map[string]string) ([]string, []string) {
+	intrinsic := make([]string, 0, len(tags))
+	meta := make([]string, 0, len(s.MetaTags))
+	for k, v := range tags {
+		tag := keyReplacer.Replace(k) + "=" + valueReplacer.Replace(v)
+		if s.isMetaTag(k) {
+			meta = append(meta, tag)
+		} else {
+			intrinsic = append(intrinsic, tag)
+		}
+	}
+	sort.Strings(intrinsic)
+	sort.Strings(meta)
+	return intrinsic, meta
+}
+
+func (s *Carbon2Serializer) isMetaTag(key string) bool {
+	for _, k := range s.MetaTags {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func formatValue(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case uint64:
+		return strconv.FormatUint(v, 10), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		if v {
+			return "1", true
+		}
+		return "0", true
+	}
+	return "", false
+}
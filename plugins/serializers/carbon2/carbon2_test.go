@@ -0,0 +1,51 @@
+package carbon2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerialize(t *testing.T) {
+	m, err := metric.New(
+		"cpu",
+		map[string]string{"cpu": "cpu0", "host": "tars"},
+		map[string]interface{}{
+			"usage_idle": float64(98.09),
+			"usage_user": int64(1),
+			"online":     true,
+			"state":      "skipped",
+		},
+		time.Unix(1455320660, 0),
+	)
+	require.NoError(t, err)
+
+	s := Carbon2Serializer{}
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	expected := "metric=cpu field=online cpu=cpu0 host=tars  1 1455320660\n" +
+		"metric=cpu field=usage_idle cpu=cpu0 host=tars  98.09 1455320660\n" +
+		"metric=cpu field=usage_user cpu=cpu0 host=tars  1 1455320660\n"
+	assert.Equal(t, expected, string(buf))
+}
+
+func TestSerializeMetaTags(t *testing.T) {
+	m, err := metric.New(
+		"disk usage",
+		map[string]string{"path": "/", "host": "tars", "dc": "us east"},
+		map[string]interface{}{"used": uint64(42)},
+		time.Unix(1455320660, 0),
+	)
+	require.NoError(t, err)
+
+	s := Carbon2Serializer{MetaTags: []string{"host", "dc"}}
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	expected := "metric=disk_usage field=used path=/  dc=us_east host=tars 42 1455320660\n"
+	assert.Equal(t, expected, string(buf))
+}
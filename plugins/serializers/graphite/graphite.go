@@ -11,6 +11,10 @@ import (
 
 const DEFAULT_TEMPLATE = "host.tags.measurement.field"
 
+// DEFAULT_TAGGED_TEMPLATE is used when tag support is enabled and no template
+// is set, the tags are sent as Graphite tags instead of in the path.
+const DEFAULT_TAGGED_TEMPLATE = "measurement.field"
+
 var (
 	allowedChars = regexp.MustCompile(`[^a-zA-Z0-9-:._=\p{L}]`)
 	hypenChars   = strings.NewReplacer(
@@ -24,11 +28,24 @@ var (
 	)
 
 	fieldDeleter = strings.NewReplacer(".FIELDNAME", "", "FIELDNAME.", "")
+
+	// characters that are not allowed in Graphite tag names, in addition to
+	// those replaced by sanitize
+	tagKeyChars = strings.NewReplacer(
+		";", "_",
+		"!", "_",
+		"^", "_",
+		"=", "_",
+	)
 )
 
 type GraphiteSerializer struct {
 	Prefix   string
 	Template string
+
+	// TagSupport enables Graphite 1.1 tagged series, tags not used by the
+	// template are appended to the path as ;tag=value pairs.
+	TagSupport bool
 }
 
 func (s *GraphiteSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
@@ -37,7 +54,14 @@ func (s *GraphiteSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
 	// Convert UnixNano to Unix timestamps
 	timestamp := metric.UnixNano() / 1000000000
 
-	bucket := SerializeBucketName(metric.Name(), metric.Tags(), s.Template, s.Prefix)
+	var bucket, tags string
+	if s.TagSupport {
+		var remaining map[string]string
+		bucket, remaining = SerializeBucketNameWithTags(metric.Name(), metric.Tags(), s.Template, s.Prefix)
+		tags = buildTaggedSeriesTags(remaining)
+	} else {
+		bucket = SerializeBucketName(metric.Name(), metric.Tags(), s.Template, s.Prefix)
+	}
 	if bucket == "" {
 		return out, nil
 	}
@@ -55,7 +79,7 @@ func (s *GraphiteSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
 		}
 		metricString := fmt.Sprintf("%s %#v %d\n",
 			// insert "field" section of template
-			sanitize(InsertField(bucket, fieldName)),
+			sanitize(InsertField(bucket, fieldName))+tags,
 			value,
 			timestamp)
 		point := []byte(metricString)
@@ -81,6 +105,51 @@ func SerializeBucketName(
 	if template == "" {
 		template = DEFAULT_TEMPLATE
 	}
+	out, tagsCopy := applyTemplate(measurement, tags, template)
+
+	// insert remaining tags into output name
+	for i, templatePart := range out {
+		if templatePart == "TAGS" {
+			out[i] = buildTags(tagsCopy)
+			break
+		}
+	}
+
+	return joinBucket(out, prefix)
+}
+
+// SerializeBucketNameWithTags works like SerializeBucketName, but instead of
+// inserting the remaining tags into the bucket at the position of the "tags"
+// template keyword it returns them, so that they can be sent as tags of a
+// Graphite 1.1 tagged series.
+func SerializeBucketNameWithTags(
+	measurement string,
+	tags map[string]string,
+	template string,
+	prefix string,
+) (string, map[string]string) {
+	if template == "" {
+		template = DEFAULT_TAGGED_TEMPLATE
+	}
+	parts, tagsCopy := applyTemplate(measurement, tags, template)
+
+	out := parts[:0]
+	for _, templatePart := range parts {
+		if templatePart != "TAGS" {
+			out = append(out, templatePart)
+		}
+	}
+
+	return joinBucket(out, prefix), tagsCopy
+}
+
+// applyTemplate returns the parts of the bucket described by the template and
+// the tags that were not used by it.
+func applyTemplate(
+	measurement string,
+	tags map[string]string,
+	template string,
+) ([]string, map[string]string) {
 	tagsCopy := make(map[string]string)
 	for k, v := range tags {
 		tagsCopy[k] = v
@@ -106,15 +175,10 @@ func SerializeBucketName(
 			}
 		}
 	}
+	return out, tagsCopy
+}
 
-	// insert remaining tags into output name
-	for i, templatePart := range out {
-		if templatePart == "TAGS" {
-			out[i] = buildTags(tagsCopy)
-			break
-		}
-	}
-
+func joinBucket(out []string, prefix string) string {
 	if len(out) == 0 {
 		return ""
 	}
@@ -155,6 +219,34 @@ func buildTags(tags map[string]string) string {
 	return tag_str
 }
 
+// buildTaggedSeriesTags formats tags as the ;tag=value suffix of a Graphite
+// tagged series.  Tags with an empty value are not allowed by Graphite and are
+// skipped; the "name" tag is reserved for the series name and is renamed.
+func buildTaggedSeriesTags(tags map[string]string) string {
+	var keys []string
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var tag_str string
+	for _, k := range keys {
+		tag_value := sanitize(tags[k])
+		if tag_value == "" {
+			continue
+		}
+		tag_key := tagKeyChars.Replace(sanitize(k))
+		if tag_key == "" {
+			continue
+		}
+		if tag_key == "name" {
+			tag_key = "_name"
+		}
+		tag_str += ";" + tag_key + "=" + tag_value
+	}
+	return tag_str
+}
+
 func sanitize(value string) string {
 	// Apply special hypenation rules to preserve backwards compatibility
 	value = hypenChars.Replace(value)
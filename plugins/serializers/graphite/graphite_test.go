@@ -561,3 +561,56 @@ func TestClean(t *testing.T) {
 		})
 	}
 }
+
+func TestSerializeTaggedMetrics(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	tests := []struct {
+		name     string
+		template string
+		prefix   string
+		tags     map[string]string
+		fields   map[string]interface{}
+		expected string
+	}{
+		{
+			"Default template",
+			"",
+			"",
+			map[string]string{"host": "localhost", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": float64(91.5)},
+			"cpu.usage_idle;cpu=cpu0;host=localhost 91.5 1234567890\n",
+		},
+		{
+			"Template and prefix",
+			"host.tags.measurement.field",
+			"telegraf",
+			map[string]string{"host": "localhost", "cpu": "cpu0"},
+			map[string]interface{}{"value": float64(91.5)},
+			"telegraf.localhost.cpu;cpu=cpu0 91.5 1234567890\n",
+		},
+		{
+			"Sanitized tags",
+			"",
+			"",
+			map[string]string{"tag;key=": "value with;semicolon", "empty": "", "name": "n"},
+			map[string]interface{}{"usage_idle": int64(1)},
+			"cpu.usage_idle;_name=n;tag_key_=value_with_semicolon 1 1234567890\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := metric.New("cpu", tt.tags, tt.fields, now)
+			require.NoError(t, err)
+
+			s := GraphiteSerializer{
+				Template:   tt.template,
+				Prefix:     tt.prefix,
+				TagSupport: true,
+			}
+			buf, err := s.Serialize(m)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(buf))
+		})
+	}
+}
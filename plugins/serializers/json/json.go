@@ -2,6 +2,7 @@ package json
 
 import (
 	ejson "encoding/json"
+	"math"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -9,9 +10,55 @@ import (
 
 type JsonSerializer struct {
 	TimestampUnits time.Duration
+
+	// Batch makes SerializeBatch produce a single document of the form
+	// {"metrics":[...]} instead of one document per metric.
+	Batch bool
 }
 
 func (s *JsonSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	m := s.createObject(metric)
+	serialized, err := ejson.Marshal(m)
+	if err != nil {
+		return []byte{}, err
+	}
+	serialized = append(serialized, '\n')
+
+	return serialized, nil
+}
+
+// SerializeBatch serializes all metrics of a write into one buffer, either as
+// a single batch document or as one document per line.
+func (s *JsonSerializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	if !s.Batch {
+		var out []byte
+		for _, metric := range metrics {
+			serialized, err := s.Serialize(metric)
+			if err != nil {
+				return []byte{}, err
+			}
+			out = append(out, serialized...)
+		}
+		return out, nil
+	}
+
+	objects := make([]map[string]interface{}, 0, len(metrics))
+	for _, metric := range metrics {
+		objects = append(objects, s.createObject(metric))
+	}
+
+	serialized, err := ejson.Marshal(map[string]interface{}{
+		"metrics": objects,
+	})
+	if err != nil {
+		return []byte{}, err
+	}
+	serialized = append(serialized, '\n')
+
+	return serialized, nil
+}
+
+func (s *JsonSerializer) createObject(metric telegraf.Metric) map[string]interface{} {
 	m := make(map[string]interface{})
 	units_nanoseconds := s.TimestampUnits.Nanoseconds()
 	// if the units passed in were less than or equal to zero,
@@ -20,14 +67,28 @@ func (s *JsonSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
 		units_nanoseconds = 1000000000
 	}
 	m["tags"] = metric.Tags()
-	m["fields"] = metric.Fields()
+	m["fields"] = convertFields(metric.Fields())
 	m["name"] = metric.Name()
 	m["timestamp"] = metric.UnixNano() / units_nanoseconds
-	serialized, err := ejson.Marshal(m)
-	if err != nil {
-		return []byte{}, err
-	}
-	serialized = append(serialized, '\n')
+	return m
+}
 
-	return serialized, nil
+// convertFields drops float values that cannot be represented in JSON, NaN
+// and +/-Inf, so that a single bad value does not cause the whole metric to
+// fail.  Unsigned integers are kept as is and encoded without loss of
+// precision.
+func convertFields(fields map[string]interface{}) map[string]interface{} {
+	for k, v := range fields {
+		switch v := v.(type) {
+		case float64:
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				delete(fields, k)
+			}
+		case float32:
+			if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+				delete(fields, k)
+			}
+		}
+	}
+	return fields
 }
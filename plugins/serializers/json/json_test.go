@@ -2,11 +2,13 @@ package json
 
 import (
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
 )
 
@@ -108,3 +110,84 @@ func TestSerializeMetricWithEscapes(t *testing.T) {
 	expS := []byte(fmt.Sprintf(`{"fields":{"U,age=Idle":90},"name":"My CPU","tags":{"cpu tag":"cpu0"},"timestamp":%d}`, now.Unix()) + "\n")
 	assert.Equal(t, string(expS), string(buf))
 }
+
+func TestSerializeMetricUint(t *testing.T) {
+	now := time.Now()
+	m, err := metric.New("cpu", map[string]string{}, map[string]interface{}{"count": int64(0)}, now)
+	assert.NoError(t, err)
+
+	s := JsonSerializer{}
+	buf, err := s.Serialize(&fieldsMetric{Metric: m, fields: map[string]interface{}{
+		"count": uint64(18446744073709551615),
+	}})
+	assert.NoError(t, err)
+
+	expS := []byte(fmt.Sprintf(`{"fields":{"count":18446744073709551615},"name":"cpu","tags":{},"timestamp":%d}`, now.Unix()) + "\n")
+	assert.Equal(t, string(expS), string(buf))
+}
+
+func TestSerializeMetricNaN(t *testing.T) {
+	now := time.Now()
+	m, err := metric.New("cpu", map[string]string{}, map[string]interface{}{"usage_idle": float64(91.5)}, now)
+	assert.NoError(t, err)
+
+	s := JsonSerializer{}
+	buf, err := s.Serialize(&fieldsMetric{Metric: m, fields: map[string]interface{}{
+		"usage_idle": float64(91.5),
+		"nan":        math.NaN(),
+		"inf":        math.Inf(1),
+	}})
+	assert.NoError(t, err)
+
+	expS := []byte(fmt.Sprintf(`{"fields":{"usage_idle":91.5},"name":"cpu","tags":{},"timestamp":%d}`, now.Unix()) + "\n")
+	assert.Equal(t, string(expS), string(buf))
+}
+
+func TestSerializeTimestampUnits(t *testing.T) {
+	now := time.Unix(1500000000, 123456789)
+	m, err := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": int64(1)}, now)
+	assert.NoError(t, err)
+
+	s := JsonSerializer{TimestampUnits: time.Millisecond}
+	buf, err := s.Serialize(m)
+	assert.NoError(t, err)
+
+	assert.Equal(t, `{"fields":{"value":1},"name":"cpu","tags":{},"timestamp":1500000000123}`+"\n", string(buf))
+}
+
+func TestSerializeBatch(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	m1, err := metric.New("cpu", map[string]string{"cpu": "cpu0"}, map[string]interface{}{"value": int64(1)}, now)
+	assert.NoError(t, err)
+	m2, err := metric.New("mem", map[string]string{}, map[string]interface{}{"free": int64(2)}, now)
+	assert.NoError(t, err)
+
+	s := JsonSerializer{Batch: true}
+	buf, err := s.SerializeBatch([]telegraf.Metric{m1, m2})
+	assert.NoError(t, err)
+
+	expS := `{"metrics":[` +
+		`{"fields":{"value":1},"name":"cpu","tags":{"cpu":"cpu0"},"timestamp":1500000000},` +
+		`{"fields":{"free":2},"name":"mem","tags":{},"timestamp":1500000000}` +
+		`]}` + "\n"
+	assert.Equal(t, expS, string(buf))
+
+	s.Batch = false
+	buf, err = s.SerializeBatch([]telegraf.Metric{m1, m2})
+	assert.NoError(t, err)
+
+	expS = `{"fields":{"value":1},"name":"cpu","tags":{"cpu":"cpu0"},"timestamp":1500000000}` + "\n" +
+		`{"fields":{"free":2},"name":"mem","tags":{},"timestamp":1500000000}` + "\n"
+	assert.Equal(t, expS, string(buf))
+}
+
+// fieldsMetric overrides the fields of a metric, allowing values that cannot
+// be stored by metric.New.
+type fieldsMetric struct {
+	telegraf.Metric
+	fields map[string]interface{}
+}
+
+func (m *fieldsMetric) Fields() map[string]interface{} {
+	return m.fields
+}
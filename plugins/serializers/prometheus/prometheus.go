@@ -0,0 +1,301 @@
+package prometheus
+
+import (
+	"bytes"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+const helpText = "Telegraf collected metric"
+
+var (
+	invalidNameCharRE = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+// PrometheusSerializer serializes metrics into the Prometheus text exposition
+// format.  Metric names are derived from the measurement and field names the
+// same way as the prometheus_client output, and the Prometheus type is taken
+// from the telegraf.ValueType of the metric.
+type PrometheusSerializer struct {
+	// ExportTimestamp adds the metric time, in milliseconds, to each sample.
+	ExportTimestamp bool
+	// StringAsLabel converts string fields into labels, otherwise they are
+	// dropped, which is the default.
+	StringAsLabel bool
+}
+
+func (s *PrometheusSerializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	return s.SerializeBatch([]telegraf.Metric{metric})
+}
+
+// SerializeBatch serializes the metrics grouped by metric family, so that the
+// HELP and TYPE lines of each family are written once, before all of its
+// samples.  The families are written in the order they are first seen.
+func (s *PrometheusSerializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	fs := &familySet{families: make(map[string]*family)}
+	for _, metric := range metrics {
+		s.addMetric(fs, metric)
+	}
+
+	var buf bytes.Buffer
+	for _, f := range fs.order {
+		writeHeader(&buf, f.name, f.tt)
+		buf.Write(f.samples.Bytes())
+	}
+	return buf.Bytes(), nil
+}
+
+// family holds the serialized samples of a metric name.
+type family struct {
+	name    string
+	tt      telegraf.ValueType
+	samples bytes.Buffer
+}
+
+type familySet struct {
+	families map[string]*family
+	order    []*family
+}
+
+// get returns the family of the name, creating it with the type if needed.
+func (fs *familySet) get(name string, tt telegraf.ValueType) *family {
+	f, ok := fs.families[name]
+	if !ok {
+		f = &family{name: name, tt: tt}
+		fs.families[name] = f
+		fs.order = append(fs.order, f)
+	}
+	return f
+}
+
+func (s *PrometheusSerializer) addMetric(fs *familySet, metric telegraf.Metric) {
+	labels := s.labels(metric)
+
+	var timestamp string
+	if s.ExportTimestamp {
+		timestamp = strconv.FormatInt(metric.UnixNano()/1000000, 10)
+	}
+
+	switch metric.Type() {
+	case telegraf.Histogram, telegraf.Summary:
+		s.addDistribution(fs, metric, labels, timestamp)
+	default:
+		fields := metric.Fields()
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, fn := range keys {
+			value, ok := SampleValue(fields[fn])
+			if !ok {
+				continue
+			}
+			name := MetricName(metric.Name(), fn, metric.Type())
+			f := fs.get(name, metric.Type())
+			writeSample(&f.samples, name, labels, nil, value, timestamp)
+		}
+	}
+}
+
+// addDistribution adds a histogram or summary, whose fields are the bucket
+// upper bounds or quantiles along with the "sum" and "count" fields.
+func (s *PrometheusSerializer) addDistribution(
+	fs *familySet,
+	metric telegraf.Metric,
+	labels [][2]string,
+	timestamp string,
+) {
+	name := Sanitize(metric.Name())
+	boundLabel := "quantile"
+	sampleName := name
+	if metric.Type() == telegraf.Histogram {
+		boundLabel = "le"
+		sampleName = name + "_bucket"
+	}
+
+	var sum, count float64
+	var hasSum, hasCount bool
+	bounds := make([]float64, 0)
+	values := make(map[float64]float64)
+	for fn, fv := range metric.Fields() {
+		value, ok := SampleValue(fv)
+		if !ok {
+			continue
+		}
+		switch fn {
+		case "sum":
+			sum, hasSum = value, true
+		case "count":
+			count, hasCount = value, true
+		default:
+			bound, err := strconv.ParseFloat(fn, 64)
+			if err != nil {
+				continue
+			}
+			bounds = append(bounds, bound)
+			values[bound] = value
+		}
+	}
+	sort.Float64s(bounds)
+
+	buf := &fs.get(name, metric.Type()).samples
+	for _, bound := range bounds {
+		extra := [2]string{boundLabel, formatValue(bound)}
+		writeSample(buf, sampleName, labels, &extra, values[bound], timestamp)
+	}
+	if hasSum {
+		writeSample(buf, name+"_sum", labels, nil, sum, timestamp)
+	}
+	if hasCount {
+		writeSample(buf, name+"_count", labels, nil, count, timestamp)
+	}
+}
+
+// labels returns the sorted, sanitized label pairs of a metric.
+func (s *PrometheusSerializer) labels(metric telegraf.Metric) [][2]string {
+	labels := make(map[string]string)
+	for k, v := range metric.Tags() {
+		labels[Sanitize(k)] = v
+	}
+
+	// Prometheus doesn't have a string value type, so convert string
+	// fields to labels if enabled.
+	if s.StringAsLabel {
+		for fn, fv := range metric.Fields() {
+			if v, ok := fv.(string); ok {
+				labels[Sanitize(fn)] = v
+			}
+		}
+	}
+
+	pairs := make([][2]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, [2]string{k, v})
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i][0] < pairs[j][0] })
+	return pairs
+}
+
+func writeHeader(buf *bytes.Buffer, name string, tt telegraf.ValueType) {
+	buf.WriteString("# HELP ")
+	buf.WriteString(name)
+	buf.WriteByte(' ')
+	buf.WriteString(helpText)
+	buf.WriteString("\n# TYPE ")
+	buf.WriteString(name)
+	buf.WriteByte(' ')
+	buf.WriteString(TypeName(tt))
+	buf.WriteByte('\n')
+}
+
+func writeSample(
+	buf *bytes.Buffer,
+	name string,
+	labels [][2]string,
+	extra *[2]string,
+	value float64,
+	timestamp string,
+) {
+	buf.WriteString(name)
+	if len(labels) > 0 || extra != nil {
+		buf.WriteByte('{')
+		first := true
+		write := func(pair [2]string) {
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			buf.WriteString(pair[0])
+			buf.WriteString(`="`)
+			buf.WriteString(labelValueEscaper.Replace(pair[1]))
+			buf.WriteByte('"')
+		}
+		for _, pair := range labels {
+			write(pair)
+		}
+		if extra != nil {
+			write(*extra)
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteByte(' ')
+	buf.WriteString(formatValue(value))
+	if timestamp != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(timestamp)
+	}
+	buf.WriteByte('\n')
+}
+
+// MetricName returns the Prometheus metric name for a field of a measurement.
+// The "value" field, and the "counter" or "gauge" field of metrics with the
+// matching type, map to the measurement name alone.
+func MetricName(measurement, field string, tt telegraf.ValueType) string {
+	switch {
+	case field == "value",
+		tt == telegraf.Counter && field == "counter",
+		tt == telegraf.Gauge && field == "gauge":
+		return Sanitize(measurement)
+	}
+	return Sanitize(measurement + "_" + field)
+}
+
+// TypeName returns the Prometheus type for a telegraf.ValueType.
+func TypeName(tt telegraf.ValueType) string {
+	switch tt {
+	case telegraf.Counter:
+		return "counter"
+	case telegraf.Gauge:
+		return "gauge"
+	case telegraf.Summary:
+		return "summary"
+	case telegraf.Histogram:
+		return "histogram"
+	}
+	return "untyped"
+}
+
+// Sanitize replaces characters that are not allowed in Prometheus metric and
+// label names.
+func Sanitize(name string) string {
+	name = invalidNameCharRE.ReplaceAllString(name, "_")
+	if len(name) > 0 && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// SampleValue returns the value of a numeric field as a sample value, false
+// for the fields which are not numeric.
+func SampleValue(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,199 @@
+package prometheus
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerializeUntyped(t *testing.T) {
+	m, err := metric.New(
+		"cpu",
+		map[string]string{"cpu": "cpu0", "host.name": "localhost"},
+		map[string]interface{}{
+			"usage_idle": 91.5,
+			"usage_busy": int64(8),
+			"state":      "ok",
+			"enabled":    true,
+		},
+		time.Unix(1500000000, 0),
+	)
+	require.NoError(t, err)
+
+	s := PrometheusSerializer{}
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	expected := `# HELP cpu_usage_busy Telegraf collected metric
+# TYPE cpu_usage_busy untyped
+cpu_usage_busy{cpu="cpu0",host_name="localhost"} 8
+# HELP cpu_usage_idle Telegraf collected metric
+# TYPE cpu_usage_idle untyped
+cpu_usage_idle{cpu="cpu0",host_name="localhost"} 91.5
+`
+	assert.Equal(t, expected, string(buf))
+}
+
+func TestSerializeCounterAndStringLabel(t *testing.T) {
+	m, err := metric.New(
+		"http_requests_total",
+		map[string]string{"code": "200"},
+		map[string]interface{}{
+			"counter": float64(1027),
+			"method":  "post",
+		},
+		time.Unix(1500000000, 0),
+		telegraf.Counter,
+	)
+	require.NoError(t, err)
+
+	s := PrometheusSerializer{StringAsLabel: true, ExportTimestamp: true}
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	expected := `# HELP http_requests_total Telegraf collected metric
+# TYPE http_requests_total counter
+http_requests_total{code="200",method="post"} 1027 1500000000000
+`
+	assert.Equal(t, expected, string(buf))
+}
+
+func TestSerializeHistogram(t *testing.T) {
+	m, err := metric.New(
+		"request_duration_seconds",
+		map[string]string{},
+		map[string]interface{}{
+			"0.05":  float64(24054),
+			"0.5":   float64(129389),
+			"+Inf":  float64(144320),
+			"sum":   float64(53423),
+			"count": float64(144320),
+		},
+		time.Unix(1500000000, 0),
+		telegraf.Histogram,
+	)
+	require.NoError(t, err)
+
+	s := PrometheusSerializer{}
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	expected := `# HELP request_duration_seconds Telegraf collected metric
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{le="0.05"} 24054
+request_duration_seconds_bucket{le="0.5"} 129389
+request_duration_seconds_bucket{le="+Inf"} 144320
+request_duration_seconds_sum 53423
+request_duration_seconds_count 144320
+`
+	assert.Equal(t, expected, string(buf))
+}
+
+func TestSerializeSummary(t *testing.T) {
+	m, err := metric.New(
+		"rpc_duration_seconds",
+		map[string]string{"service": "a"},
+		map[string]interface{}{
+			"0.5":   float64(0.05),
+			"0.99":  float64(0.2),
+			"sum":   float64(17),
+			"count": float64(200),
+		},
+		time.Unix(1500000000, 0),
+		telegraf.Summary,
+	)
+	require.NoError(t, err)
+
+	s := PrometheusSerializer{}
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	expected := `# HELP rpc_duration_seconds Telegraf collected metric
+# TYPE rpc_duration_seconds summary
+rpc_duration_seconds{service="a",quantile="0.5"} 0.05
+rpc_duration_seconds{service="a",quantile="0.99"} 0.2
+rpc_duration_seconds_sum{service="a"} 17
+rpc_duration_seconds_count{service="a"} 200
+`
+	assert.Equal(t, expected, string(buf))
+}
+
+func TestSerializeEscaping(t *testing.T) {
+	m, err := metric.New(
+		"1st metric",
+		map[string]string{"path": `C:\temp "dir"`},
+		map[string]interface{}{"value": float64(1)},
+		time.Unix(1500000000, 0),
+		telegraf.Gauge,
+	)
+	require.NoError(t, err)
+
+	s := PrometheusSerializer{}
+	buf, err := s.Serialize(m)
+	require.NoError(t, err)
+
+	expected := `# HELP _1st_metric Telegraf collected metric
+# TYPE _1st_metric gauge
+_1st_metric{path="C:\\temp \"dir\""} 1
+`
+	assert.Equal(t, expected, string(buf))
+}
+
+func TestFormatValue(t *testing.T) {
+	assert.Equal(t, "+Inf", formatValue(math.Inf(1)))
+	assert.Equal(t, "-Inf", formatValue(math.Inf(-1)))
+	assert.Equal(t, "NaN", formatValue(math.NaN()))
+	assert.Equal(t, "1e+21", formatValue(1e21))
+}
+
+func TestMetricName(t *testing.T) {
+	assert.Equal(t, "cpu", MetricName("cpu", "value", telegraf.Untyped))
+	assert.Equal(t, "cpu", MetricName("cpu", "gauge", telegraf.Gauge))
+	assert.Equal(t, "cpu_gauge", MetricName("cpu", "gauge", telegraf.Counter))
+	assert.Equal(t, "cpu_usage_idle", MetricName("cpu", "usage-idle", telegraf.Untyped))
+}
+
+func TestSerializeBatch(t *testing.T) {
+	m1, err := metric.New(
+		"cpu",
+		map[string]string{"cpu": "cpu0"},
+		map[string]interface{}{
+			"usage_idle": 91.5,
+			"usage_user": 8.5,
+		},
+		time.Unix(1500000000, 0),
+		telegraf.Gauge,
+	)
+	require.NoError(t, err)
+	m2, err := metric.New(
+		"cpu",
+		map[string]string{"cpu": "cpu1"},
+		map[string]interface{}{
+			"usage_idle": 42.0,
+			"usage_user": 58.0,
+		},
+		time.Unix(1500000000, 0),
+		telegraf.Gauge,
+	)
+	require.NoError(t, err)
+
+	s := PrometheusSerializer{}
+	buf, err := s.SerializeBatch([]telegraf.Metric{m1, m2})
+	require.NoError(t, err)
+
+	expected := `# HELP cpu_usage_idle Telegraf collected metric
+# TYPE cpu_usage_idle gauge
+cpu_usage_idle{cpu="cpu0"} 91.5
+cpu_usage_idle{cpu="cpu1"} 42
+# HELP cpu_usage_user Telegraf collected metric
+# TYPE cpu_usage_user gauge
+cpu_usage_user{cpu="cpu0"} 8.5
+cpu_usage_user{cpu="cpu1"} 58
+`
+	assert.Equal(t, expected, string(buf))
+}
@@ -6,9 +6,12 @@ import (
 
 	"github.com/influxdata/telegraf"
 
+	"github.com/influxdata/telegraf/plugins/serializers/carbon2"
 	"github.com/influxdata/telegraf/plugins/serializers/graphite"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/plugins/serializers/json"
+	"github.com/influxdata/telegraf/plugins/serializers/prometheus"
+	"github.com/influxdata/telegraf/plugins/serializers/wavefront"
 )
 
 // SerializerOutput is an interface for output plugins that are able to
@@ -18,6 +21,14 @@ type SerializerOutput interface {
 	SetSerializer(serializer Serializer)
 }
 
+// SerializerConfigOutput is an interface for output plugins that are able to
+// write several data formats, which need the serializer options of the output
+// to create the serializers of the other data formats.
+type SerializerConfigOutput interface {
+	// SetSerializerConfig sets the serializer options of the output.
+	SetSerializerConfig(config *Config)
+}
+
 // Serializer is an interface defining functions that a serializer plugin must
 // satisfy.
 type Serializer interface {
@@ -27,21 +38,85 @@ type Serializer interface {
 	Serialize(metric telegraf.Metric) ([]byte, error)
 }
 
+// BatchSerializer is an interface for serializers that are able to serialize
+// all metrics of a single write as one document.
+type BatchSerializer interface {
+	// SerializeBatch takes the telegraf metrics of a write and turns them
+	// into a byte buffer.
+	SerializeBatch(metrics []telegraf.Metric) ([]byte, error)
+}
+
+// SerializeBatch serializes metrics using the SerializeBatch function of the
+// serializer if it has one, otherwise the serialized metrics are
+// concatenated.
+func SerializeBatch(serializer Serializer, metrics []telegraf.Metric) ([]byte, error) {
+	if bs, ok := serializer.(BatchSerializer); ok {
+		return bs.SerializeBatch(metrics)
+	}
+
+	var out []byte
+	for _, metric := range metrics {
+		b, err := serializer.Serialize(metric)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b...)
+	}
+	return out, nil
+}
+
 // Config is a struct that covers the data types needed for all serializer types,
 // and can be used to instantiate _any_ of the serializers.
 type Config struct {
-	// Dataformat can be one of: influx, graphite, or json
+	// Dataformat can be one of: influx, graphite, json, prometheus, carbon2
+	// or wavefront
 	DataFormat string
 
-	// Prefix to add to all measurements, only supports Graphite
+	// Prefix to add to all measurements, only supports Graphite and Wavefront
 	Prefix string
 
 	// Template for converting telegraf metrics into Graphite
 	// only supports Graphite
 	Template string
 
+	// Support Graphite 1.1 tagged series, only supports Graphite
+	GraphiteTagSupport bool
+
 	// Timestamp units to use for JSON formatted output
 	TimestampUnits time.Duration
+
+	// Serialize all metrics of a write as a single JSON document
+	JSONBatch bool
+
+	// Include the metric timestamp on each Prometheus sample
+	PrometheusExportTimestamp bool
+
+	// Convert string fields to Prometheus labels instead of dropping them
+	PrometheusStringAsLabel bool
+
+	// Tag keys to send as Carbon2 meta tags instead of intrinsic tags
+	Carbon2MetaTags []string
+
+	// Use "value" for the name of simple fields in Wavefront
+	WavefrontSimpleFields bool
+
+	// Character to use between metric and field name in Wavefront
+	WavefrontMetricSeparator string
+
+	// Convert underscores in Wavefront metric names to the separator
+	WavefrontConvertPaths bool
+
+	// Convert boolean values to 1 or 0 in Wavefront
+	WavefrontConvertBool bool
+
+	// Use a regex to sanitize Wavefront metric and tag names
+	WavefrontUseRegex bool
+
+	// Tags to use as the Wavefront source, instead of the host tag
+	WavefrontSourceOverride []string
+
+	// Mappings of string values to numbers in Wavefront, by metric prefix
+	WavefrontStringToNumber map[string][]map[string]float64
 }
 
 // NewSerializer a Serializer interface based on the given config.
@@ -52,26 +127,74 @@ func NewSerializer(config *Config) (Serializer, error) {
 	case "influx":
 		serializer, err = NewInfluxSerializer()
 	case "graphite":
-		serializer, err = NewGraphiteSerializer(config.Prefix, config.Template)
+		serializer, err = NewGraphiteSerializer(config.Prefix, config.Template, config.GraphiteTagSupport)
 	case "json":
-		serializer, err = NewJsonSerializer(config.TimestampUnits)
+		serializer, err = NewJsonSerializer(config.TimestampUnits, config.JSONBatch)
+	case "prometheus":
+		serializer, err = NewPrometheusSerializer(config.PrometheusExportTimestamp,
+			config.PrometheusStringAsLabel)
+	case "carbon2":
+		serializer, err = NewCarbon2Serializer(config.Carbon2MetaTags)
+	case "wavefront":
+		serializer, err = NewWavefrontSerializer(config.Prefix,
+			config.WavefrontSimpleFields, config.WavefrontMetricSeparator,
+			config.WavefrontConvertPaths, config.WavefrontConvertBool,
+			config.WavefrontUseRegex, config.WavefrontSourceOverride,
+			config.WavefrontStringToNumber)
 	default:
 		err = fmt.Errorf("Invalid data format: %s", config.DataFormat)
 	}
 	return serializer, err
 }
 
-func NewJsonSerializer(timestampUnits time.Duration) (Serializer, error) {
-	return &json.JsonSerializer{TimestampUnits: timestampUnits}, nil
+func NewJsonSerializer(timestampUnits time.Duration, batch bool) (Serializer, error) {
+	return &json.JsonSerializer{TimestampUnits: timestampUnits, Batch: batch}, nil
 }
 
 func NewInfluxSerializer() (Serializer, error) {
 	return &influx.InfluxSerializer{}, nil
 }
 
-func NewGraphiteSerializer(prefix, template string) (Serializer, error) {
+func NewGraphiteSerializer(prefix, template string, tagSupport bool) (Serializer, error) {
 	return &graphite.GraphiteSerializer{
-		Prefix:   prefix,
-		Template: template,
+		Prefix:     prefix,
+		Template:   template,
+		TagSupport: tagSupport,
+	}, nil
+}
+
+func NewPrometheusSerializer(exportTimestamp, stringAsLabel bool) (Serializer, error) {
+	return &prometheus.PrometheusSerializer{
+		ExportTimestamp: exportTimestamp,
+		StringAsLabel:   stringAsLabel,
+	}, nil
+}
+
+func NewCarbon2Serializer(metaTags []string) (Serializer, error) {
+	return &carbon2.Carbon2Serializer{MetaTags: metaTags}, nil
+}
+
+func NewWavefrontSerializer(
+	prefix string,
+	simpleFields bool,
+	metricSeparator string,
+	convertPaths bool,
+	convertBool bool,
+	useRegex bool,
+	sourceOverride []string,
+	stringToNumber map[string][]map[string]float64,
+) (Serializer, error) {
+	if metricSeparator == "" {
+		metricSeparator = "."
+	}
+	return &wavefront.WavefrontSerializer{
+		Prefix:          prefix,
+		SimpleFields:    simpleFields,
+		MetricSeparator: metricSeparator,
+		ConvertPaths:    convertPaths,
+		ConvertBool:     convertBool,
+		UseRegex:        useRegex,
+		SourceOverride:  sourceOverride,
+		StringToNumber:  stringToNumber,
 	}, nil
 }
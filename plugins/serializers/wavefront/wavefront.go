@@ -0,0 +1,202 @@
+package wavefront
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+)
+
+// WavefrontSerializer serializes metrics into the Wavefront data format:
+//
+//	<metric> <value> [<timestamp>] source=<source> [tagk1=tagv1 ...tagkN=tagvN]
+type WavefrontSerializer struct {
+	Prefix          string
+	SimpleFields    bool
+	MetricSeparator string
+	ConvertPaths    bool
+	ConvertBool     bool
+	UseRegex        bool
+	SourceOverride  []string
+	StringToNumber  map[string][]map[string]float64
+}
+
+// catch many of the invalid chars that could appear in a metric or tag name
+var sanitizedChars = strings.NewReplacer(
+	"!", "-", "@", "-", "#", "-", "$", "-", "%", "-", "^", "-", "&", "-",
+	"*", "-", "(", "-", ")", "-", "+", "-", "`", "-", "'", "-", "\"", "-",
+	"[", "-", "]", "-", "{", "-", "}", "-", ":", "-", ";", "-", "<", "-",
+	">", "-", ",", "-", "?", "-", "/", "-", "\\", "-", "|", "-", " ", "-",
+	"=", "-",
+)
+
+// instead of Replacer which may miss some special characters we can use a regex pattern, but this is significantly slower than Replacer
+var sanitizedRegex = regexp.MustCompile("[^a-zA-Z\\d_.-]")
+
+var tagValueReplacer = strings.NewReplacer("\"", "\\\"", "*", "-")
+
+type MetricPoint struct {
+	Metric    string
+	Value     float64
+	Timestamp int64
+	Source    string
+	Tags      map[string]string
+}
+
+func (s *WavefrontSerializer) Serialize(m telegraf.Metric) ([]byte, error) {
+	out := []byte{}
+	for _, point := range s.BuildMetrics(m) {
+		out = append(out, s.FormatMetricPoint(point)...)
+	}
+	return out, nil
+}
+
+// BuildMetrics converts each field of a metric into a Wavefront point.  Fields
+// whose values cannot be represented as a number are skipped.
+func (s *WavefrontSerializer) BuildMetrics(m telegraf.Metric) []*MetricPoint {
+	ret := []*MetricPoint{}
+
+	convertPaths := s.ConvertPaths && s.MetricSeparator != "_"
+	var pathReplacer *strings.Replacer
+	if convertPaths {
+		pathReplacer = strings.NewReplacer("_", s.MetricSeparator)
+	}
+
+	for fieldName, value := range m.Fields() {
+		var name string
+		if !s.SimpleFields && fieldName == "value" {
+			name = fmt.Sprintf("%s%s", s.Prefix, m.Name())
+		} else {
+			name = fmt.Sprintf("%s%s%s%s", s.Prefix, m.Name(), s.MetricSeparator, fieldName)
+		}
+
+		if s.UseRegex {
+			name = sanitizedRegex.ReplaceAllLiteralString(name, "-")
+		} else {
+			name = sanitizedChars.Replace(name)
+		}
+
+		if convertPaths {
+			name = pathReplacer.Replace(name)
+		}
+
+		metric := &MetricPoint{
+			Metric:    name,
+			Timestamp: m.UnixNano() / 1000000000,
+		}
+
+		metricValue, buildError := s.BuildValue(value, metric.Metric)
+		if buildError != nil {
+			log.Printf("D! Serializer [wavefront] %s\n", buildError.Error())
+			continue
+		}
+		metric.Value = metricValue
+
+		source, tags := s.BuildTags(m.Tags())
+		metric.Source = source
+		metric.Tags = tags
+
+		ret = append(ret, metric)
+	}
+	return ret
+}
+
+// BuildTags returns the source of a point, taken from the first tag found in
+// SourceOverride or else from the host tag, and the remaining point tags.
+func (s *WavefrontSerializer) BuildTags(mTags map[string]string) (string, map[string]string) {
+	var source string
+	sourceTagFound := false
+
+	for _, src := range s.SourceOverride {
+		for k, v := range mTags {
+			if k == src {
+				source = v
+				mTags["telegraf_host"] = mTags["host"]
+				sourceTagFound = true
+				delete(mTags, k)
+				break
+			}
+		}
+		if sourceTagFound {
+			break
+		}
+	}
+
+	if !sourceTagFound {
+		source = mTags["host"]
+	}
+	delete(mTags, "host")
+
+	return tagValueReplacer.Replace(source), mTags
+}
+
+// BuildValue converts a field value into a float.  Booleans are converted if
+// ConvertBool is set and strings only if they are mapped by StringToNumber.
+func (s *WavefrontSerializer) BuildValue(v interface{}, name string) (float64, error) {
+	switch p := v.(type) {
+	case bool:
+		if s.ConvertBool {
+			if p {
+				return 1, nil
+			} else {
+				return 0, nil
+			}
+		}
+	case int64:
+		return float64(v.(int64)), nil
+	case uint64:
+		return float64(v.(uint64)), nil
+	case float64:
+		return v.(float64), nil
+	case string:
+		for prefix, mappings := range s.StringToNumber {
+			if strings.HasPrefix(name, prefix) {
+				for _, mapping := range mappings {
+					val, hasVal := mapping[string(p)]
+					if hasVal {
+						return val, nil
+					}
+				}
+			}
+		}
+		return 0, fmt.Errorf("unexpected type: %T, with value: %v, for: %s", v, v, name)
+	default:
+		return 0, fmt.Errorf("unexpected type: %T, with value: %v, for: %s", v, v, name)
+	}
+
+	return 0, fmt.Errorf("unexpected type: %T, with value: %v, for: %s", v, v, name)
+}
+
+// FormatMetricPoint returns the line representation of a point, including
+// the trailing newline.
+func (s *WavefrontSerializer) FormatMetricPoint(metricPoint *MetricPoint) string {
+	buffer := bytes.NewBufferString("")
+	buffer.WriteString(metricPoint.Metric)
+	buffer.WriteString(" ")
+	buffer.WriteString(strconv.FormatFloat(metricPoint.Value, 'f', 6, 64))
+	buffer.WriteString(" ")
+	buffer.WriteString(strconv.FormatInt(metricPoint.Timestamp, 10))
+	buffer.WriteString(" source=\"")
+	buffer.WriteString(metricPoint.Source)
+	buffer.WriteString("\"")
+
+	for k, v := range metricPoint.Tags {
+		buffer.WriteString(" ")
+		if s.UseRegex {
+			buffer.WriteString(sanitizedRegex.ReplaceAllLiteralString(k, "-"))
+		} else {
+			buffer.WriteString(sanitizedChars.Replace(k))
+		}
+		buffer.WriteString("=\"")
+		buffer.WriteString(tagValueReplacer.Replace(v))
+		buffer.WriteString("\"")
+	}
+
+	buffer.WriteString("\n")
+
+	return buffer.String()
+}
@@ -0,0 +1,30 @@
+package testutil
+
+import (
+	"crypto/tls"
+	"path/filepath"
+	"runtime"
+)
+
+// TLSFile returns the path of a test certificate or key file in the pki
+// directory: the cacert.pem authority signs the servercert.pem certificate
+// for "localhost" and the clientcert.pem certificate for "telegraf", with
+// the serverkey.pem and clientkey.pem keys.
+func TLSFile(name string) string {
+	_, filename, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(filename), "pki", name)
+}
+
+// TLSClientConfig returns a client TLS config presenting the test client
+// certificate.  The server certificate is not verified as the test server
+// certificate has no subject alternative names.
+func TLSClientConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(TLSFile("clientcert.pem"), TLSFile("clientkey.pem"))
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates:       []tls.Certificate{cert},
+		InsecureSkipVerify: true,
+	}, nil
+}